@@ -5,13 +5,15 @@ import (
 	"os"
 
 	"github.com/redhat-developer/opencompose/pkg/cmd"
+	"github.com/redhat-developer/opencompose/pkg/util"
 )
 
 func Run() error {
 	command := cmd.NewOpenComposeCommand(os.Stdin, os.Stdout, os.Stderr)
 	err := command.Execute()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+	// every error is printed on its own when there are more of them
+	for _, e := range util.Errors(err) {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", e)
 	}
 	return err
 }
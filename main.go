@@ -4,11 +4,12 @@ import (
 	"os"
 
 	"github.com/redhat-developer/opencompose/cmd"
+	"github.com/redhat-developer/opencompose/pkg/util"
 )
 
 func main() {
 	if err := cmd.Run(); err != nil {
-		os.Exit(1)
+		os.Exit(util.ExitCode(err))
 	}
 	os.Exit(0)
 }
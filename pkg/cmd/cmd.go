@@ -38,6 +38,12 @@ func NewOpenComposeCommand(in io.Reader, out, outerr io.Writer) *cobra.Command {
 
 	rootCmd.AddCommand(NewCmdConvert(v, out, outerr))
 	rootCmd.AddCommand(NewCmdValidate(v, out, outerr))
+	rootCmd.AddCommand(NewCmdLint(v, out, outerr))
+	rootCmd.AddCommand(NewCmdFmt(v, out, outerr))
+	rootCmd.AddCommand(NewCmdMigrate(v, out, outerr))
+	rootCmd.AddCommand(NewCmdImport(v, out, outerr))
+	rootCmd.AddCommand(NewCmdSchema(v, out, outerr))
+	rootCmd.AddCommand(NewCmdExplain(v, out, outerr))
 	rootCmd.AddCommand(NewCmdVersion(v, out, outerr))
 	rootCmd.AddCommand(NewCmdCompletion(v, out, outerr))
 
@@ -13,6 +13,7 @@ import (
 	"github.com/redhat-developer/opencompose/pkg/encoding"
 	"github.com/redhat-developer/opencompose/pkg/object"
 	"github.com/redhat-developer/opencompose/pkg/transform"
+	"github.com/redhat-developer/opencompose/pkg/transform/compose"
 	"github.com/redhat-developer/opencompose/pkg/transform/kubernetes"
 	"github.com/redhat-developer/opencompose/pkg/transform/openshift"
 	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
@@ -50,84 +51,334 @@ func NewCmdConvert(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
 
 			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
 			cmdutil.AddIOFlagsViper(v, cmd)
+			cmdutil.AddConvertFlagsViper(v, cmd)
 
 			return nil
 		},
 	}
 
 	cmdutil.AddIOFlags(cmd)
+	cmdutil.AddConvertFlags(cmd)
 
 	return cmd
 }
 
-func GetValidatedObject(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) (*object.OpenCompose, error) {
-	files := v.GetStringSlice(cmdutil.Flag_File_Key)
+// Parameters declared in one file can be referenced from the others,
+// later declarations override the earlier ones
+func getParameters(files []string, contents [][]byte) ([]object.Parameter, error) {
+	var parameters []object.Parameter
+	for i, data := range contents {
+		fileParameters, err := encoding.GetParameters(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not read parameters for file '%s': %s", files[i], err)
+		}
+
+		for _, fp := range fileParameters {
+			found := false
+			for j := range parameters {
+				if parameters[j].Name == fp.Name {
+					parameters[j] = fp
+					found = true
+					break
+				}
+			}
+			if !found {
+				parameters = append(parameters, fp)
+			}
+		}
+	}
+
+	return parameters, nil
+}
+
+// Collects variables for interpolation; the process environment overrides
+// --env-file files and --set overrides both
+func GetVariables(v *viper.Viper) (map[string]string, error) {
+	variables := make(map[string]string)
+
+	for _, file := range cmdutil.GetStringSlice(v, cmdutil.Flag_EnvFile_Key) {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read env file '%s': %s", file, err)
+		}
+
+		fileVariables, err := pkgutil.ParseEnvFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse env file '%s': %s", file, err)
+		}
+
+		for key, value := range fileVariables {
+			variables[key] = value
+		}
+	}
+
+	for _, env := range os.Environ() {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) == 2 {
+			variables[parts[0]] = parts[1]
+		}
+	}
+
+	assignments, err := cmdutil.GetStringArray(v, cmdutil.Flag_Set_Key)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		key, value, err := pkgutil.ParseKeyValue(assignment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %s", cmdutil.Flag_Set_Key, err)
+		}
+		variables[key] = value
+	}
+
+	return variables, nil
+}
+
+// Reads and merges all the files and resolves templates, profiles are left unresolved
+func GetObject(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) (*object.OpenCompose, error) {
+	openCompose, _, err := getObject(v, cmd, out, outerr)
+	return openCompose, err
+}
+
+// Same as GetObject, returns also the files that were read so the errors can be located in them
+func getObject(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) (*object.OpenCompose, []document, error) {
+	files := cmdutil.GetStringSlice(v, cmdutil.Flag_File_Key)
 	if len(files) < 1 {
-		return nil, cmdutil.UsageError(cmd, "there has to be at least one file")
+		return nil, nil, cmdutil.UsageError(cmd, "there has to be at least one file")
+	}
+
+	// parameter references are kept for OpenShift Template, everywhere else they are set to default values
+	var resolveParameter encoding.ParameterResolver = encoding.DefaultParameterResolver
+	if v.GetString(cmdutil.Flag_OutputFormat_Key) == cmdutil.OutputFormat_OpenShiftTemplate {
+		resolveParameter = openshift.TemplateParameterResolver
 	}
-	var ocObjects []*object.OpenCompose
 
-	for _, file := range files {
-		var data []byte
-		var err error
+	documents, err := loadDocuments(files)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// Check if the passed resource points to STDIN or not
-		if file == "-" {
-			data, err = ioutil.ReadAll(os.Stdin)
-			if err != nil {
-				return nil, fmt.Errorf("unable to read from stdin: %s", err)
-			}
-		} else if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
-			// Check if the passed resource is a URL or not
-			// TODO: add test for validating this against an actual resource on the web
-			data, err = pkgutil.GetURLData(file, retryAttempts)
-			if err != nil {
-				return nil, fmt.Errorf("an error occurred while fetching data from the url %v: %v", file, err)
-			}
-		} else {
-			data, err = ioutil.ReadFile(file)
-			if err != nil {
-				return nil, fmt.Errorf("unable to read file '%s': %s", file, err)
-			}
+	// included files are merged the same way as the files given by -f
+	files = nil
+	var contents [][]byte
+	for _, d := range documents {
+		files = append(files, d.Location)
+		contents = append(contents, d.Data)
+	}
+
+	variables, err := GetVariables(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// declared parameters have to be known before the interpolation so references to them are kept
+	parameters, err := getParameters(files, contents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, data := range contents {
+		var warnings []string
+		contents[i], warnings, err = encoding.InterpolateVariables(data, variables, parameters)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not interpolate variables in file '%s': %s", files[i], err)
 		}
+
+		for _, warning := range warnings {
+			fmt.Fprintf(outerr, "WARNING: file '%s': %s\n", files[i], warning)
+		}
+	}
+
+	// parameter values could have been interpolated
+	parameters, err = getParameters(files, contents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var openCompose *object.OpenCompose
+	var errs pkgutil.ErrorList
+	for i, data := range contents {
+		file := files[i]
+
+		data, err := encoding.SubstituteParameters(data, parameters, resolveParameter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not substitute parameters in file '%s': %s", file, err)
+		}
+
+		data, warnings := encoding.MigrateForDecoding(data)
+		for _, warning := range warnings {
+			fmt.Fprintf(outerr, "WARNING: file '%s': %s\n", file, warning)
+		}
+
 		decoder, err := encoding.GetDecoderFor(data)
 		if err != nil {
-			return nil, fmt.Errorf("could not find decoder for resource '%s': %s", file, err)
+			return nil, nil, fmt.Errorf("could not find decoder for resource '%s': %s", file, err)
 		}
 
-		o, err := decoder.Decode(data)
+		// the first file is the base one and has to be complete,
+		// the following ones are merged over it and can set only the fields they override
+		var o *object.OpenCompose
+		if i == 0 {
+			o, err = decoder.Decode(data)
+		} else {
+			o, err = decoder.DecodeOverride(data)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("could not unmarshal data for file '%s': %s", file, err)
+			// the rest of the files are still decoded so all the errors are reported at once
+			errs = errs.Append(documents[i].wrapError(err, "could not unmarshal data"))
+			continue
+		}
+		if len(errs) > 0 {
+			continue
+		}
+
+		if openCompose == nil {
+			openCompose = o
+			continue
 		}
 
-		ocObjects = append(ocObjects, o)
+		if err := openCompose.Merge(o); err != nil {
+			return nil, nil, fmt.Errorf("could not merge file '%s': %s", file, err)
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := openCompose.ResolveTemplates(); err != nil {
+		return nil, nil, err
+	}
+
+	return openCompose, documents, nil
+}
+
+// Returns the object with the selected profiles applied once it is valid together with its warnings
+func getValidatedObject(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) (*object.OpenCompose, []document, error, error) {
+	openCompose, documents, err := getObject(v, cmd, out, outerr)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	// FIXME: implement merging OpenCompose obejcts
-	openCompose := ocObjects[0]
+	if err := openCompose.ResolveProfiles(cmdutil.GetStringSlice(v, cmdutil.Flag_Profile_Key)); err != nil {
+		return nil, nil, nil, err
+	}
 
 	if err := openCompose.Validate(); err != nil {
+		return nil, nil, nil, locateObjectError(err, documents)
+	}
+
+	return openCompose, documents, locateObjectError(openCompose.Warnings(), documents), nil
+}
+
+// Returns the object with the selected profiles applied once it is valid, warnings are printed to outerr
+func GetValidatedObject(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) (*object.OpenCompose, error) {
+	openCompose, _, warnings, err := getValidatedObject(v, cmd, out, outerr)
+	if err != nil {
 		return nil, err
 	}
 
+	for _, warning := range pkgutil.Errors(warnings) {
+		fmt.Fprintf(outerr, "WARNING: %s\n", warning)
+	}
+
 	return openCompose, nil
 }
 
+func GetTransformer(v *viper.Viper, cmd *cobra.Command) (transform.Transformer, error) {
+	distro := v.GetString(cmdutil.Flag_Distro_Key)
+	deploymentConfig := v.GetBool(cmdutil.Flag_DeploymentConfig_Key)
+	outputFormat := v.GetString(cmdutil.Flag_OutputFormat_Key)
+	switch d := strings.ToLower(distro); d {
+	case "kubernetes":
+		if deploymentConfig {
+			return nil, cmdutil.UsageError(cmd, "--%s can be used only with '--distro openshift'", cmdutil.Flag_DeploymentConfig_Key)
+		}
+		if outputFormat == cmdutil.OutputFormat_OpenShiftTemplate {
+			return nil, cmdutil.UsageError(cmd, "output format '%s' can be used only with '--distro openshift'", outputFormat)
+		}
+		return &kubernetes.Transformer{}, nil
+	case "compose":
+		if deploymentConfig {
+			return nil, cmdutil.UsageError(cmd, "--%s can be used only with '--distro openshift'", cmdutil.Flag_DeploymentConfig_Key)
+		}
+		if outputFormat == cmdutil.OutputFormat_OpenShiftTemplate {
+			return nil, cmdutil.UsageError(cmd, "output format '%s' can be used only with '--distro openshift'", outputFormat)
+		}
+		return &compose.Transformer{}, nil
+	case "openshift":
+		return &openshift.Transformer{
+			DeploymentConfig: deploymentConfig,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown distro '%s'", distro)
+	}
+}
+
+// Runs distribution specific checks of the generated objects.
+// Warnings are printed to outerr, errors make the check fail.
+// Returns settings of the generated objects that OpenShift would reject or that would break the pods,
+// they are checked only for OpenShift
+func sccViolations(v *viper.Viper, objects []runtime.Object) []openshift.SCCViolation {
+	if strings.ToLower(v.GetString(cmdutil.Flag_Distro_Key)) != "openshift" {
+		return nil
+	}
+	return openshift.CheckRestrictedSCC(objects)
+}
+
+func CheckObjects(v *viper.Viper, objects []runtime.Object, outerr io.Writer) error {
+	for _, obj := range objects {
+		if f, ok := obj.(*compose.File); ok {
+			for _, warning := range f.Warnings {
+				fmt.Fprintf(outerr, "WARNING: %s\n", warning)
+			}
+		}
+	}
+
+	errors := 0
+	for _, violation := range sccViolations(v, objects) {
+		fmt.Fprintln(outerr, violation)
+		if violation.Severity == openshift.SCCSeverity_Error {
+			errors++
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("generated objects have %d setting(s) that 'restricted' SecurityContextConstraints would reject", errors)
+	}
+
+	return nil
+}
+
+// Name of the Template defaults to the name of the first OpenCompose file
+func templateName(v *viper.Viper) string {
+	if name := v.GetString(cmdutil.Flag_TemplateName_Key); name != "" {
+		return name
+	}
+
+	files := cmdutil.GetStringSlice(v, cmdutil.Flag_File_Key)
+	if len(files) == 0 || files[0] == "-" {
+		return "opencompose"
+	}
+
+	base := path.Base(files[0])
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
 func RunConvert(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error {
+	outputFormat := v.GetString(cmdutil.Flag_OutputFormat_Key)
+	switch outputFormat {
+	case cmdutil.OutputFormat_Objects, cmdutil.OutputFormat_OpenShiftTemplate:
+	default:
+		return cmdutil.UsageError(cmd, "unknown output format '%s'", outputFormat)
+	}
+
 	o, err := GetValidatedObject(v, cmd, out, outerr)
 	if err != nil {
 		return err
 	}
 
-	var transformer transform.Transformer
-	distro := v.GetString("distro")
-	switch d := strings.ToLower(distro); d {
-	case "kubernetes":
-		transformer = &kubernetes.Transformer{}
-	case "openshift":
-		transformer = &openshift.Transformer{}
-	default:
-		return fmt.Errorf("unknown distro '%s'", distro)
+	transformer, err := GetTransformer(v, cmd)
+	if err != nil {
+		return err
 	}
 
 	runtimeObjects, err := transformer.Transform(o)
@@ -135,6 +386,18 @@ func RunConvert(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error
 		return fmt.Errorf("transformation failed: %s", err)
 	}
 
+	if err := CheckObjects(v, runtimeObjects, outerr); err != nil {
+		return err
+	}
+
+	if outputFormat == cmdutil.OutputFormat_OpenShiftTemplate {
+		template, err := openshift.CreateTemplate(templateName(v), o.Parameters, runtimeObjects)
+		if err != nil {
+			return fmt.Errorf("failed to create template: %s", err)
+		}
+		runtimeObjects = []runtime.Object{template}
+	}
+
 	var writeObject func(o runtime.Object, data []byte) error
 	outputDir := v.GetString(cmdutil.Flag_OutputDir_Key)
 	if outputDir == "" || outputDir == "-" {
@@ -151,13 +414,16 @@ func RunConvert(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error
 	} else {
 		// write files
 		writeObject = func(o runtime.Object, data []byte) error {
-			kind := o.GetObjectKind().GroupVersionKind().Kind
-			m, ok := o.(meta.Object)
-			if !ok {
-				return fmt.Errorf("failed to cast runtime.object to meta.object (type is %s): %s", reflect.TypeOf(o).String(), err)
-			}
+			filename := compose.Filename
+			if _, ok := o.(*compose.File); !ok {
+				kind := o.GetObjectKind().GroupVersionKind().Kind
+				m, ok := o.(meta.Object)
+				if !ok {
+					return fmt.Errorf("failed to cast runtime.object to meta.object (type is %s): %s", reflect.TypeOf(o).String(), err)
+				}
 
-			filename := fmt.Sprintf("%s-%s.yaml", m.GetName(), strings.ToLower(kind))
+				filename = fmt.Sprintf("%s-%s.yaml", m.GetName(), strings.ToLower(kind))
+			}
 
 			err := ioutil.WriteFile(path.Join(outputDir, filename), data, 0644)
 			if err != nil {
@@ -171,6 +437,19 @@ func RunConvert(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error
 	}
 
 	for _, runtimeObject := range runtimeObjects {
+		// docker-compose file is not a Kubernetes object, it has no kind to set
+		if file, ok := runtimeObject.(*compose.File); ok {
+			data, err := file.Marshal()
+			if err != nil {
+				return fmt.Errorf("failed to marshal docker-compose file: %s", err)
+			}
+
+			if err := writeObject(file, data); err != nil {
+				return fmt.Errorf("failed to write object: %s", err)
+			}
+			continue
+		}
+
 		gvk, isUnversioned, err := api.Scheme.ObjectKind(runtimeObject)
 		if err != nil {
 			return fmt.Errorf("ConvertToVersion failed: %s", err)
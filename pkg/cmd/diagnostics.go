@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/lint"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	"github.com/redhat-developer/opencompose/pkg/policy"
+	"github.com/redhat-developer/opencompose/pkg/transform/openshift"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+)
+
+const (
+	Severity_Error   = "error"
+	Severity_Warning = "warning"
+)
+
+// Descriptions of all the error codes that can be reported
+func errorCodeDescriptions() map[pkgutil.ErrorCode]string {
+	descriptions := map[pkgutil.ErrorCode]string{
+		pkgutil.ErrorCode_Unknown:         "The problem doesn't have a more specific code",
+		openshift.ErrorCode_RestrictedSCC: "The generated objects don't comply with the 'restricted' SecurityContextConstraints",
+		policy.ErrorCode_PolicyViolation:  "The value doesn't comply with a rule of the policy",
+	}
+	for code, description := range encodingutil.ErrorCodeDescriptions {
+		descriptions[code] = description
+	}
+	for code, description := range object.ErrorCodeDescriptions {
+		descriptions[code] = description
+	}
+	for code, description := range lint.ErrorCodeDescriptions() {
+		descriptions[code] = description
+	}
+	return descriptions
+}
+
+type DiagnosticLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+}
+
+// Diagnostic is a problem found in OpenCompose files in a form suitable for tools
+type Diagnostic struct {
+	Severity string            `json:"severity"`
+	Code     pkgutil.ErrorCode `json:"code"`
+	Message  string            `json:"message"`
+	// JSON pointer to the value in the file, items of lists can be identified by their names
+	Path string `json:"path,omitempty"`
+	// Profile the problem is found with, empty when it's there without any
+	Profile string `json:"profile,omitempty"`
+	// Lint rule that found the problem
+	Rule     string              `json:"rule,omitempty"`
+	Location *DiagnosticLocation `json:"location,omitempty"`
+}
+
+// Returns Diagnostic for err, position, path and profile are taken out of the message
+func NewDiagnostic(err error) Diagnostic {
+	d := Diagnostic{
+		Severity: Severity_Error,
+		Code:     pkgutil.CodeOf(err),
+	}
+
+	for {
+		switch e := err.(type) {
+		case profileError:
+			d.Profile = e.Profile
+			err = e.Err
+			continue
+		case warningError:
+			d.Severity = Severity_Warning
+			err = e.Err
+			continue
+		case encodingutil.PositionError:
+			if e.File != "" {
+				d.Location = &DiagnosticLocation{File: e.File, Line: e.Position.Line, Column: e.Position.Column}
+			}
+			err = e.Err
+			continue
+		case encodingutil.PathError:
+			d.Path = e.Path
+			d.Message = e.Message()
+		case *object.FieldError:
+			d.Path = e.Path
+			d.Message = e.Error()
+		case lintError:
+			if e.Severity == lint.Severity_Warning {
+				d.Severity = Severity_Warning
+			}
+			d.Rule = e.Rule
+			d.Path = e.Path
+			d.Message = e.Message
+		case policy.Violation:
+			if e.Target == policy.Target_OpenCompose {
+				d.Path = e.Path
+			}
+			d.Message = e.Error()
+		case sccViolationError:
+			if e.Severity == openshift.SCCSeverity_Warning {
+				d.Severity = Severity_Warning
+			}
+			d.Message = e.Error()
+		default:
+			d.Message = err.Error()
+		}
+		return d
+	}
+}
+
+func WriteDiagnosticsJSON(out io.Writer, diagnostics []Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}{diagnostics}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal diagnostics: %s", err)
+	}
+
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
+
+// Subset of SARIF 2.1.0 (Static Analysis Results Interchange Format) used for the diagnostics
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+func WriteSARIF(out io.Writer, diagnostics []Diagnostic) error {
+	descriptions := errorCodeDescriptions()
+
+	results := []sarifResult{}
+	used := make(map[pkgutil.ErrorCode]bool)
+	for _, d := range diagnostics {
+		used[d.Code] = true
+
+		message := d.Message
+		if d.Profile != "" {
+			message = fmt.Sprintf("profile %q: %s", d.Profile, message)
+		}
+
+		result := sarifResult{
+			RuleID:  string(d.Code),
+			Level:   d.Severity,
+			Message: sarifMessage{Text: message},
+		}
+
+		if d.Location != nil || d.Path != "" {
+			var location sarifLocation
+			if d.Location != nil {
+				location.PhysicalLocation = &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(d.Location.File)},
+					Region:           sarifRegion{StartLine: d.Location.Line, StartColumn: d.Location.Column},
+				}
+			}
+			if d.Path != "" {
+				location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: d.Path}}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+
+		results = append(results, result)
+	}
+
+	// rules of the reported codes, in a stable order
+	var codes []string
+	for code := range used {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+
+	rules := []sarifRule{}
+	for _, code := range codes {
+		rules = append(rules, sarifRule{
+			ID:               code,
+			ShortDescription: sarifMessage{Text: descriptions[pkgutil.ErrorCode(code)]},
+		})
+	}
+
+	data, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "opencompose",
+						InformationURI: "https://github.com/redhat-developer/opencompose",
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF log: %s", err)
+	}
+
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	cmdutil "github.com/redhat-developer/opencompose/pkg/cmd/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	explainExample = `
+  # Describe the top level sections of OpenCompose file
+  opencompose explain
+
+  # Describe ports of containers and their fields
+  opencompose explain services.containers.ports`
+)
+
+// Width descriptions are wrapped at
+const explainTextWidth = 80
+
+func NewCmdExplain(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "explain [FIELD]",
+		Short:   "Describe fields of OpenCompose files",
+		Long:    "Describe fields of OpenCompose files: their type, whether they are required, allowed and default values and what they are for. Nested fields are separated by '.', list items and map values are traversed implicitly.",
+		Example: explainExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunExplain(v, cmd, args, out, outerr)
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Parent().PersistentPreRunE != nil {
+				if err := cmd.Parent().PersistentPreRunE(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
+			cmdutil.AddExplainFlagsViper(v, cmd)
+
+			return nil
+		},
+	}
+
+	cmdutil.AddExplainFlags(cmd)
+
+	return cmd
+}
+
+// Returns the alternative types s accepts, the one for references left out
+func withoutReferences(s *encodingutil.Schema) []*encodingutil.Schema {
+	// anyOf of a typed schema only adds constraints, e.g. on the required fields
+	if len(s.AnyOf) == 0 || s.Type != "" {
+		return []*encodingutil.Schema{s}
+	}
+
+	var alternatives []*encodingutil.Schema
+	for _, alternative := range s.AnyOf {
+		if alternative.Pattern == encodingutil.ReferencePattern {
+			continue
+		}
+		alternatives = append(alternatives, withoutReferences(alternative)...)
+	}
+	return alternatives
+}
+
+// Returns name of the type s describes, e.g. "[]object" or "integer | string"
+func schemaTypeName(s *encodingutil.Schema) string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, alternative := range withoutReferences(s) {
+		var name string
+		switch {
+		case alternative.Type == "array" && alternative.Items != nil:
+			name = "[]" + schemaTypeName(alternative.Items)
+			if strings.Contains(name, " | ") {
+				name = "[](" + name[2:] + ")"
+			}
+		case alternative.Type == "object" && alternative.Properties == nil:
+			name = "map[string]"
+			if values, ok := alternative.AdditionalProperties.(*encodingutil.Schema); ok {
+				name += schemaTypeName(values)
+			}
+		case alternative.Type != "":
+			name = alternative.Type
+		default:
+			continue
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "any"
+	}
+	return strings.Join(names, " | ")
+}
+
+// Returns whether field name of object is required, fields required only in some
+// of the alternatives are required unless the fields of the other alternatives are set
+func requiredText(object *encodingutil.Schema, name string) string {
+	if object == nil {
+		return "no"
+	}
+
+	for _, required := range object.Required {
+		if required == name {
+			return "yes"
+		}
+	}
+
+	found := false
+	var others []string
+	for _, alternative := range object.AnyOf {
+		requiredHere := false
+		for _, required := range alternative.Required {
+			if required == name {
+				requiredHere = true
+			}
+		}
+		if requiredHere {
+			found = true
+		} else {
+			others = append(others, strings.Join(alternative.Required, " and "))
+		}
+	}
+
+	if !found {
+		return "no"
+	}
+	if len(others) == 0 {
+		return "yes"
+	}
+	return fmt.Sprintf("yes, unless %s is set", strings.Join(others, " or "))
+}
+
+// Returns lines of text wrapped at width, each prefixed by indent
+func wrapText(text string, indent string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(indent)+len(line)+1+len(word) > width {
+			lines = append(lines, indent+line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, indent+line)
+	}
+	return lines
+}
+
+func RunExplain(v *viper.Viper, cmd *cobra.Command, args []string, out, outerr io.Writer) error {
+	if len(args) > 1 {
+		return cmdutil.UsageError(cmd, "Too many arguments. Expected only the field, e.g. opencompose explain services.containers")
+	}
+
+	schema, err := encoding.GetSchemaFor(v.GetString(cmdutil.Flag_Version_Key))
+	if err != nil {
+		return err
+	}
+
+	path := ""
+	if len(args) == 1 {
+		path = strings.Trim(args[0], ".")
+	}
+
+	field := schema
+	var parent *encodingutil.Schema
+	var names []string
+	if path != "" {
+		for _, name := range strings.Split(path, ".") {
+			parent = field.Object()
+			field = field.Property(name)
+			if field == nil {
+				if len(names) == 0 {
+					return fmt.Errorf("field %q does not exist", name)
+				}
+				return fmt.Errorf("field %q does not exist in %q", name, strings.Join(names, "."))
+			}
+			names = append(names, name)
+		}
+	}
+
+	var lines []string
+	if path == "" {
+		lines = append(lines, fmt.Sprintf("FILE:     %s", schema.Title))
+	} else {
+		lines = append(lines,
+			fmt.Sprintf("FIELD:    %s <%s>", path, schemaTypeName(field)),
+			fmt.Sprintf("REQUIRED: %s", requiredText(parent, names[len(names)-1])),
+		)
+	}
+
+	if field.Default != nil {
+		lines = append(lines, fmt.Sprintf("DEFAULT:  %v", field.Default))
+	}
+
+	for _, alternative := range withoutReferences(field) {
+		if len(alternative.Enum) > 0 {
+			lines = append(lines, fmt.Sprintf("ALLOWED:  %s", strings.Join(alternative.Enum, ", ")))
+		}
+		if alternative.Pattern != "" {
+			lines = append(lines, fmt.Sprintf("PATTERN:  %s", alternative.Pattern))
+		}
+	}
+
+	if field.Description != "" {
+		lines = append(lines, "", "DESCRIPTION:")
+		lines = append(lines, wrapText(field.Description, "     ", explainTextWidth)...)
+	}
+
+	if object := field.Object(); object != nil && len(object.Properties) > 0 {
+		var properties []string
+		for name := range object.Properties {
+			properties = append(properties, name)
+		}
+		sort.Strings(properties)
+
+		lines = append(lines, "", "FIELDS:")
+		for _, name := range properties {
+			property := object.Properties[name]
+
+			header := fmt.Sprintf("   %s\t<%s>", name, schemaTypeName(property))
+			if requiredText(object, name) == "yes" {
+				header += " -required-"
+			}
+			lines = append(lines, header)
+			lines = append(lines, wrapText(property.Description, "     ", explainTextWidth)...)
+			lines = append(lines, "")
+		}
+		lines = lines[:len(lines)-1]
+	}
+
+	_, err = fmt.Fprintln(out, strings.Join(lines, "\n"))
+	return err
+}
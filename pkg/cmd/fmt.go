@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cmdutil "github.com/redhat-developer/opencompose/pkg/cmd/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	fmtExample = `
+  # Format OpenCompose files in place
+  opencompose fmt -f opencompose.yaml -f opencompose-prod.yaml
+
+  # Check that the files are formatted, e.g. in CI
+  opencompose fmt --check -f opencompose.yaml`
+)
+
+// Matches parameter references ${{NAME}}, ${NAME} and variable references with defaults
+var referenceRegexp = regexp.MustCompile(`\$\{\{[A-Za-z_][A-Za-z0-9_]*\}\}|\$\{[A-Za-z_][A-Za-z0-9_]*(?:(?::-|:\?)[^}]*)?\}`)
+
+func NewCmdFmt(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "fmt",
+		Short:   "Rewrite OpenCompose files in the canonical format",
+		Long:    "Rewrite OpenCompose files in the canonical format: fields in a stable order, values that are not set (or are set to their defaults) left out.",
+		Example: fmtExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunFmt(v, cmd, out, outerr)
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Parent().PersistentPreRunE != nil {
+				if err := cmd.Parent().PersistentPreRunE(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
+			cmdutil.AddFmtFlagsViper(v, cmd)
+
+			return nil
+		},
+	}
+
+	cmdutil.AddFmtFlags(cmd)
+
+	return cmd
+}
+
+// Replaces parameter and variable references by placeholders so the file can be decoded
+// without resolving them. ${{NAME}} is used in non-string fields so it gets a number.
+// Returns function that replaces the placeholders in formatted file back by the references.
+func protectReferences(data []byte) ([]byte, func([]byte) []byte) {
+	placeholders := make(map[string]string)
+	var order []string
+
+	data = referenceRegexp.ReplaceAllFunc(data, func(reference []byte) []byte {
+		if placeholder, ok := placeholders[string(reference)]; ok {
+			return []byte(placeholder)
+		}
+
+		var placeholder string
+		for n := len(placeholders); ; n++ {
+			if strings.HasPrefix(string(reference), "${{") {
+				placeholder = strconv.Itoa(2000000000 + n)
+			} else {
+				placeholder = fmt.Sprintf("ocref%d", n)
+			}
+			if !bytes.Contains(data, []byte(placeholder)) {
+				break
+			}
+		}
+
+		placeholders[string(reference)] = placeholder
+		order = append(order, string(reference))
+		return []byte(placeholder)
+	})
+
+	restore := func(data []byte) []byte {
+		for _, reference := range order {
+			placeholder := regexp.QuoteMeta(placeholders[reference])
+			// yaml quotes the numbers if they ended up in string fields
+			r := regexp.MustCompile(fmt.Sprintf(`"%s"|\b%s\b`, placeholder, placeholder))
+			data = r.ReplaceAllLiteral(data, []byte(reference))
+		}
+		return data
+	}
+
+	return data, restore
+}
+
+// Returns data in the canonical format, comments, extension fields and anchors are kept
+func formatFile(data []byte) ([]byte, error) {
+	if encodingutil.IsJSON(data) {
+		return nil, fmt.Errorf("%s", "only YAML files can be formatted")
+	}
+
+	protected, restore := protectReferences(data)
+
+	// files of older spec versions have to be rewritten completely
+	if _, from, changes, err := encoding.Migrate(protected); err == nil && len(changes) > 0 {
+		return nil, fmt.Errorf("the file is written for version %q, run 'opencompose migrate' on it first", from)
+	}
+
+	version, err := encoding.GetVersion(protected)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, err := encoding.GetEncoderFor(version)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := encoder.Format(protected)
+	if err != nil {
+		return nil, err
+	}
+
+	return restore(formatted), nil
+}
+
+func RunFmt(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error {
+	files := cmdutil.GetStringSlice(v, cmdutil.Flag_File_Key)
+	if len(files) < 1 {
+		return cmdutil.UsageError(cmd, "there has to be at least one file")
+	}
+	check := v.GetBool(cmdutil.Flag_Check_Key)
+
+	var unformatted []string
+	for _, file := range files {
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(file)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read file '%s': %s", file, err)
+		}
+
+		formatted, err := formatFile(data)
+		if err != nil {
+			return fmt.Errorf("could not format file '%s': %s", file, err)
+		}
+
+		// STDIN can't be rewritten in place
+		if file == "-" && !check {
+			if _, err := out.Write(formatted); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if bytes.Equal(data, formatted) {
+			continue
+		}
+
+		if check {
+			fmt.Fprintln(out, file)
+			unformatted = append(unformatted, file)
+			continue
+		}
+
+		if err := ioutil.WriteFile(file, formatted, 0644); err != nil {
+			return fmt.Errorf("failed to write file '%s': %s", file, err)
+		}
+	}
+
+	if len(unformatted) > 0 {
+		return fmt.Errorf("%d file(s) not formatted, run 'opencompose fmt' on them", len(unformatted))
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+)
+
+func TestFormatFile(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Succeed bool
+		File    string
+		// expected result, empty if the file is formatted already
+		Formatted string
+	}{
+		{
+			"Formatted file stays the same",
+			true,
+			`version: 0.1-dev
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx
+    ports:
+    - port: 8080
+      type: external
+`,
+			"",
+		},
+		{
+			"Fields are ordered and defaults left out",
+			true,
+			`services:
+  - containers:
+      - ports: [{port: "80", type: internal}]
+        image: 'nginx'
+        name: nginx
+        env: [{name: A, value: 'yes'}]
+    name: web
+    labels: {}
+version: 0.1-dev
+`,
+			`version: 0.1-dev
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx
+    env:
+    - name: A
+      value: 'yes'
+    ports:
+    - port: "80"
+`,
+		},
+		{
+			"Comments, extension fields and anchors are kept",
+			true,
+			`# Web application
+x-common: &common
+  env:
+  - name: MODE
+    value: prod
+version: 0.1-dev
+services:
+# the database
+- x-note: kept
+  name: db
+  containers:
+  - <<: *common
+    name: mysql
+    image: mysql # opencompose:ignore image-tag
+`,
+			"",
+		},
+		{
+			"Extension fields go first",
+			true,
+			`version: 0.1-dev
+x-common: &common
+  env:
+  - name: MODE
+    value: prod
+services:
+- name: web
+  containers:
+  - <<: *common
+    name: nginx
+    image: nginx
+`,
+			`x-common: &common
+  env:
+  - name: MODE
+    value: prod
+version: 0.1-dev
+services:
+- name: web
+  containers:
+  - <<: *common
+    name: nginx
+    image: nginx
+`,
+		},
+		{
+			"References are kept",
+			true,
+			`version: 0.1-dev
+services:
+- replicas: ${{REPLICAS}}
+  name: web
+  containers:
+  - name: nginx
+    image: "nginx:${TAG:-latest}"
+`,
+			`version: 0.1-dev
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx:${TAG:-latest}
+  replicas: ${{REPLICAS}}
+`,
+		},
+		{
+			"Alias can't end up before its anchor",
+			false,
+			`version: 0.1-dev
+services:
+- name: web
+  labels: &labels
+    app: web
+  containers:
+  - name: nginx
+    image: nginx
+  x-labels: *labels
+`,
+			"",
+		},
+		{
+			"Older version",
+			false,
+			`version: 0.1-dev
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx
+    env:
+    - MODE=prod
+`,
+			"",
+		},
+		{
+			"JSON",
+			false,
+			`{"version": "0.1-dev", "services": []}`,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			formatted, err := formatFile([]byte(tt.File))
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to format: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got:\n%s", formatted)
+			}
+
+			expected := tt.Formatted
+			if expected == "" {
+				expected = tt.File
+			}
+			if string(formatted) != expected {
+				t.Fatalf("Expected:\n%s\ngot:\n%s", expected, formatted)
+			}
+
+			// formatting is idempotent
+			again, err := formatFile(formatted)
+			if err != nil {
+				t.Fatalf("Failed to format the formatted file: %s", err)
+			}
+			if string(again) != string(formatted) {
+				t.Fatalf("Formatted file changed when formatted again:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	current := "version: 0.1-dev\nservices: []  # none yet\n"
+	if migrated, _, _, err := migrateFile([]byte(current)); err != nil || migrated != nil {
+		t.Fatalf("Expected current file with comments to be left as it is, got %q, %v", migrated, err)
+	}
+
+	outdated := `x-env: &env
+- DEBUG=0
+# web application
+version: 0.1-dev
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx
+    env:
+    # production
+    - MODE=prod  # mode
+    - URL=http://${HOST}/
+    mounts:
+    - volumeName: data  # persistent
+      mountPath: /data
+- name: worker
+  containers:
+  - name: worker
+    image: worker
+    env: *env
+`
+	expected := `x-env: &env
+- name: DEBUG
+  value: "0"
+# web application
+version: 0.1-dev
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx
+    env:
+    # production
+    - name: MODE
+      value: prod # mode
+    - name: URL
+      value: http://${HOST}/
+    mounts:
+    - volumeRef: data # persistent
+      mountPath: /data
+- name: worker
+  containers:
+  - name: worker
+    image: worker
+    env: *env
+`
+	migrated, from, changes, err := migrateFile([]byte(outdated))
+	if err != nil {
+		t.Fatalf("Failed to migrate file: %s", err)
+	}
+	if from != encoding.Version_0_1_0 || len(changes) != 4 {
+		t.Errorf("Expected 4 changes from version %q, got %q, %#v", encoding.Version_0_1_0, from, changes)
+	}
+	if string(migrated) != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, migrated)
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	cmdutil "github.com/redhat-developer/opencompose/pkg/cmd/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/importer"
+	"github.com/redhat-developer/opencompose/pkg/importer/compose"
+	"github.com/redhat-developer/opencompose/pkg/importer/kubernetes"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	importComposeExample = `
+  # Import docker-compose file into OpenCompose file
+  opencompose import compose -f docker-compose.yml > opencompose.yaml`
+
+	importKubernetesExample = `
+  # Import the manifests in directory into OpenCompose file
+  opencompose import kubernetes -f manifests/ > opencompose.yaml
+
+  # Import manifests of an application deployed to cluster
+  kubectl get deployments,services,ingresses,pvc -l app=web -o yaml | opencompose import kubernetes -f -`
+)
+
+func NewCmdImport(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import files of other tools into OpenCompose",
+		Long:  "Import files of other tools into OpenCompose. The parts that can't be represented in OpenCompose are reported as warnings.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+			return errors.New("You have to specify what to import.")
+		},
+	}
+	// the subcommands call it as the PersistentPreRunE of their parent so it can't use its cmd argument
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		if cmd.Parent().PersistentPreRunE != nil {
+			return cmd.Parent().PersistentPreRunE(c, args)
+		}
+		return nil
+	}
+
+	cmd.AddCommand(NewCmdImportCompose(v, out, outerr))
+	cmd.AddCommand(NewCmdImportKubernetes(v, out, outerr))
+
+	return cmd
+}
+
+func NewCmdImportCompose(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "compose",
+		Short:   "Import docker-compose files into OpenCompose",
+		Long:    "Import docker-compose files of version 2 and 3 and print the equivalent OpenCompose file. Keys that can't be represented in OpenCompose are left out and reported as warnings.",
+		Example: importComposeExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunImportCompose(v, cmd, out, outerr)
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Parent().PersistentPreRunE != nil {
+				if err := cmd.Parent().PersistentPreRunE(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
+			cmdutil.AddImportFlagsViper(v, cmd)
+
+			return nil
+		},
+	}
+
+	cmdutil.AddImportFlags(cmd)
+
+	return cmd
+}
+
+func NewCmdImportKubernetes(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "kubernetes",
+		Short:   "Import Kubernetes manifests into OpenCompose",
+		Long:    "Import Deployments, Services, Ingresses and PersistentVolumeClaims and print the equivalent OpenCompose file. Services are matched to Deployments by their selectors. Objects and fields that can't be represented in OpenCompose are left out and reported as warnings.",
+		Example: importKubernetesExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunImportKubernetes(v, cmd, out, outerr)
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Parent().PersistentPreRunE != nil {
+				if err := cmd.Parent().PersistentPreRunE(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
+			cmdutil.AddImportFlagsViper(v, cmd)
+
+			return nil
+		},
+	}
+
+	cmdutil.AddImportFlags(cmd)
+
+	return cmd
+}
+
+// Prints the problems of the imported documents located in them
+func printImportProblems(outerr io.Writer, d document, problems []importer.Problem) {
+	index := encodingutil.IndexPositions(d.Data)
+
+	var errs []error
+	for _, p := range problems {
+		if position, _, ok := index.Lookup(p.Path); ok {
+			errs = append(errs, encodingutil.NewPositionError(d.Location, d.Data, position, p))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("file '%s': %s", d.Location, p))
+	}
+	sort.Stable(byPosition(errs))
+
+	for _, err := range errs {
+		fmt.Fprintf(outerr, "WARNING: %s\n", err)
+	}
+}
+
+// Validates and prints the imported OpenCompose, what's not valid is reported as warnings
+// so it can be fixed in the printed file
+func writeImported(o *object.OpenCompose, out, outerr io.Writer) error {
+	if err := o.Validate(); err != nil {
+		for _, e := range pkgutil.Errors(err) {
+			fmt.Fprintf(outerr, "WARNING: imported OpenCompose is not valid: %s\n", e)
+		}
+	}
+
+	encoder, err := encoding.GetEncoderFor(o.Version)
+	if err != nil {
+		return err
+	}
+
+	data, err := encoder.Encode(o)
+	if err != nil {
+		return fmt.Errorf("failed to encode OpenCompose: %s", err)
+	}
+
+	// what is written has to be read back the same way, e.g. by 'opencompose convert'
+	decoder, err := encoding.GetDecoderFor(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode imported OpenCompose: %s", err)
+	}
+	if _, err := decoder.Decode(data); err != nil {
+		for _, e := range pkgutil.Errors(err) {
+			fmt.Fprintf(outerr, "WARNING: imported OpenCompose can't be decoded: %s\n", e)
+		}
+	}
+
+	_, err = out.Write(data)
+	return err
+}
+
+func RunImportCompose(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error {
+	files := cmdutil.GetStringSlice(v, cmdutil.Flag_File_Key)
+	// overrides of docker-compose files leave out what OpenCompose requires, they can't be imported on their own
+	if len(files) != 1 {
+		return cmdutil.UsageError(cmd, "there has to be exactly one file")
+	}
+	file := files[0]
+
+	data, err := readLocation(file)
+	if err != nil {
+		return err
+	}
+
+	o, problems, err := compose.Import(data)
+	if err != nil {
+		return fmt.Errorf("could not import file '%s': %s", file, err)
+	}
+	printImportProblems(outerr, document{Location: file, Data: data}, problems)
+
+	return writeImported(o, out, outerr)
+}
+
+// Returns the manifest files in the directory at location in lexical order, other locations are returned as they are
+func manifestFiles(location string) ([]string, error) {
+	if location == "-" || isURL(location) {
+		return []string{location}, nil
+	}
+
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file '%s': %s", location, err)
+	}
+	if !info.IsDir() {
+		return []string{location}, nil
+	}
+
+	var files []string
+	err = filepath.Walk(location, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+			if !info.IsDir() {
+				files = append(files, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read directory '%s': %s", location, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("directory '%s' has no YAML or JSON files", location)
+	}
+	return files, nil
+}
+
+func RunImportKubernetes(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error {
+	locations := cmdutil.GetStringSlice(v, cmdutil.Flag_File_Key)
+	if len(locations) == 0 {
+		return cmdutil.UsageError(cmd, "there has to be at least one file or directory")
+	}
+
+	var manifests []kubernetes.Manifest
+	for _, location := range locations {
+		files, err := manifestFiles(location)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			data, err := readLocation(file)
+			if err != nil {
+				return err
+			}
+
+			decoded, err := kubernetes.Decode(file, data)
+			if err != nil {
+				return fmt.Errorf("could not decode file '%s': %s", file, err)
+			}
+			manifests = append(manifests, decoded...)
+		}
+	}
+
+	o, problems, err := kubernetes.Import(manifests)
+	if err != nil {
+		return fmt.Errorf("could not import manifests: %s", err)
+	}
+	// the problems are in objects of multi-document files, they are reported by the objects instead of positions
+	for _, p := range problems {
+		fmt.Fprintf(outerr, "WARNING: file '%s': %s\n", p.File, p)
+	}
+
+	return writeImported(o, out, outerr)
+}
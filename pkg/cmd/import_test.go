@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	cmdutil "github.com/redhat-developer/opencompose/pkg/cmd/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	"github.com/redhat-developer/opencompose/pkg/importer/kubernetes"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestWriteImported(t *testing.T) {
+	o := &object.OpenCompose{
+		Version: encoding.CurrentVersion,
+		Services: []object.Service{
+			{
+				Name: "web",
+				Containers: []object.Container{
+					{
+						Name:  "nginx",
+						Image: "nginx",
+						Environment: []object.EnvVariable{
+							{Key: "EMPTY", Value: ""},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var out, outerr bytes.Buffer
+	if err := writeImported(o, &out, &outerr); err != nil {
+		t.Fatalf("Failed to write: %s", err)
+	}
+	if outerr.Len() > 0 {
+		t.Errorf("Expected no warnings, got:\n%s", outerr.String())
+	}
+
+	decoder, err := encoding.GetDecoderFor(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decoder.Decode(out.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to decode the written file: %s\n%s", err, out.String())
+	}
+	if env := decoded.Services[0].Containers[0].Environment; len(env) != 1 || env[0].Key != "EMPTY" || env[0].Value != "" {
+		t.Errorf("Unexpected environment variables: %#v", env)
+	}
+}
+
+func TestImportKubernetesRoundTrip(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: app
+        image: app
+        args: ["${{NAME}}", "$$"]
+        env:
+        - name: DIR
+          value: ${HOME}/x
+`
+	manifests, err := kubernetes.Decode("manifests.yaml", []byte(manifest))
+	if err != nil {
+		t.Fatalf("Failed to decode: %s", err)
+	}
+	o, _, err := kubernetes.Import(manifests)
+	if err != nil {
+		t.Fatalf("Failed to import: %s", err)
+	}
+
+	var out, outerr bytes.Buffer
+	if err := writeImported(o, &out, &outerr); err != nil {
+		t.Fatalf("Failed to write: %s", err)
+	}
+
+	file, err := ioutil.TempFile("", "opencompose-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(out.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	// the values are read back the same way 'opencompose convert' reads them
+	v := viper.New()
+	v.Set(cmdutil.Flag_File_Key, []string{file.Name()})
+	v.Set(cmdutil.Flag_Set_Key, []string{"HOME=/home/user"})
+	read, err := GetObject(v, &cobra.Command{}, &out, &outerr)
+	if err != nil {
+		t.Fatalf("Failed to read imported file: %s\n%s", err, out.String())
+	}
+
+	container := read.Services[0].Containers[0]
+	if env := container.Environment; len(env) != 1 || env[0].Value != "${HOME}/x" {
+		t.Errorf("Expected the environment variable to be unchanged, got %#v", env)
+	}
+	if args := container.Args; !reflect.DeepEqual(args, []string{"${{NAME}}", "$$"}) {
+		t.Errorf("Expected the arguments to be unchanged, got %#v", args)
+	}
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+)
+
+// document is a single OpenCompose file, either given by -f or included
+type document struct {
+	Location string
+	Data     []byte
+}
+
+// Sorts errors in the order they appear in the file, the ones without position go last
+type byPosition []error
+
+func (p byPosition) Len() int      { return len(p) }
+func (p byPosition) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p byPosition) Less(i, j int) bool {
+	pi, iok := p[i].(encodingutil.PositionError)
+	pj, jok := p[j].(encodingutil.PositionError)
+	if !iok || !jok {
+		return iok && !jok
+	}
+	if pi.Position.Line != pj.Position.Line {
+		return pi.Position.Line < pj.Position.Line
+	}
+	return pi.Position.Column < pj.Position.Column
+}
+
+// Returns err with context; when the error points to a value or a position in the document
+// the file, line and column are attached to it. Every error of pkgutil.ErrorList is wrapped on its own.
+func (d *document) wrapError(err error, context string) error {
+	if list, ok := err.(pkgutil.ErrorList); ok {
+		var errs pkgutil.ErrorList
+		for _, e := range list {
+			errs = append(errs, d.wrapError(e, context))
+		}
+
+		sort.Stable(byPosition(errs))
+		return errs
+	}
+
+	switch e := err.(type) {
+	case encodingutil.PositionError:
+		if e.File == "" {
+			return encodingutil.NewPositionError(d.Location, d.Data, e.Position, encodingutil.Wrapf(e.Err, "%s", context))
+		}
+	case encodingutil.PathError:
+		if position, _, ok := encodingutil.IndexPositions(d.Data).Lookup(e.Path); ok {
+			return encodingutil.NewPositionError(d.Location, d.Data, position, encodingutil.Wrapf(e, "%s", context))
+		}
+	}
+	return fmt.Errorf("%s for file '%s': %s", context, d.Location, err)
+}
+
+// Attaches file, line and column of the value the error of the merged object is about.
+// The value is looked up by its path in all the files, the one where the most of the path
+// is found wins and the later files win ties because they override the earlier ones.
+// Every error of pkgutil.ErrorList is located on its own.
+func locateObjectError(err error, documents []document) error {
+	if list, ok := err.(pkgutil.ErrorList); ok {
+		var errs pkgutil.ErrorList
+		for _, e := range list {
+			errs = append(errs, locateObjectError(e, documents))
+		}
+		return errs
+	}
+
+	fe, ok := err.(*object.FieldError)
+	if !ok {
+		return err
+	}
+
+	return locatePathError(err, fe.Path, documents)
+}
+
+// Attaches file, line and column of the value at path of the merged object to err,
+// the value is looked up the same way as by locateObjectError
+func locatePathError(err error, path string, documents []document) error {
+	found := -1
+	var position encodingutil.Position
+	longest := ""
+	for i := len(documents) - 1; i >= 0; i-- {
+		p, prefix, ok := encodingutil.IndexPositions(documents[i].Data).Lookup(path)
+		if ok && prefix != "" && len(prefix) > len(longest) {
+			found, position, longest = i, p, prefix
+		}
+	}
+	if found < 0 {
+		return err
+	}
+
+	return encodingutil.NewPositionError(documents[found].Location, documents[found].Data, position, err)
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// Reads file from local path, URL or STDIN ("-")
+func readLocation(location string) ([]byte, error) {
+	// Check if the passed resource points to STDIN or not
+	if location == "-" {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read from stdin: %s", err)
+		}
+		return data, nil
+	}
+
+	// Check if the passed resource is a URL or not
+	if isURL(location) {
+		// TODO: add test for validating this against an actual resource on the web
+		data, err := pkgutil.GetURLData(location, retryAttempts)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while fetching data from the url %v: %v", location, err)
+		}
+		return data, nil
+	}
+
+	data, err := ioutil.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file '%s': %s", location, err)
+	}
+	return data, nil
+}
+
+// Resolves location of the include relative to the location of the including file
+func resolveInclude(parent string, include object.Include) (string, error) {
+	if include.IsURL {
+		return include.Location, nil
+	}
+
+	if isURL(parent) {
+		if filepath.IsAbs(include.Location) {
+			return "", fmt.Errorf("remote file can't include local file '%s'", include.Location)
+		}
+
+		base, err := url.Parse(parent)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(filepath.ToSlash(include.Location))
+		if err != nil {
+			return "", fmt.Errorf("invalid include path '%s': %s", include.Location, err)
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+
+	if filepath.IsAbs(include.Location) {
+		return include.Location, nil
+	}
+
+	// includes from STDIN are relative to the working directory
+	dir := "."
+	if parent != "-" {
+		dir = filepath.Dir(parent)
+	}
+	return filepath.Join(dir, include.Location), nil
+}
+
+type documentLoader struct {
+	// locations of the files being loaded, used for detecting cycles
+	stack []string
+	// files that are already loaded, each file is merged only once
+	loaded map[string]bool
+}
+
+// Loads the file and its includes; returns the documents in the order they are merged,
+// i.e. the includes (recursively) followed by the file itself
+func (l *documentLoader) load(location string, checksum string) ([]document, error) {
+	for i, s := range l.stack {
+		if s == location {
+			cycle := append(append([]string{}, l.stack[i:]...), location)
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if l.loaded[location] {
+		return nil, nil
+	}
+
+	data, err := readLocation(location)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != "" {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, checksum) {
+			return nil, fmt.Errorf("checksum mismatch for '%s': expected sha256 %s, got %s", location, checksum, actual)
+		}
+	}
+
+	// the format is detected from the content, but files with .json extension have to be JSON
+	if strings.EqualFold(filepath.Ext(location), ".json") && !encodingutil.IsJSON(data) {
+		if _, err := encodingutil.ParseJSON(data); err != nil {
+			return nil, fmt.Errorf("file '%s': %s", location, err)
+		}
+		return nil, fmt.Errorf("file '%s': JSON document has to be an object", location)
+	}
+
+	includes, err := encoding.GetIncludes(data)
+	if err != nil {
+		return nil, (&document{Location: location, Data: data}).wrapError(err, "could not read includes")
+	}
+
+	l.stack = append(l.stack, location)
+	var documents []document
+	for _, include := range includes {
+		includeLocation, err := resolveInclude(location, include)
+		if err != nil {
+			return nil, fmt.Errorf("file '%s': %s", location, err)
+		}
+
+		// remote files can change, so they have to be pinned
+		if isURL(includeLocation) && include.Sha256 == "" {
+			return nil, fmt.Errorf("file '%s': remote include '%s' has to have 'sha256' checksum", location, includeLocation)
+		}
+
+		included, err := l.load(includeLocation, include.Sha256)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, included...)
+	}
+	l.stack = l.stack[:len(l.stack)-1]
+
+	if l.loaded == nil {
+		l.loaded = make(map[string]bool)
+	}
+	l.loaded[location] = true
+
+	return append(documents, document{Location: location, Data: data}), nil
+}
+
+// Loads all the files with their includes in the order they are merged
+func loadDocuments(files []string) ([]document, error) {
+	loader := &documentLoader{}
+
+	var documents []document
+	for _, file := range files {
+		if file != "-" && !isURL(file) {
+			file = filepath.Clean(file)
+		}
+
+		loaded, err := loader.load(file, "")
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, loaded...)
+	}
+
+	return documents, nil
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	cmdutil "github.com/redhat-developer/opencompose/pkg/cmd/util"
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/lint"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	lintExample = `
+  # Check the file for practices that cause problems once it is deployed
+  opencompose lint -f hello-nginx.yaml
+
+  # Report the problems in SARIF for code review tools
+  opencompose lint -f hello-nginx.yaml --output sarif > opencompose-lint.sarif`
+)
+
+// Codes the command exits with, the highest severity of the problems decides.
+// Files that can't be linted, e.g. because they are not valid, make it exit with 1.
+const (
+	lintExitCode_Warning = 2
+	lintExitCode_Error   = 3
+)
+
+func NewCmdLint(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "lint",
+		Short:   "Check OpenCompose files for practices that cause problems once they are deployed",
+		Long:    "Check OpenCompose files for practices that cause problems once they are deployed. Unlike validation the rules are not required for the files to be converted, they can be disabled or changed to errors by a config file and problems can be ignored by '# opencompose:ignore' comments.",
+		Example: lintExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunLint(v, cmd, out, outerr)
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Parent().PersistentPreRunE != nil {
+				if err := cmd.Parent().PersistentPreRunE(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
+			cmdutil.AddIOFlagsViper(v, cmd)
+			cmdutil.AddLintFlagsViper(v, cmd)
+
+			return nil
+		},
+	}
+
+	cmdutil.AddIOFlags(cmd)
+	cmdutil.AddLintFlags(cmd)
+
+	return cmd
+}
+
+// lintError reports lint.Problem among the other problems
+type lintError struct {
+	lint.Problem
+}
+
+func (e lintError) Error() string {
+	return fmt.Sprintf("%s [%s]", e.Message, e.Rule)
+}
+
+func (e lintError) Code() pkgutil.ErrorCode {
+	return e.Problem.Code
+}
+
+// Loads the config given by --config, or the default one if it exists
+func getLintConfig(v *viper.Viper) (*lint.Config, error) {
+	file := v.GetString(cmdutil.Flag_Config_Key)
+	if file == "" {
+		if _, err := os.Stat(cmdutil.LintConfigFile); err != nil {
+			return nil, nil
+		}
+		file = cmdutil.LintConfigFile
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read lint config '%s': %s", file, err)
+	}
+
+	config, err := lint.LoadConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("file '%s': %s", file, err)
+	}
+	return config, nil
+}
+
+func RunLint(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error {
+	output := v.GetString(cmdutil.Flag_Output_Key)
+	switch output {
+	case cmdutil.ValidateOutput_Text, cmdutil.ValidateOutput_JSON, cmdutil.ValidateOutput_SARIF:
+	default:
+		return cmdutil.UsageError(cmd, "unknown output format '%s'", output)
+	}
+
+	config, err := getLintConfig(v)
+	if err != nil {
+		return err
+	}
+
+	o, documents, err := getObject(v, cmd, out, outerr)
+	if err != nil {
+		return err
+	}
+
+	if err := o.ResolveProfiles(cmdutil.GetStringSlice(v, cmdutil.Flag_Profile_Key)); err != nil {
+		return err
+	}
+
+	// the rules expect valid object
+	if err := o.Validate(); err != nil {
+		return locateObjectError(err, documents)
+	}
+
+	var ignores []lint.Ignore
+	for _, d := range documents {
+		ignores = append(ignores, lint.IgnoreComments(d.Data)...)
+	}
+	services, templates := getExtends(documents)
+	ignores = append(ignores, lint.TemplateIgnores(ignores, services, templates)...)
+
+	var problems []lint.Problem
+	for _, p := range lint.Lint(o, config) {
+		if !isIgnored(p, ignores) {
+			problems = append(problems, p)
+		}
+	}
+
+	var errs []error
+	for _, p := range problems {
+		errs = append(errs, locatePathError(lintError{p}, p.Path, documents))
+	}
+	sort.Stable(byPosition(errs))
+
+	switch output {
+	case cmdutil.ValidateOutput_Text:
+		for _, err := range errs {
+			fmt.Fprintf(out, "%s: %s\n", strings.ToUpper(NewDiagnostic(err).Severity), err)
+		}
+	default:
+		var diagnostics []Diagnostic
+		for _, err := range errs {
+			diagnostics = append(diagnostics, NewDiagnostic(err))
+		}
+
+		if output == cmdutil.ValidateOutput_SARIF {
+			err = WriteSARIF(out, diagnostics)
+		} else {
+			err = WriteDiagnosticsJSON(out, diagnostics)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// the problems are already printed, only the exit code is left
+	switch lint.MaxSeverity(problems) {
+	case lint.Severity_Error:
+		return pkgutil.ExitError{Code: lintExitCode_Error}
+	case lint.Severity_Warning:
+		return pkgutil.ExitError{Code: lintExitCode_Warning}
+	}
+	return nil
+}
+
+// Returns the templates the services and the templates extend by their names. They are read
+// from the documents as the decoded object has the templates resolved, later documents override
+// the earlier ones the same way as when they are merged.
+func getExtends(documents []document) (map[string]string, map[string]string) {
+	services := make(map[string]string)
+	templates := make(map[string]string)
+	for _, d := range documents {
+		var st struct {
+			Services []struct {
+				Name    string `yaml:"name"`
+				Extends string `yaml:"extends"`
+			} `yaml:"services"`
+			Templates map[string]struct {
+				Extends string `yaml:"extends"`
+			} `yaml:"templates"`
+		}
+		// the documents were decoded already, the ones that are not valid are skipped
+		if err := encodingutil.Unmarshal(d.Data, &st); err != nil {
+			continue
+		}
+
+		for _, s := range st.Services {
+			if s.Extends != "" {
+				services[s.Name] = s.Extends
+			}
+		}
+		for name, t := range st.Templates {
+			if t.Extends != "" {
+				templates[name] = t.Extends
+			}
+		}
+	}
+	return services, templates
+}
+
+// Reports whether any of the ignore comments suppresses the problem
+func isIgnored(p lint.Problem, ignores []lint.Ignore) bool {
+	for _, ignore := range ignores {
+		if ignore.Matches(p) {
+			return true
+		}
+	}
+	return false
+}
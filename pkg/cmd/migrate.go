@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	cmdutil "github.com/redhat-developer/opencompose/pkg/cmd/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	migrateExample = `
+  # Rewrite OpenCompose files written for an older spec version to the current one
+  opencompose migrate -f opencompose.yaml
+
+  # Check that no file needs to be migrated, e.g. in CI
+  opencompose migrate --check -f opencompose.yaml`
+)
+
+func NewCmdMigrate(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "migrate",
+		Short:   "Rewrite OpenCompose files to the current spec version",
+		Long:    "Rewrite OpenCompose files written for an older spec version to the current one and print what was changed. Migrated files are written in the canonical format (see 'opencompose fmt').",
+		Example: migrateExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunMigrate(v, cmd, out, outerr)
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Parent().PersistentPreRunE != nil {
+				if err := cmd.Parent().PersistentPreRunE(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
+			cmdutil.AddMigrateFlagsViper(v, cmd)
+
+			return nil
+		},
+	}
+
+	cmdutil.AddMigrateFlags(cmd)
+
+	return cmd
+}
+
+// Returns data converted to the current spec version in the canonical format together with
+// the version it was converted from and the changes, data is nil when it's already current.
+// Comments, extension fields and anchors are kept.
+func migrateFile(data []byte) ([]byte, string, []string, error) {
+	if encodingutil.IsJSON(data) {
+		return nil, "", nil, fmt.Errorf("%s", "only YAML files can be migrated")
+	}
+
+	protected, restore := protectReferences(data)
+
+	migrated, from, changes, err := encoding.Migrate(protected)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(changes) == 0 {
+		return nil, from, nil, nil
+	}
+
+	encoder, err := encoding.GetEncoderFor(encoding.CurrentVersion)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	formatted, err := encoder.Format(migrated)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for i := range changes {
+		changes[i] = string(restore([]byte(changes[i])))
+	}
+
+	return restore(formatted), from, changes, nil
+}
+
+func RunMigrate(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error {
+	files := cmdutil.GetStringSlice(v, cmdutil.Flag_File_Key)
+	if len(files) < 1 {
+		return cmdutil.UsageError(cmd, "there has to be at least one file")
+	}
+	check := v.GetBool(cmdutil.Flag_Check_Key)
+
+	var outdated []string
+	for _, file := range files {
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(file)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read file '%s': %s", file, err)
+		}
+
+		migrated, from, changes, err := migrateFile(data)
+		if err != nil {
+			return fmt.Errorf("could not migrate file '%s': %s", file, err)
+		}
+
+		// migrated STDIN goes to the output so the changes are reported on the error output
+		report := out
+		if file == "-" {
+			report = outerr
+		}
+
+		if migrated == nil {
+			if file == "-" && !check {
+				if _, err := out.Write(data); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(report, "%s: already at version %q\n", file, from)
+			continue
+		}
+
+		if warning := encoding.MigrationWarning(data, from); warning != "" {
+			fmt.Fprintf(outerr, "WARNING: file '%s': %s\n", file, warning)
+		}
+		fmt.Fprintf(report, "%s: migrated from version %q to %q\n", file, from, encoding.CurrentVersion)
+		for _, change := range changes {
+			fmt.Fprintf(report, "  %s\n", change)
+		}
+
+		if check {
+			outdated = append(outdated, file)
+			continue
+		}
+
+		if file == "-" {
+			if _, err := out.Write(migrated); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := ioutil.WriteFile(file, migrated, 0644); err != nil {
+			return fmt.Errorf("failed to write file '%s': %s", file, err)
+		}
+	}
+
+	if len(outdated) > 0 {
+		return fmt.Errorf("%d file(s) need to be migrated, run 'opencompose migrate' on them", len(outdated))
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	cmdutil "github.com/redhat-developer/opencompose/pkg/cmd/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	schemaExample = `
+  # Print JSON Schema of the current spec version
+  opencompose schema > opencompose.schema.json
+
+  # Print JSON Schema of a specific spec version
+  opencompose schema --version 0.1-dev`
+)
+
+func NewCmdSchema(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "schema",
+		Short:   "Print JSON Schema of the OpenCompose format",
+		Long:    "Print JSON Schema of the OpenCompose format. Editors (e.g. VS Code with the YAML extension) can use it for completion and validation of OpenCompose files.",
+		Example: schemaExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSchema(v, cmd, out, outerr)
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Parent().PersistentPreRunE != nil {
+				if err := cmd.Parent().PersistentPreRunE(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
+			cmdutil.AddSchemaFlagsViper(v, cmd)
+
+			return nil
+		},
+	}
+
+	cmdutil.AddSchemaFlags(cmd)
+
+	return cmd
+}
+
+func RunSchema(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error {
+	schema, err := encoding.GetSchemaFor(v.GetString(cmdutil.Flag_Version_Key))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %s", err)
+	}
+
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
@@ -1,8 +1,11 @@
 package util
 
 import (
+	"encoding/csv"
 	"fmt"
+	"strings"
 
+	"github.com/redhat-developer/opencompose/pkg/encoding"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -12,6 +15,37 @@ const (
 	Flag_File_Key      = "file"
 	Flag_OutputDir_Key = "output-dir"
 	Flag_Distro_Key    = "distro"
+	Flag_Profile_Key   = "profile"
+	Flag_EnvFile_Key   = "env-file"
+	Flag_Set_Key       = "set"
+
+	Flag_DeploymentConfig_Key = "deployment-config"
+	Flag_OutputFormat_Key     = "output-format"
+	Flag_TemplateName_Key     = "template-name"
+
+	Flag_Check_Key = "check"
+
+	Flag_FailFast_Key = "fail-fast"
+	Flag_Output_Key   = "output"
+	Flag_Policy_Key   = "policy"
+
+	Flag_Version_Key = "version"
+
+	Flag_Config_Key = "config"
+)
+
+const (
+	OutputFormat_Objects           = "objects"
+	OutputFormat_OpenShiftTemplate = "openshift-template"
+)
+
+// Lint config used when --config is not given
+const LintConfigFile = ".opencompose-lint.yaml"
+
+const (
+	ValidateOutput_Text  = "text"
+	ValidateOutput_JSON  = "json"
+	ValidateOutput_SARIF = "sarif"
 )
 
 func UsageError(cmd *cobra.Command, format string, args ...interface{}) error {
@@ -37,14 +71,133 @@ func BindViper(v *viper.Viper, fs *flag.FlagSet, name string) {
 	BindViperNames(v, fs, name, name)
 }
 
+// Viper returns values of string slice flags joined by commas into a single string
+// (https://github.com/spf13/viper/issues/200) so they have to be split again.
+func GetStringSlice(v *viper.Viper, key string) []string {
+	if s, ok := v.Get(key).(string); ok {
+		var values []string
+		for _, value := range strings.Split(s, ",") {
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	return v.GetStringSlice(key)
+}
+
+// Viper returns values of string array flags the way pflag prints them, i.e. as CSV in brackets.
+func GetStringArray(v *viper.Viper, key string) ([]string, error) {
+	s, ok := v.Get(key).(string)
+	if !ok {
+		return v.GetStringSlice(key), nil
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if s == "" {
+		return nil, nil
+	}
+
+	values, err := csv.NewReader(strings.NewReader(s)).Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values of %q: %s", key, err)
+	}
+	return values, nil
+}
+
 func AddIOFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringSliceP(Flag_File_Key, "f", []string{}, "Specify alternative OpenCompose file(s)")
 	cmd.PersistentFlags().StringP(Flag_OutputDir_Key, "o", "", "Specify output directory for genrated Kubernetes (and OpenShift) definitions")
 	cmd.PersistentFlags().StringP(Flag_Distro_Key, "d", "kubernetes", "Choose a target distribution")
+	cmd.PersistentFlags().StringSlice(Flag_Profile_Key, []string{}, "Apply profile(s) defined in the OpenCompose file, in the given order")
+	cmd.PersistentFlags().StringSlice(Flag_EnvFile_Key, []string{}, "Read variables for ${VAR} interpolation from .env file(s)")
+	cmd.PersistentFlags().StringArray(Flag_Set_Key, []string{}, "Set variable for ${VAR} interpolation (KEY=VALUE), overrides environment and --env-file")
 }
 
 func AddIOFlagsViper(v *viper.Viper, cmd *cobra.Command) {
 	BindViper(v, cmd.PersistentFlags(), Flag_File_Key)
 	BindViper(v, cmd.PersistentFlags(), Flag_OutputDir_Key)
 	BindViper(v, cmd.PersistentFlags(), Flag_Distro_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_Profile_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_EnvFile_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_Set_Key)
+}
+
+func AddConvertFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool(Flag_DeploymentConfig_Key, false, "Generate DeploymentConfigs and ImageStreams instead of Deployments (requires --distro openshift)")
+	cmd.PersistentFlags().String(Flag_OutputFormat_Key, OutputFormat_Objects, fmt.Sprintf("Choose an output format: %q or %q (requires --distro openshift)", OutputFormat_Objects, OutputFormat_OpenShiftTemplate))
+	cmd.PersistentFlags().String(Flag_TemplateName_Key, "", "Name of the generated OpenShift Template (defaults to the name of the first file)")
+}
+
+func AddConvertFlagsViper(v *viper.Viper, cmd *cobra.Command) {
+	BindViper(v, cmd.PersistentFlags(), Flag_DeploymentConfig_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_OutputFormat_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_TemplateName_Key)
+}
+
+func AddFmtFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceP(Flag_File_Key, "f", []string{}, "Specify OpenCompose file(s) to format")
+	cmd.PersistentFlags().Bool(Flag_Check_Key, false, "Don't rewrite the files, fail if any of them isn't formatted")
+}
+
+func AddFmtFlagsViper(v *viper.Viper, cmd *cobra.Command) {
+	BindViper(v, cmd.PersistentFlags(), Flag_File_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_Check_Key)
+}
+
+func AddMigrateFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceP(Flag_File_Key, "f", []string{}, "Specify OpenCompose file(s) to migrate")
+	cmd.PersistentFlags().Bool(Flag_Check_Key, false, "Don't rewrite the files, fail if any of them needs to be migrated")
+}
+
+func AddMigrateFlagsViper(v *viper.Viper, cmd *cobra.Command) {
+	BindViper(v, cmd.PersistentFlags(), Flag_File_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_Check_Key)
+}
+
+func AddSchemaFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(Flag_Version_Key, encoding.CurrentVersion, "Spec version to print the schema of")
+}
+
+func AddSchemaFlagsViper(v *viper.Viper, cmd *cobra.Command) {
+	BindViper(v, cmd.PersistentFlags(), Flag_Version_Key)
+}
+
+func AddExplainFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(Flag_Version_Key, encoding.CurrentVersion, "Spec version to describe")
+}
+
+func AddExplainFlagsViper(v *viper.Viper, cmd *cobra.Command) {
+	BindViper(v, cmd.PersistentFlags(), Flag_Version_Key)
+}
+
+func AddValidateFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool(Flag_FailFast_Key, false, "Report only the first error instead of all of them")
+	cmd.PersistentFlags().String(Flag_Output_Key, ValidateOutput_Text, fmt.Sprintf("Choose an output format: %q, %q or %q", ValidateOutput_Text, ValidateOutput_JSON, ValidateOutput_SARIF))
+	cmd.PersistentFlags().StringSlice(Flag_Policy_Key, []string{}, "Check the files and the generated objects against the rules of policy file(s)")
+}
+
+func AddValidateFlagsViper(v *viper.Viper, cmd *cobra.Command) {
+	BindViper(v, cmd.PersistentFlags(), Flag_FailFast_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_Output_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_Policy_Key)
+}
+
+func AddImportFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceP(Flag_File_Key, "f", []string{}, "Specify file(s) to import")
+}
+
+func AddImportFlagsViper(v *viper.Viper, cmd *cobra.Command) {
+	BindViper(v, cmd.PersistentFlags(), Flag_File_Key)
+}
+
+func AddLintFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(Flag_Config_Key, "", fmt.Sprintf("Lint config file (defaults to %s in the working directory if it exists)", LintConfigFile))
+	cmd.PersistentFlags().String(Flag_Output_Key, ValidateOutput_Text, fmt.Sprintf("Choose an output format: %q, %q or %q", ValidateOutput_Text, ValidateOutput_JSON, ValidateOutput_SARIF))
+}
+
+func AddLintFlagsViper(v *viper.Viper, cmd *cobra.Command) {
+	BindViper(v, cmd.PersistentFlags(), Flag_Config_Key)
+	BindViper(v, cmd.PersistentFlags(), Flag_Output_Key)
 }
@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
+	"strings"
 
 	cmdutil "github.com/redhat-developer/opencompose/pkg/cmd/util"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	"github.com/redhat-developer/opencompose/pkg/policy"
+	"github.com/redhat-developer/opencompose/pkg/transform/openshift"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -32,17 +39,287 @@ func NewCmdValidate(v *viper.Viper, out, outerr io.Writer) *cobra.Command {
 
 			// We have to bind Viper in Run because there is only one instance to avoid collisions between subcommands
 			cmdutil.AddIOFlagsViper(v, cmd)
+			cmdutil.AddValidateFlagsViper(v, cmd)
 
 			return nil
 		},
 	}
 
 	cmdutil.AddIOFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
 
 	return cmd
 }
 
+// profileError is an error that is found only with the profile applied
+type profileError struct {
+	Profile string
+	Err     error
+}
+
+func (e profileError) Error() string {
+	return fmt.Sprintf("profile %q: %s", e.Profile, e.Err)
+}
+
+func (e profileError) Code() pkgutil.ErrorCode {
+	return pkgutil.CodeOf(e.Err)
+}
+
+// sccViolationError reports SCCViolation among the other problems
+type sccViolationError struct {
+	openshift.SCCViolation
+}
+
+func (e sccViolationError) Error() string {
+	return fmt.Sprintf("%s %q: %s: %s", e.Kind, e.Name, e.Path, e.Message)
+}
+
+func (e sccViolationError) Code() pkgutil.ErrorCode {
+	return openshift.ErrorCode_RestrictedSCC
+}
+
+// warningError is a problem that doesn't make the validation fail
+type warningError struct {
+	Err error
+}
+
+func (e warningError) Error() string {
+	return e.Err.Error()
+}
+
+func (e warningError) Code() pkgutil.ErrorCode {
+	return pkgutil.CodeOf(e.Err)
+}
+
+// Reports whether err is a warning, possibly found with a profile
+func isWarning(err error) bool {
+	if pe, ok := err.(profileError); ok {
+		err = pe.Err
+	}
+	_, ok := err.(warningError)
+	return ok
+}
+
+// Returns warnings as warningErrors
+func asWarnings(warnings error) pkgutil.ErrorList {
+	var errs pkgutil.ErrorList
+	for _, w := range pkgutil.Errors(warnings) {
+		errs = append(errs, warningError{w})
+	}
+	return errs
+}
+
 func RunValidate(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer) error {
-	_, err := GetValidatedObject(v, cmd, out, outerr)
-	return err
+	output := v.GetString(cmdutil.Flag_Output_Key)
+	switch output {
+	case cmdutil.ValidateOutput_Text, cmdutil.ValidateOutput_JSON, cmdutil.ValidateOutput_SARIF:
+	default:
+		return cmdutil.UsageError(cmd, "unknown output format '%s'", output)
+	}
+
+	// with structured output the violations of the generated objects are reported
+	// as diagnostics too instead of being printed
+	errs := pkgutil.Errors(validate(v, cmd, out, outerr, output != cmdutil.ValidateOutput_Text))
+
+	if output == cmdutil.ValidateOutput_Text {
+		var failures pkgutil.ErrorList
+		for _, err := range errs {
+			if isWarning(err) {
+				fmt.Fprintf(outerr, "WARNING: %s\n", err)
+				continue
+			}
+			failures = append(failures, err)
+		}
+		errs = failures
+	}
+
+	// all the errors are reported by default so they can be fixed at once
+	if len(errs) > 1 && v.GetBool(cmdutil.Flag_FailFast_Key) {
+		errs = errs[:1]
+	}
+
+	if output == cmdutil.ValidateOutput_Text {
+		return pkgutil.ErrorList(errs).Err()
+	}
+
+	var diagnostics []Diagnostic
+	for _, err := range errs {
+		diagnostics = append(diagnostics, NewDiagnostic(err))
+	}
+
+	var err error
+	if output == cmdutil.ValidateOutput_SARIF {
+		err = WriteSARIF(out, diagnostics)
+	} else {
+		err = WriteDiagnosticsJSON(out, diagnostics)
+	}
+	if err != nil {
+		return err
+	}
+
+	errors := 0
+	for _, d := range diagnostics {
+		if d.Severity == Severity_Error {
+			errors++
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("%d error(s) found", errors)
+	}
+
+	return nil
+}
+
+func validate(v *viper.Viper, cmd *cobra.Command, out, outerr io.Writer, collectViolations bool) error {
+	policies, err := getPolicies(v)
+	if err != nil {
+		return err
+	}
+
+	// with explicitly selected profiles only the result of applying them is validated
+	if len(cmdutil.GetStringSlice(v, cmdutil.Flag_Profile_Key)) > 0 {
+		o, documents, warnings, err := getValidatedObject(v, cmd, out, outerr)
+		if err != nil {
+			return err
+		}
+
+		errs := asWarnings(warnings)
+		errs = errs.Append(checkValidatedObject(v, cmd, o, outerr, collectViolations))
+		errs = errs.Append(checkPolicies(v, cmd, o, policies, documents))
+		return errs.Err()
+	}
+
+	o, documents, err := getObject(v, cmd, out, outerr)
+	if err != nil {
+		return err
+	}
+
+	// otherwise the file is validated without any profile and then with each of the profiles
+	// because they can break it in different ways (e.g. by disabling a service)
+	var errs pkgutil.ErrorList
+	reported := make(map[string]bool)
+	variants := []string{""}
+	variants = append(variants, o.ProfileNames()...)
+	for _, profile := range variants {
+		resolved := o.DeepCopy()
+
+		var profiles []string
+		if profile != "" {
+			profiles = []string{profile}
+		}
+
+		if err := resolved.ResolveProfiles(profiles); err != nil {
+			return err
+		}
+
+		err := locateObjectError(resolved.Validate(), documents)
+		if err == nil {
+			err = pkgutil.ErrorList{}.
+				Append(checkValidatedObject(v, cmd, resolved, outerr, collectViolations)).
+				Append(checkPolicies(v, cmd, resolved, policies, documents)).
+				Err()
+		}
+
+		problems := asWarnings(locateObjectError(resolved.Warnings(), documents))
+		problems = problems.Append(err)
+		for _, e := range problems {
+			// the errors the profiles don't change are reported just once
+			if reported[e.Error()] {
+				continue
+			}
+			reported[e.Error()] = true
+
+			if profile != "" {
+				e = profileError{Profile: profile, Err: e}
+			}
+			errs = append(errs, e)
+		}
+	}
+
+	return errs.Err()
+}
+
+// Checks the objects generated for OpenShift, with collectViolations all the violations
+// are returned as errors instead of being printed to outerr
+func checkValidatedObject(v *viper.Viper, cmd *cobra.Command, o *object.OpenCompose, outerr io.Writer, collectViolations bool) error {
+	// generated objects are checked only for OpenShift, Kubernetes has no default restrictions
+	if strings.ToLower(v.GetString(cmdutil.Flag_Distro_Key)) != "openshift" {
+		return nil
+	}
+
+	transformer, err := GetTransformer(v, cmd)
+	if err != nil {
+		return err
+	}
+
+	runtimeObjects, err := transformer.Transform(o)
+	if err != nil {
+		return fmt.Errorf("transformation failed: %s", err)
+	}
+
+	if !collectViolations {
+		return CheckObjects(v, runtimeObjects, outerr)
+	}
+
+	var errs pkgutil.ErrorList
+	for _, violation := range sccViolations(v, runtimeObjects) {
+		errs = append(errs, sccViolationError{violation})
+	}
+	return errs.Err()
+}
+
+// Loads the policies given by --policy
+func getPolicies(v *viper.Viper) ([]*policy.Policy, error) {
+	var policies []*policy.Policy
+	for _, file := range cmdutil.GetStringSlice(v, cmdutil.Flag_Policy_Key) {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read policy '%s': %s", file, err)
+		}
+
+		p, err := policy.Load(data)
+		if err != nil {
+			return nil, (&document{Location: file, Data: data}).wrapError(err, "invalid policy")
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// Evaluates the policies against the validated object and the objects generated from it,
+// violations of the rules with severity "warning" are returned as warningErrors
+func checkPolicies(v *viper.Viper, cmd *cobra.Command, o *object.OpenCompose, policies []*policy.Policy, documents []document) error {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	transformer, err := GetTransformer(v, cmd)
+	if err != nil {
+		return err
+	}
+
+	runtimeObjects, err := transformer.Transform(o)
+	if err != nil {
+		return fmt.Errorf("transformation failed: %s", err)
+	}
+
+	var errs pkgutil.ErrorList
+	for _, p := range policies {
+		violations, err := p.Evaluate(o, runtimeObjects)
+		if err != nil {
+			return err
+		}
+
+		for _, violation := range violations {
+			var e error = violation
+			if violation.Target == policy.Target_OpenCompose {
+				e = locatePathError(violation, violation.Path, documents)
+			}
+			if violation.Severity == policy.Severity_Warning {
+				e = warningError{e}
+			}
+			errs = append(errs, e)
+		}
+	}
+	return errs.Err()
 }
@@ -9,6 +9,8 @@ import (
 
 type Decoder interface {
 	Decode([]byte) (*object.OpenCompose, error)
+	// Decodes file that is merged over other files, required fields may be left out
+	DecodeOverride([]byte) (*object.OpenCompose, error)
 }
 
 func GetDecoderFor(data []byte) (Decoder, error) {
@@ -20,7 +22,25 @@ func GetDecoderFor(data []byte) (Decoder, error) {
 	switch version {
 	case v1.Version:
 		return &v1.Decoder{}, nil
+	case Version_0_1_0:
+		return nil, fmt.Errorf("files of version %q have to be migrated before they are decoded", version)
 	default:
 		return nil, fmt.Errorf("unsupported version %q", version)
 	}
 }
+
+// Returns data converted to the current spec version so it can be decoded together with warnings
+// about the conversion, the files should be migrated permanently by 'opencompose migrate'.
+// Data that can't be converted is returned as it is so the decoder reports the errors.
+func MigrateForDecoding(data []byte) ([]byte, []string) {
+	migrated, from, changes, err := Migrate(data)
+	if err != nil || len(changes) == 0 {
+		return data, nil
+	}
+
+	warning := MigrationWarning(data, from)
+	if warning == "" {
+		warning = fmt.Sprintf("the file is written for version %q and is migrated to %q; run 'opencompose migrate' on the file", from, CurrentVersion)
+	}
+	return migrated, []string{warning}
+}
@@ -0,0 +1,24 @@
+package encoding
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/v1"
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+type Encoder interface {
+	Encode(*object.OpenCompose) ([]byte, error)
+	// Rewrites file of the version in the canonical format keeping its comments,
+	// extension fields and anchors
+	Format(data []byte) ([]byte, error)
+}
+
+func GetEncoderFor(version string) (Encoder, error) {
+	switch version {
+	case v1.Version:
+		return &v1.Encoder{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported version %q", version)
+	}
+}
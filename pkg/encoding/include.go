@@ -0,0 +1,34 @@
+package encoding
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding/v1"
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+// Reads includes of OpenCompose file.
+// Includes have to be known before the file is decoded because the included files
+// are merged first.
+func GetIncludes(data []byte) ([]object.Include, error) {
+	version, err := GetVersion(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch version {
+	// fields of v0.1.0 documents don't change after they are migrated
+	case v1.Version, Version_0_1_0:
+		var st struct {
+			Include []v1.Include `yaml:"include,omitempty"`
+		}
+		if err := util.Unmarshal(data, &st); err != nil {
+			return nil, util.Wrapf(err, "failed to unmarshal OpenCompose include")
+		}
+
+		return v1.ConvertIncludes(st.Include), nil
+	default:
+		return nil, fmt.Errorf("unsupported version %q", version)
+	}
+}
@@ -0,0 +1,103 @@
+package encoding
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+func TestGetIncludes(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Succeed  bool
+		File     string
+		Includes []object.Include
+	}{
+		{
+			"No includes",
+			true, `
+version: 0.1-dev
+services: []
+`,
+			nil,
+		},
+		{
+			"Short and long forms",
+			true, `
+version: 0.1-dev
+include:
+- ../shared/redis.yaml
+- https://example.com/postgres.yaml
+- path: local.yaml
+- url: https://example.com/other.yaml
+  sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+`,
+			[]object.Include{
+				{Location: "../shared/redis.yaml"},
+				{Location: "https://example.com/postgres.yaml", IsURL: true},
+				{Location: "local.yaml"},
+				{Location: "https://example.com/other.yaml", IsURL: true, Sha256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+			},
+		},
+		{
+			"Both path and url",
+			false, `
+version: 0.1-dev
+include:
+- path: local.yaml
+  url: https://example.com/other.yaml
+`,
+			nil,
+		},
+		{
+			"Neither path nor url",
+			false, `
+version: 0.1-dev
+include:
+- sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+`,
+			nil,
+		},
+		{
+			"Unsupported url scheme",
+			false, `
+version: 0.1-dev
+include:
+- url: ftp://example.com/other.yaml
+`,
+			nil,
+		},
+		{
+			"Excess key in include",
+			false, `
+version: 0.1-dev
+include:
+- path: local.yaml
+  checksum: abc
+`,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			includes, err := GetIncludes([]byte(test.File))
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to get includes from %q: %s", test.File, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, file: %q, includes: %#v", test.File, includes)
+				return
+			}
+
+			if !reflect.DeepEqual(includes, test.Includes) {
+				t.Errorf("Expected: %#v\nGot: %#v", test.Includes, includes)
+			}
+		})
+	}
+}
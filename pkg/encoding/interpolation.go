@@ -0,0 +1,193 @@
+package encoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Matches variable references ${VAR}, ${VAR:-default} and ${VAR:?error} and the $$ escape.
+// Typed parameter references ${{NAME}} don't match because of the second brace.
+var variableReferenceRegexp = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:-|:\?)([^}]*))?\}`)
+
+// Matches JSON strings, the colon that follows keys is matched as well to tell them apart from values
+var jsonStringRegexp = regexp.MustCompile(`"(?:[^"\\]|\\.)*"(\s*:)?`)
+
+// Replaces variable references in values of data by values from variables:
+//   - ${VAR} is replaced by the value of VAR, unset VAR is replaced by an empty string and reported as a warning
+//   - ${VAR:-default} is replaced by default when VAR is unset or empty
+//   - ${VAR:?error} fails with error when VAR is unset or empty
+//
+// Only the decoded values are interpolated, keys and comments are left as they are and the values
+// stay values whatever characters they get. Unquoted YAML values are typed after the interpolation,
+// so "replicas: ${REPLICAS:-1}" is a number.
+//
+// $$ is an escaped $, it's left for SubstituteParameters which unescapes it after the parameters
+// are substituted. References to declared parameters in the ${NAME} form are left for SubstituteParameters too.
+// Data that doesn't parse is returned as it is for the decoder to report.
+func InterpolateVariables(data []byte, variables map[string]string, parameters []object.Parameter) ([]byte, []string, error) {
+	in := interpolator{
+		variables: variables,
+		declared:  make(map[string]bool),
+	}
+	for _, p := range parameters {
+		in.declared[p.Name] = true
+	}
+
+	if util.IsJSON(data) {
+		result, err := in.interpolateJSON(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return result, in.warnings, nil
+	}
+
+	var document yaml3.Node
+	if err := yaml3.Unmarshal(data, &document); err != nil {
+		return data, nil, nil
+	}
+
+	changed, err := in.interpolateNode(&document)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !changed {
+		return data, in.warnings, nil
+	}
+
+	result, err := util.MarshalNode(&document)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, in.warnings, nil
+}
+
+type interpolator struct {
+	variables map[string]string
+	declared  map[string]bool
+	warnings  []string
+}
+
+// Returns s with the variable references replaced
+func (in *interpolator) interpolate(s string) (string, error) {
+	var err error
+	result := variableReferenceRegexp.ReplaceAllStringFunc(s, func(reference string) string {
+		if err != nil || reference == "$$" {
+			return reference
+		}
+
+		match := variableReferenceRegexp.FindStringSubmatch(reference)
+		name := match[1]
+		operator := match[2]
+		argument := match[3]
+
+		value, set := in.variables[name]
+		switch operator {
+		case "":
+			if in.declared[name] {
+				return reference
+			}
+			if !set {
+				in.warnings = append(in.warnings, fmt.Sprintf("variable %q is not set, substituting an empty string", name))
+			}
+		case ":-":
+			if value == "" {
+				value = argument
+			}
+		case ":?":
+			if value == "" {
+				if argument == "" {
+					argument = "is not set"
+				}
+				err = fmt.Errorf("variable %q: %s", name, argument)
+				return reference
+			}
+		}
+
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// Interpolates the scalar values of node, reports whether any of them changed.
+// Aliases are interpolated where their anchors are.
+func (in *interpolator) interpolateNode(node *yaml3.Node) (bool, error) {
+	changed := false
+	switch node.Kind {
+	case yaml3.DocumentNode, yaml3.SequenceNode:
+		for _, n := range node.Content {
+			c, err := in.interpolateNode(n)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+	case yaml3.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			c, err := in.interpolateNode(node.Content[i])
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+	case yaml3.ScalarNode:
+		value, err := in.interpolate(node.Value)
+		if err != nil {
+			return false, err
+		}
+		if value == node.Value {
+			return false, nil
+		}
+
+		node.Value = value
+		// unquoted values are typed by what they contain, the encoder quotes them when needed
+		if node.Style&(yaml3.SingleQuotedStyle|yaml3.DoubleQuotedStyle|yaml3.LiteralStyle|yaml3.FoldedStyle|yaml3.TaggedStyle) == 0 {
+			node.Tag = ""
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// Interpolates the string values of JSON document data, the rest of the document is kept as it is
+func (in *interpolator) interpolateJSON(data []byte) ([]byte, error) {
+	var err error
+	result := jsonStringRegexp.ReplaceAllFunc(data, func(literal []byte) []byte {
+		if err != nil {
+			return literal
+		}
+		if match := jsonStringRegexp.FindSubmatch(literal); len(match[1]) > 0 {
+			return literal
+		}
+
+		var s string
+		if e := json.Unmarshal(literal, &s); e != nil {
+			// invalid JSON is reported by the decoder
+			return literal
+		}
+
+		var value string
+		value, err = in.interpolate(s)
+		if err != nil || value == s {
+			return literal
+		}
+
+		encoded, e := json.Marshal(value)
+		if e != nil {
+			err = e
+			return literal
+		}
+		return encoded
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
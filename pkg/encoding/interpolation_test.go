@@ -0,0 +1,156 @@
+package encoding
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+func TestInterpolateVariables(t *testing.T) {
+	variables := map[string]string{
+		"TAG":     "v1.2",
+		"HOST":    "ci-42.example.com",
+		"EMPTY":   "",
+		"COLON":   "key: value",
+		"COMMENT": " # not a comment",
+		"QUOTES":  `'single' "double"`,
+		"LINES":   "first\nsecond",
+	}
+	parameters := []object.Parameter{
+		{Name: "REPLICAS"},
+		{Name: "DOMAIN"},
+	}
+
+	tests := []struct {
+		Name     string
+		Succeed  bool
+		Data     string
+		Result   string
+		Warnings []string
+	}{
+		{
+			"Set variables",
+			true,
+			"image: nginx:${TAG}\nhost: ${HOST}\n",
+			"image: nginx:v1.2\nhost: ci-42.example.com\n",
+			nil,
+		},
+		{
+			"Unset variable",
+			true,
+			"image: nginx:${UNSET}\n",
+			"image: 'nginx:'\n",
+			[]string{`variable "UNSET" is not set, substituting an empty string`},
+		},
+		{
+			"Default values",
+			true,
+			"value: ${TAG:-latest} ${UNSET:-latest} ${EMPTY:-latest} ${UNSET:-}\n",
+			"value: 'v1.2 latest latest '\n",
+			nil,
+		},
+		{
+			"Required variable is set",
+			true,
+			"image: nginx:${TAG:?tag has to be set}\n",
+			"image: nginx:v1.2\n",
+			nil,
+		},
+		{
+			"Required variable is not set",
+			false,
+			"image: nginx:${UNSET:?tag has to be set}\n",
+			"",
+			nil,
+		},
+		{
+			"Required variable is empty",
+			false,
+			"image: nginx:${EMPTY:?}\n",
+			"",
+			nil,
+		},
+		{
+			"Parameter references are kept",
+			true,
+			"replicas: ${{REPLICAS}}\nhost: ${DOMAIN}\nfallback: ${DOMAIN:-example.com}\n",
+			"replicas: ${{REPLICAS}}\nhost: ${DOMAIN}\nfallback: example.com\n",
+			nil,
+		},
+		{
+			"Values with YAML syntax stay values",
+			true,
+			"labels:\n  a: ${COLON}\n  b: x${COMMENT}\n  c: \"${QUOTES}\"\n  d: ${LINES}\n",
+			"labels:\n  a: 'key: value'\n  b: 'x # not a comment'\n  c: \"'single' \\\"double\\\"\"\n  d: |-\n    first\n    second\n",
+			nil,
+		},
+		{
+			"Unquoted values are typed after the interpolation",
+			true,
+			"replicas: ${UNSET:-2}\nname: \"${UNSET:-2}\"\n",
+			"replicas: 2\nname: \"2\"\n",
+			nil,
+		},
+		{
+			"Comments and keys are not interpolated",
+			true,
+			"# ${UNSET:?not used}\nimage: nginx # ${UNSET:?not used}\n${TAG}: x\n",
+			"# ${UNSET:?not used}\nimage: nginx # ${UNSET:?not used}\n${TAG}: x\n",
+			nil,
+		},
+		{
+			"Escaped references are kept for the parameter substitution",
+			true,
+			"command: echo $${UNSET} $$HOME $$${TAG}\n",
+			"command: echo $${UNSET} $$HOME $$v1.2\n",
+			nil,
+		},
+		{
+			"Anchored values are interpolated once",
+			true,
+			"a: &tag ${TAG}\nb: *tag\n",
+			"a: &tag v1.2\nb: *tag\n",
+			nil,
+		},
+		{
+			"JSON",
+			true,
+			`{"image": "nginx:${TAG}", "${TAG}": "${COLON}", "labels": {"a": "${QUOTES}"}}`,
+			`{"image": "nginx:v1.2", "${TAG}": "key: value", "labels": {"a": "'single' \"double\""}}`,
+			nil,
+		},
+		{
+			"Required variable in JSON",
+			false,
+			`{"image": "nginx:${UNSET:?tag has to be set}"}`,
+			"",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			result, warnings, err := InterpolateVariables([]byte(test.Data), variables, parameters)
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to interpolate %q: %s", test.Data, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, data: %q, result: %q", test.Data, result)
+				return
+			}
+
+			if string(result) != test.Result {
+				t.Errorf("Expected: %q\nGot: %q", test.Result, result)
+			}
+
+			if !reflect.DeepEqual(warnings, test.Warnings) {
+				t.Errorf("Expected warnings: %#v\nGot: %#v", test.Warnings, warnings)
+			}
+		})
+	}
+}
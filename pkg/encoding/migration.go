@@ -0,0 +1,303 @@
+package encoding
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding/v1"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Spec version the files are migrated to
+const CurrentVersion = v1.Version
+
+// Files written for OpenCompose v0.1.0 declare version "0.1-dev" as well. They can declare this version
+// to be migrated explicitly, the ones that declare "0.1-dev" are recognized by the fields that changed
+// in v0.2.0 and migrated implicitly (see MigrationWarning).
+const Version_0_1_0 = "0.1.0"
+
+// Migration converts a document of spec version From to the next version To
+type Migration struct {
+	From string
+	To   string
+	// Rewrites the root mapping of the document in place and returns descriptions of the changes
+	Migrate func(document *yaml3.Node) ([]string, error)
+}
+
+// Chain of the migrations from the oldest version to the current one
+var migrations = []Migration{
+	{From: Version_0_1_0, To: CurrentVersion, Migrate: migrate_0_1_0},
+}
+
+// Returns the node an alias refers to, other nodes are returned as they are
+func resolveAlias(node *yaml3.Node) *yaml3.Node {
+	for node != nil && node.Kind == yaml3.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// Returns key and value of mapping m for key, the keys merged into m are looked up
+// after its own keys. Both are nil if m is not a mapping or it doesn't have the key.
+func mappingValue(m *yaml3.Node, key string) (*yaml3.Node, *yaml3.Node) {
+	m = resolveAlias(m)
+	if m == nil || m.Kind != yaml3.MappingNode {
+		return nil, nil
+	}
+
+	var merged []*yaml3.Node
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], resolveAlias(m.Content[i+1])
+		switch {
+		case k.Tag == "!!merge" && v.Kind == yaml3.SequenceNode:
+			merged = append(merged, v.Content...)
+		case k.Tag == "!!merge":
+			merged = append(merged, v)
+		case k.Value == key:
+			return k, v
+		}
+	}
+
+	for _, n := range merged {
+		if k, v := mappingValue(n, key); k != nil {
+			return k, v
+		}
+	}
+	return nil, nil
+}
+
+// Returns items of the list at key in m that are mappings, together with their paths
+// where items are identified by their name if they have one
+func mapItems(m *yaml3.Node, key string, path string) ([]*yaml3.Node, []string) {
+	_, list := mappingValue(m, key)
+	if list == nil || list.Kind != yaml3.SequenceNode {
+		return nil, nil
+	}
+
+	var items []*yaml3.Node
+	var paths []string
+	for i, item := range list.Content {
+		item = resolveAlias(item)
+		if item.Kind != yaml3.MappingNode {
+			continue
+		}
+
+		segment := strconv.Itoa(i)
+		if _, name := mappingValue(item, "name"); name != nil && name.Kind == yaml3.ScalarNode && name.Value != "" {
+			segment = util.EscapePathSegment(name.Value)
+		}
+		items = append(items, item)
+		paths = append(paths, path+"/"+key+"/"+segment)
+	}
+	return items, paths
+}
+
+// Calls f for every container of the document
+func forEachContainer(document *yaml3.Node, f func(container *yaml3.Node, path string) error) error {
+	services, servicePaths := mapItems(document, "services", "")
+	for i, service := range services {
+		containers, containerPaths := mapItems(service, "containers", servicePaths[i])
+		for j, container := range containers {
+			if err := f(container, containerPaths[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns environment variables of the container, nil if it has none
+func containerEnv(container *yaml3.Node) *yaml3.Node {
+	_, env := mappingValue(container, "env")
+	if env == nil || env.Kind != yaml3.SequenceNode {
+		return nil
+	}
+	return env
+}
+
+// Reports whether the node is a string written as KEY=VALUE environment variable of v0.1.0
+func isKeyValueString(node *yaml3.Node) bool {
+	node = resolveAlias(node)
+	return node.Kind == yaml3.ScalarNode && node.ShortTag() == "!!str"
+}
+
+// Reports whether the document uses the format of OpenCompose v0.1.0,
+// i.e. mounts with volumeName or environment variables as KEY=VALUE strings
+func isVersion_0_1_0(document *yaml3.Node) bool {
+	found := false
+	forEachContainer(document, func(container *yaml3.Node, path string) error {
+		mounts, _ := mapItems(container, "mounts", path)
+		for _, mount := range mounts {
+			if k, _ := mappingValue(mount, "volumeName"); k != nil {
+				found = true
+			}
+		}
+
+		if env := containerEnv(container); env != nil {
+			for _, e := range env.Content {
+				if isKeyValueString(e) {
+					found = true
+				}
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// v0.2.0 renamed volumeName of mounts to volumeRef and changed environment variables
+// from KEY=VALUE strings to name and value fields.
+// The nodes are changed in place so comments and anchors stay where they were, nodes shared
+// through anchors are migrated once.
+func migrate_0_1_0(document *yaml3.Node) ([]string, error) {
+	var changes []string
+	migrated := make(map[*yaml3.Node]bool)
+	err := forEachContainer(document, func(container *yaml3.Node, path string) error {
+		mounts, mountPaths := mapItems(container, "mounts", path)
+		for i, mount := range mounts {
+			key, _ := mappingValue(mount, "volumeName")
+			if key == nil || migrated[key] {
+				continue
+			}
+			if k, _ := mappingValue(mount, "volumeRef"); k != nil {
+				return fmt.Errorf("%s: both volumeName and volumeRef are set", mountPaths[i])
+			}
+			key.Value = "volumeRef"
+			migrated[key] = true
+			changes = append(changes, fmt.Sprintf("%s: renamed volumeName to volumeRef", mountPaths[i]))
+		}
+
+		env := containerEnv(container)
+		if env == nil || migrated[env] {
+			return nil
+		}
+		migrated[env] = true
+		for i, e := range env.Content {
+			if !isKeyValueString(e) {
+				continue
+			}
+			s := resolveAlias(e).Value
+
+			key, value, err := pkgutil.ParseKeyValue(s)
+			if err != nil {
+				return fmt.Errorf("%s/env/%d: %s", path, i, err)
+			}
+			env.Content[i] = &yaml3.Node{
+				Kind:        yaml3.MappingNode,
+				Tag:         "!!map",
+				HeadComment: e.HeadComment,
+				FootComment: e.FootComment,
+				Content: []*yaml3.Node{
+					{Kind: yaml3.ScalarNode, Tag: "!!str", Value: "name"},
+					{Kind: yaml3.ScalarNode, Tag: "!!str", Value: key},
+					{Kind: yaml3.ScalarNode, Tag: "!!str", Value: "value"},
+					{Kind: yaml3.ScalarNode, Tag: "!!str", Value: value, LineComment: e.LineComment},
+				},
+			}
+			changes = append(changes, fmt.Sprintf("%s/env/%d: converted %q to name and value", path, i, s))
+		}
+		return nil
+	})
+	return changes, err
+}
+
+// Returns spec version the document declares
+func declaredVersion(document *yaml3.Node) string {
+	if _, v := mappingValue(document, "version"); v != nil && v.Kind == yaml3.ScalarNode && v.ShortTag() != "!!null" {
+		return v.Value
+	}
+	return ""
+}
+
+// Returns spec version of the document: the declared one, except for documents that declare
+// the current version and use the fields of v0.1.0
+func detectVersion(document *yaml3.Node) string {
+	version := declaredVersion(document)
+	if version == CurrentVersion && isVersion_0_1_0(document) {
+		return Version_0_1_0
+	}
+	return version
+}
+
+// Sets version the document declares, the version is added as the first field if it's missing
+func setVersion(document *yaml3.Node, version string) {
+	if _, v := mappingValue(document, "version"); v != nil && v.Kind == yaml3.ScalarNode {
+		v.Value = version
+		v.Tag = "!!str"
+		return
+	}
+
+	document.Content = append([]*yaml3.Node{
+		{Kind: yaml3.ScalarNode, Tag: "!!str", Value: "version"},
+		{Kind: yaml3.ScalarNode, Tag: "!!str", Value: version},
+	}, document.Content...)
+}
+
+// Returns warning about data being migrated from version from although it declares another version,
+// i.e. the version was recognized by the fields the file uses. Empty if the versions are the same.
+func MigrationWarning(data []byte, from string) string {
+	declared, err := GetVersion(data)
+	if err != nil || declared == from {
+		return ""
+	}
+	return fmt.Sprintf("the file declares version %q but uses the fields of version %q and is migrated from it; declare 'version: %s' or run 'opencompose migrate' on the file", declared, from, from)
+}
+
+// Converts OpenCompose document to the current spec version by applying the migrations
+// from its version one by one. Returns the converted document, the version it was converted from
+// and descriptions of the changes. Documents that are already current are returned unchanged.
+// Only the migrated fields are rewritten, comments, anchors and extension fields are kept.
+func Migrate(data []byte) ([]byte, string, []string, error) {
+	var node yaml3.Node
+	if err := yaml3.Unmarshal(data, &node); err != nil {
+		return nil, "", nil, util.Wrapf(err, "failed to unmarshal OpenCompose")
+	}
+
+	var document *yaml3.Node
+	if node.Kind == yaml3.DocumentNode && len(node.Content) == 1 {
+		document = resolveAlias(node.Content[0])
+	}
+	if document == nil || document.Kind != yaml3.MappingNode {
+		return nil, "", nil, fmt.Errorf("%s", "OpenCompose document has to be a mapping")
+	}
+
+	declared := declaredVersion(document)
+	from := detectVersion(document)
+	version := from
+	var changes []string
+	if declared != CurrentVersion {
+		changes = append(changes, fmt.Sprintf("/version: changed %q to %q", declared, CurrentVersion))
+	}
+	for version != CurrentVersion {
+		var migration *Migration
+		for i := range migrations {
+			if migrations[i].From == version {
+				migration = &migrations[i]
+			}
+		}
+		if migration == nil {
+			return nil, from, nil, fmt.Errorf("unsupported version %q", version)
+		}
+
+		migrationChanges, err := migration.Migrate(document)
+		if err != nil {
+			return nil, from, nil, fmt.Errorf("failed to migrate from version %q to %q: %s", migration.From, migration.To, err)
+		}
+		changes = append(changes, migrationChanges...)
+		version = migration.To
+	}
+
+	if from == CurrentVersion {
+		return data, from, nil, nil
+	}
+
+	setVersion(document, version)
+	migrated, err := util.MarshalNode(&node)
+	if err != nil {
+		return nil, from, nil, fmt.Errorf("failed to marshal migrated OpenCompose: %s", err)
+	}
+
+	return migrated, from, changes, nil
+}
@@ -0,0 +1,325 @@
+package encoding
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Succeed  bool
+		File     string
+		From     string
+		Expected string
+		Changes  []string
+	}{
+		{
+			"Current version",
+			true, `
+version: 0.1-dev
+services:
+- name: foo
+  containers:
+  - image: foo
+    env:
+    - name: KEY
+      value: value
+`,
+			CurrentVersion, `
+version: 0.1-dev
+services:
+- name: foo
+  containers:
+  - image: foo
+    env:
+    - name: KEY
+      value: value
+`,
+			nil,
+		},
+		{
+			"Version 0.1.0",
+			true, `
+version: 0.1-dev
+services:
+- name: db
+  containers:
+  - name: mysql
+    image: mysql
+    env:
+    - MYSQL_DATABASE=wordpress
+    - KEY=a=b
+    mounts:
+    - volumeName: data
+      mountPath: /var/lib/mysql
+volumes:
+- name: data
+  size: 1Gi
+  accessMode: ReadWriteOnce
+`,
+			Version_0_1_0, `
+version: 0.1-dev
+services:
+- name: db
+  containers:
+  - name: mysql
+    image: mysql
+    env:
+    - name: MYSQL_DATABASE
+      value: wordpress
+    - name: KEY
+      value: a=b
+    mounts:
+    - volumeRef: data
+      mountPath: /var/lib/mysql
+volumes:
+- name: data
+  size: 1Gi
+  accessMode: ReadWriteOnce
+`,
+			[]string{
+				"/services/db/containers/mysql/mounts/0: renamed volumeName to volumeRef",
+				`/services/db/containers/mysql/env/0: converted "MYSQL_DATABASE=wordpress" to name and value`,
+				`/services/db/containers/mysql/env/1: converted "KEY=a=b" to name and value`,
+			},
+		},
+		{
+			"Declared version 0.1.0",
+			true, `
+version: 0.1.0
+services:
+- name: db
+  containers:
+  - name: mysql
+    image: mysql
+    env:
+    - KEY=value
+`,
+			Version_0_1_0, `
+version: 0.1-dev
+services:
+- name: db
+  containers:
+  - name: mysql
+    image: mysql
+    env:
+    - name: KEY
+      value: value
+`,
+			[]string{
+				`/version: changed "0.1.0" to "0.1-dev"`,
+				`/services/db/containers/mysql/env/0: converted "KEY=value" to name and value`,
+			},
+		},
+		{
+			"Declared version 0.1.0 without changed fields",
+			true, `
+version: 0.1.0
+services: []
+`,
+			Version_0_1_0, `
+version: 0.1-dev
+services: []
+`,
+			[]string{`/version: changed "0.1.0" to "0.1-dev"`},
+		},
+		{
+			"Anchors and merge keys",
+			true, `
+version: 0.1-dev
+x-mount: &mount
+  volumeName: data
+  mountPath: /data
+services:
+- name: db
+  containers:
+  - name: one
+    image: mysql
+    mounts:
+    - <<: *mount
+      readOnly: true
+  - name: two
+    image: mysql
+    mounts:
+    - *mount
+`,
+			Version_0_1_0, `
+version: 0.1-dev
+x-mount: &mount
+  volumeRef: data
+  mountPath: /data
+services:
+- name: db
+  containers:
+  - name: one
+    image: mysql
+    mounts:
+    - <<: *mount
+      readOnly: true
+  - name: two
+    image: mysql
+    mounts:
+    - *mount
+`,
+			[]string{
+				"/services/db/containers/one/mounts/0: renamed volumeName to volumeRef",
+			},
+		},
+		{
+			"Both volumeName and volumeRef",
+			false, `
+version: 0.1-dev
+services:
+- name: db
+  containers:
+  - image: mysql
+    mounts:
+    - volumeName: data
+      volumeRef: data
+      mountPath: /var/lib/mysql
+`,
+			Version_0_1_0, "", nil,
+		},
+		{
+			"Invalid environment variable",
+			false, `
+version: 0.1-dev
+services:
+- name: db
+  containers:
+  - image: mysql
+    env:
+    - MYSQL_DATABASE
+`,
+			Version_0_1_0, "", nil,
+		},
+		{
+			"Unsupported version",
+			false, `
+version: 0.0.1
+services: []
+`,
+			"0.0.1", "", nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			migrated, from, changes, err := Migrate([]byte(tt.File))
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to migrate: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got:\n%s", migrated)
+			}
+
+			if from != tt.From {
+				t.Errorf("Expected version %q, got %q", tt.From, from)
+			}
+
+			if !reflect.DeepEqual(changes, tt.Changes) {
+				t.Errorf("Expected changes\n%#v\ngot\n%#v", tt.Changes, changes)
+			}
+
+			var expected, got interface{}
+			if err := yaml.Unmarshal([]byte(tt.Expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal(migrated, &got); err != nil {
+				t.Fatalf("Failed to unmarshal migrated file: %s", err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected\n%s\ngot\n%s", tt.Expected, migrated)
+			}
+		})
+	}
+}
+
+func TestMigrateForDecoding(t *testing.T) {
+	implicit := []byte(`
+version: 0.1-dev
+services:
+- name: db
+  containers:
+  - name: mysql
+    image: mysql
+    env:
+    - MYSQL_DATABASE=wordpress
+    mounts:
+    - volumeName: data
+      mountPath: /var/lib/mysql
+volumes:
+- name: data
+  size: 1Gi
+  accessMode: ReadWriteOnce
+`)
+	explicit := []byte(`
+version: 0.1.0
+services:
+- name: db
+  containers:
+  - name: mysql
+    image: mysql
+    env:
+    - MYSQL_DATABASE=wordpress
+    mounts:
+    - volumeName: data
+      mountPath: /var/lib/mysql
+volumes:
+- name: data
+  size: 1Gi
+  accessMode: ReadWriteOnce
+`)
+
+	if _, err := GetDecoderFor(implicit); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetDecoderFor(explicit); err == nil {
+		t.Fatalf("Expected file of version %q not to be decoded without migration", Version_0_1_0)
+	}
+
+	for _, test := range []struct {
+		Name    string
+		Data    []byte
+		Warning string
+	}{
+		{"Implicit", implicit, `the file declares version "0.1-dev" but uses the fields of version "0.1.0" and is migrated from it; declare 'version: 0.1.0' or run 'opencompose migrate' on the file`},
+		{"Explicit", explicit, `the file is written for version "0.1.0" and is migrated to "0.1-dev"; run 'opencompose migrate' on the file`},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			data, warnings := MigrateForDecoding(test.Data)
+			if !reflect.DeepEqual(warnings, []string{test.Warning}) {
+				t.Errorf("Expected warning %q, got %#v", test.Warning, warnings)
+			}
+
+			decoder, err := GetDecoderFor(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			openCompose, err := decoder.Decode(data)
+			if err != nil {
+				t.Fatalf("Failed to decode file of version %q: %s", Version_0_1_0, err)
+			}
+
+			container := openCompose.Services[0].Containers[0]
+			if len(container.Environment) != 1 || container.Environment[0].Key != "MYSQL_DATABASE" || container.Environment[0].Value != "wordpress" {
+				t.Errorf("Unexpected environment variables: %#v", container.Environment)
+			}
+			if len(container.Mounts) != 1 || container.Mounts[0].VolumeRef != "data" {
+				t.Errorf("Unexpected mounts: %#v", container.Mounts)
+			}
+		})
+	}
+
+	current := []byte("version: 0.1-dev\nservices: []\n")
+	if data, warnings := MigrateForDecoding(current); string(data) != string(current) || warnings != nil {
+		t.Errorf("Expected current file to be left as it is, got %q, %#v", data, warnings)
+	}
+}
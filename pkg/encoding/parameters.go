@@ -0,0 +1,98 @@
+package encoding
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding/v1"
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+// Matches parameter references; ${{NAME}} is used for non-string values (e.g. replicas)
+// the same way as in OpenShift Templates, ${NAME} for everything else. $$ is an escaped $.
+var parameterReferenceRegexp = regexp.MustCompile(`\$\$|\$\{\{([A-Za-z_][A-Za-z0-9_]*)\}\}|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// JSON can't contain ${{NAME}} unquoted, the quotes are dropped together with the reference
+var quotedTypedReferenceRegexp = regexp.MustCompile(`"(\$\{\{[A-Za-z_][A-Za-z0-9_]*\}\})"`)
+
+// ParameterResolver returns the text that replaces reference to the parameter.
+// typed is true for ${{NAME}} references.
+type ParameterResolver func(p object.Parameter, typed bool) (string, error)
+
+// Substitutes parameters by their default values
+func DefaultParameterResolver(p object.Parameter, typed bool) (string, error) {
+	if p.Required && p.Value == "" {
+		return "", fmt.Errorf("parameter %q is required and has no default value", p.Name)
+	}
+	return p.Value, nil
+}
+
+// Reads parameters declared in OpenCompose file.
+// Parameters have to be known before the file is decoded because the references
+// to them in non-string fields wouldn't decode.
+func GetParameters(data []byte) ([]object.Parameter, error) {
+	version, err := GetVersion(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch version {
+	// fields of v0.1.0 documents don't change after they are migrated
+	case v1.Version, Version_0_1_0:
+		var st struct {
+			Parameters []v1.Parameter `yaml:"parameters,omitempty"`
+		}
+		if err := util.Unmarshal(data, &st); err != nil {
+			return nil, util.Wrapf(err, "failed to unmarshal OpenCompose parameters")
+		}
+		return v1.ConvertParameters(st.Parameters), nil
+	default:
+		return nil, fmt.Errorf("unsupported version %q", version)
+	}
+}
+
+// Replaces all parameter references in data using resolve and unescapes $$ to $.
+// Referencing parameter that is not declared is an error.
+func SubstituteParameters(data []byte, parameters []object.Parameter, resolve ParameterResolver) ([]byte, error) {
+	declared := make(map[string]object.Parameter)
+	for _, p := range parameters {
+		declared[p.Name] = p
+	}
+
+	if util.IsJSON(data) {
+		data = quotedTypedReferenceRegexp.ReplaceAll(data, []byte("$1"))
+	}
+
+	var err error
+	result := parameterReferenceRegexp.ReplaceAllFunc(data, func(reference []byte) []byte {
+		if err != nil {
+			return reference
+		}
+		if string(reference) == "$$" {
+			return []byte("$")
+		}
+
+		match := parameterReferenceRegexp.FindSubmatch(reference)
+		typed := len(match[1]) > 0
+		name := string(match[2])
+		if typed {
+			name = string(match[1])
+		}
+
+		p, ok := declared[name]
+		if !ok {
+			err = fmt.Errorf("parameter %q is referenced but not declared in 'parameters'", name)
+			return reference
+		}
+
+		var value string
+		value, err = resolve(p, typed)
+		return []byte(value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
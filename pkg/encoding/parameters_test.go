@@ -0,0 +1,131 @@
+package encoding
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+func TestGetParameters(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Succeed    bool
+		File       string
+		Parameters []object.Parameter
+	}{
+		{
+			"No parameters",
+			true, `
+version: 0.1-dev
+services: []
+`,
+			nil,
+		},
+		{
+			"Parameters with references in non-string fields",
+			true, `
+version: 0.1-dev
+parameters:
+- name: REPLICAS
+  description: Number of replicas
+  value: "3"
+  required: true
+- name: DOMAIN
+services:
+- name: foo
+  replicas: ${{REPLICAS}}
+`,
+			[]object.Parameter{
+				{Name: "REPLICAS", Description: "Number of replicas", Value: "3", Required: true},
+				{Name: "DOMAIN"},
+			},
+		},
+		{
+			"Excess key in parameter",
+			false, `
+version: 0.1-dev
+parameters:
+- name: DOMAIN
+  default: example.com
+`,
+			nil,
+		},
+		{
+			"Unsupported version",
+			false, `
+version: 42
+parameters:
+- name: DOMAIN
+`,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			parameters, err := GetParameters([]byte(test.File))
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to get parameters from %q: %s", test.File, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected %q to fail!", test.File)
+				return
+			}
+
+			if !reflect.DeepEqual(parameters, test.Parameters) {
+				t.Errorf("Expected %#v, got %#v", test.Parameters, parameters)
+			}
+		})
+	}
+}
+
+func TestSubstituteParameters(t *testing.T) {
+	parameters := []object.Parameter{
+		{Name: "DOMAIN", Value: "example.com"},
+		{Name: "REPLICAS", Value: "3"},
+		{Name: "TAG", Required: true},
+	}
+
+	tests := []struct {
+		Name    string
+		Succeed bool
+		Data    string
+		Result  string
+	}{
+		{"No references", true, "host: example.com", "host: example.com"},
+		{"String reference", true, "host: www.${DOMAIN}", "host: www.example.com"},
+		{"Typed reference", true, "replicas: ${{REPLICAS}}", "replicas: 3"},
+		{"Multiple references", true, "host: ${DOMAIN}.${DOMAIN}", "host: example.com.example.com"},
+		{"Not declared parameter", false, "host: ${HOST}", ""},
+		{"Required parameter without value", false, "image: foo:${TAG}", ""},
+		{"Not a reference", true, "value: $DOMAIN", "value: $DOMAIN"},
+		{"Escaped references", true, "value: $${DOMAIN} $${{REPLICAS}} $$$${DOMAIN} $$${DOMAIN}", "value: ${DOMAIN} ${{REPLICAS}} $${DOMAIN} $example.com"},
+		{"Typed reference in JSON", true, `{"replicas": "${{REPLICAS}}", "host": "${DOMAIN}"}`, `{"replicas": 3, "host": "example.com"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			result, err := SubstituteParameters([]byte(test.Data), parameters, DefaultParameterResolver)
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to substitute parameters in %q: %s", test.Data, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected %q to fail!", test.Data)
+				return
+			}
+
+			if string(result) != test.Result {
+				t.Errorf("Expected %q, got %q", test.Result, string(result))
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package encoding
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/encoding/v1"
+)
+
+// Returns JSON Schema of the given spec version
+func GetSchemaFor(version string) (*util.Schema, error) {
+	switch version {
+	case v1.Version:
+		return v1.Schema()
+	default:
+		return nil, fmt.Errorf("unsupported version %q", version)
+	}
+}
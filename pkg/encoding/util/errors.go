@@ -0,0 +1,66 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+)
+
+// Codes of the errors found while decoding OpenCompose files
+const (
+	ErrorCode_Syntax        pkgutil.ErrorCode = "OC1001"
+	ErrorCode_ExcessKey     pkgutil.ErrorCode = "OC1002"
+	ErrorCode_RequiredField pkgutil.ErrorCode = "OC1003"
+	ErrorCode_InvalidValue  pkgutil.ErrorCode = "OC1004"
+)
+
+var ErrorCodeDescriptions = map[pkgutil.ErrorCode]string{
+	ErrorCode_Syntax:        "The file is not valid YAML or JSON",
+	ErrorCode_ExcessKey:     "The key is not known",
+	ErrorCode_RequiredField: "The required field is not set",
+	ErrorCode_InvalidValue:  "The value has a wrong type or format",
+}
+
+// SyntaxError is an error of the YAML or JSON syntax of the file
+type SyntaxError struct {
+	Err error
+}
+
+func (e SyntaxError) Error() string {
+	return e.Err.Error()
+}
+
+func (e SyntaxError) Code() pkgutil.ErrorCode {
+	return ErrorCode_Syntax
+}
+
+func (e ExcessKeysError) Code() pkgutil.ErrorCode {
+	return ErrorCode_ExcessKey
+}
+
+// RequiredFieldError is reported by ValidateRequiredFields for every field that is not set
+type RequiredFieldError struct {
+	Field string
+	Value reflect.Value
+}
+
+func (e RequiredFieldError) Error() string {
+	return fmt.Sprintf("Required field %q has unset value %#v", e.Field, e.Value)
+}
+
+func (e RequiredFieldError) Code() pkgutil.ErrorCode {
+	return ErrorCode_RequiredField
+}
+
+// Errors of values that don't have a code of their own are about invalid values
+func (e PathError) Code() pkgutil.ErrorCode {
+	if code := pkgutil.CodeOf(e.Err); code != pkgutil.ErrorCode_Unknown {
+		return code
+	}
+	return ErrorCode_InvalidValue
+}
+
+func (e PositionError) Code() pkgutil.ErrorCode {
+	return pkgutil.CodeOf(e.Err)
+}
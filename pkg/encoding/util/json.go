@@ -0,0 +1,589 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// Reports whether data is a JSON document, i.e. it starts with '{' once the whitespace is skipped.
+// JSON is a subset of YAML but the documents are decoded by encoding/json so they are not
+// subject to YAML rules, e.g. about what is a number and what is a string.
+func IsJSON(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("{"))
+}
+
+// Returns 1-based line and column of the byte at offset
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndex(before, []byte("\n"))
+	return line, column
+}
+
+// Parses JSON document into maps, slices and scalars, numbers are kept as json.Number
+func ParseJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var node interface{}
+	if err := decoder.Decode(&node); err != nil {
+		if e, ok := err.(*json.SyntaxError); ok {
+			// offset is just after the invalid character
+			line, column := position(data, e.Offset-1)
+			return nil, PositionError{Position: Position{Line: line, Column: column}, Err: SyntaxError{fmt.Errorf("invalid JSON: %s", e)}}
+		}
+		return nil, SyntaxError{fmt.Errorf("invalid JSON: %s", err)}
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, SyntaxError{fmt.Errorf("%s", "invalid JSON: unexpected data after the top-level value")}
+	}
+
+	return node, nil
+}
+
+var yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// Returns description of JSON value for errors, e.g. "string `foo`"
+func describeJSON(node interface{}) string {
+	switch n := node.(type) {
+	case string:
+		return fmt.Sprintf("string `%s`", n)
+	case json.Number:
+		return fmt.Sprintf("number `%s`", n)
+	case bool:
+		return fmt.Sprintf("boolean `%t`", n)
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return "null"
+	}
+}
+
+func jsonTypeError(node interface{}, v reflect.Value) error {
+	return fmt.Errorf("cannot unmarshal %s into %s", describeJSON(node), v.Type())
+}
+
+// Converts JSON value to the value yaml would unmarshal into interface{}
+func yamlValue(node interface{}) interface{} {
+	switch n := node.(type) {
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			if int64(int(i)) == i {
+				return int(i)
+			}
+			return i
+		}
+		f, _ := n.Float64()
+		return f
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(n))
+		for k, v := range n {
+			m[k] = yamlValue(v)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(n))
+		for i, v := range n {
+			s[i] = yamlValue(v)
+		}
+		return s
+	default:
+		return node
+	}
+}
+
+// Decodes JSON value parsed by ParseJSON into v the way yaml decodes YAML into Go values,
+// including the calls of UnmarshalYAML, but the scalars keep their JSON types: strings are never
+// numbers and numbers are decoded into strings as they are written.
+func decodeJSON(node interface{}, v reflect.Value) error {
+	if node == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(yamlUnmarshalerType) {
+		return v.Addr().Interface().(yaml.Unmarshaler).UnmarshalYAML(func(out interface{}) error {
+			ov := reflect.ValueOf(out)
+			if ov.Kind() != reflect.Ptr || ov.IsNil() {
+				return fmt.Errorf("can't unmarshal into %T, pointer is required", out)
+			}
+			return decodeJSON(node, ov.Elem())
+		})
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		e := reflect.New(v.Type().Elem())
+		if err := decodeJSON(node, e.Elem()); err != nil {
+			return err
+		}
+		v.Set(e)
+		return nil
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return jsonTypeError(node, v)
+		}
+		v.Set(reflect.ValueOf(yamlValue(node)))
+		return nil
+	case reflect.Struct:
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return jsonTypeError(node, v)
+		}
+		return decodeJSONStruct(m, v)
+	case reflect.Map:
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return jsonTypeError(node, v)
+		}
+		return decodeJSONMap(m, v, nil)
+	case reflect.Slice:
+		items, ok := node.([]interface{})
+		if !ok {
+			return jsonTypeError(node, v)
+		}
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeJSON(item, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.String:
+		switch n := node.(type) {
+		case string:
+			v.SetString(n)
+		case json.Number:
+			v.SetString(string(n))
+		case bool:
+			v.SetString(strconv.FormatBool(n))
+		default:
+			return jsonTypeError(node, v)
+		}
+		return nil
+	case reflect.Bool:
+		b, ok := node.(bool)
+		if !ok {
+			return jsonTypeError(node, v)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := node.(json.Number)
+		if !ok {
+			return jsonTypeError(node, v)
+		}
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if err != nil || v.OverflowInt(i) {
+			return jsonTypeError(node, v)
+		}
+		v.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := node.(json.Number)
+		if !ok {
+			return jsonTypeError(node, v)
+		}
+		u, err := strconv.ParseUint(string(n), 10, 64)
+		if err != nil || v.OverflowUint(u) {
+			return jsonTypeError(node, v)
+		}
+		v.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		n, ok := node.(json.Number)
+		if !ok {
+			return jsonTypeError(node, v)
+		}
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil || v.OverflowFloat(f) {
+			return jsonTypeError(node, v)
+		}
+		v.SetFloat(f)
+		return nil
+	default:
+		return jsonTypeError(node, v)
+	}
+}
+
+// Decodes the keys of m into map v, except for the ones in skip
+func decodeJSONMap(m map[string]interface{}, v reflect.Value, skip map[string]bool) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String && t.Key().Kind() != reflect.Interface {
+		return jsonTypeError(m, v)
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(t))
+	}
+
+	for k, node := range m {
+		if skip[k] {
+			continue
+		}
+		e := reflect.New(t.Elem()).Elem()
+		if err := decodeJSON(node, e); err != nil {
+			return err
+		}
+		key := reflect.ValueOf(k)
+		if t.Key().Kind() == reflect.String {
+			key = key.Convert(t.Key())
+		}
+		v.SetMapIndex(key, e)
+	}
+	return nil
+}
+
+// Decodes the keys of m into fields of struct v, the keys that are not fields go to its inline map
+// if it has one and are left out otherwise, the same as yaml does
+func decodeJSONStruct(m map[string]interface{}, v reflect.Value) error {
+	used := make(map[string]bool)
+	var inline reflect.Value
+	if err := decodeJSONFields(m, v, used, &inline); err != nil {
+		return err
+	}
+	if inline.IsValid() {
+		return decodeJSONMap(m, inline, used)
+	}
+	return nil
+}
+
+func decodeJSONFields(m map[string]interface{}, v reflect.Value, used map[string]bool, inline *reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		if (f.PkgPath != "" && !f.Anonymous) || tag == "-" {
+			continue
+		}
+
+		if isInlineField(f) {
+			switch f.Type.Kind() {
+			case reflect.Map:
+				*inline = v.Field(i)
+			case reflect.Struct:
+				if err := decodeJSONFields(m, v.Field(i), used, inline); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		name := yamlFieldName(f)
+		node, ok := m[name]
+		if !ok {
+			continue
+		}
+		used[name] = true
+		if err := decodeJSON(node, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isInlineField(f reflect.StructField) bool {
+	for _, option := range strings.Split(f.Tag.Get("yaml"), ",")[1:] {
+		if option == "inline" {
+			return true
+		}
+	}
+	return false
+}
+
+// yaml reports lines of the YAML document the node was converted to, they don't mean anything
+var yamlLineRegexp = regexp.MustCompile(`line \d+: `)
+
+// Names of the types for YAML tags in yaml errors, the same as in errors of JSON documents
+var jsonTypeReplacer = strings.NewReplacer(
+	"!!str", "string",
+	"!!int", "number",
+	"!!float", "number",
+	"!!bool", "boolean",
+	"!!null", "null",
+	"!!map", "object",
+	"!!seq", "array",
+)
+
+// Unmarshals node into a new value of type t, JSON values parsed by ParseJSON are decoded
+// directly (see decodeJSON) and YAML ones the same way YAML document would be
+func unmarshalNode(node interface{}, t reflect.Type, isJSON bool) (reflect.Value, error) {
+	v := reflect.New(t)
+
+	if isJSON {
+		return v, decodeJSON(node, v.Elem())
+	}
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return v, err
+	}
+
+	if err := yaml.Unmarshal(data, v.Interface()); err != nil {
+		if e, ok := err.(*yaml.TypeError); ok {
+			message := yamlLineRegexp.ReplaceAllString(strings.Join(e.Errors, "; "), "")
+			return v, fmt.Errorf("%s", jsonTypeReplacer.Replace(message))
+		}
+		return v, err
+	}
+
+	return v, nil
+}
+
+func fieldByYAMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath == "" && yamlFieldName(f) == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Returns the map with the keys of a JSON object if the document is JSON
+func prunedMap(m map[interface{}]interface{}, isJSON bool) interface{} {
+	if !isJSON {
+		return m
+	}
+	object := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		object[k.(string)] = v
+	}
+	return object
+}
+
+// Returns errors of all the values in node that can't be unmarshaled into type t, each pointing
+// to the deepest value that caused it, together with node without these values so the rest
+// of it can be unmarshaled. Removed reports that node itself can't be unmarshaled.
+func collect(node interface{}, t reflect.Type, pointer string, isJSON bool) (pruned interface{}, removed bool, errs []PathError) {
+	_, err := unmarshalNode(node, t, isJSON)
+	if err == nil {
+		return node, false, nil
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+			break
+		}
+
+		// YAML documents have any keys, JSON ones only strings
+		keys := make(map[string]interface{})
+		children := make(map[string]interface{})
+		switch m := n.(type) {
+		case map[interface{}]interface{}:
+			for k, v := range m {
+				keys[fmt.Sprint(k)], children[fmt.Sprint(k)] = k, v
+			}
+		case map[string]interface{}:
+			for k, v := range m {
+				keys[k], children[k] = k, v
+			}
+		}
+
+		var names []string
+		for k := range children {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		m := make(map[interface{}]interface{})
+		for _, k := range names {
+			var childType reflect.Type
+			if t.Kind() == reflect.Struct {
+				f, ok := fieldByYAMLName(t, k)
+				if !ok {
+					// excess key, reported by the object itself
+					m[keys[k]] = children[k]
+					continue
+				}
+				childType = f.Type
+			} else {
+				childType = t.Elem()
+			}
+
+			child, childRemoved, childErrs := collect(children[k], childType, pointer+"/"+EscapePathSegment(k), isJSON)
+			errs = append(errs, childErrs...)
+			if !childRemoved {
+				m[keys[k]] = child
+			}
+		}
+
+		pruned = prunedMap(m, isJSON)
+		_, err = unmarshalNode(pruned, t, isJSON)
+		// every excess key is reported on its own and left out
+		if e, ok := err.(ExcessKeysError); ok {
+			for _, k := range e.ExcessKeys {
+				errs = append(errs, PathError{Path: pointer + "/" + EscapePathSegment(k), Err: ExcessKeysError{Path: e.Path, ExcessKeys: []string{k}}})
+				delete(m, keys[k])
+			}
+			pruned = prunedMap(m, isJSON)
+			_, err = unmarshalNode(pruned, t, isJSON)
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			break
+		}
+
+		items := make([]interface{}, len(n))
+		for i, item := range n {
+			// removed items are left null so the indices of the rest don't change
+			var itemErrs []PathError
+			var itemRemoved bool
+			items[i], itemRemoved, itemErrs = collect(item, t.Elem(), pointer+"/"+strconv.Itoa(i), isJSON)
+			errs = append(errs, itemErrs...)
+			if itemRemoved {
+				items[i] = nil
+			}
+		}
+
+		pruned = items
+		_, err = unmarshalNode(items, t, isJSON)
+	}
+
+	if err != nil {
+		return nil, true, append(errs, PathError{Path: pointer, Err: err})
+	}
+	return pruned, false, errs
+}
+
+// Reports whether path is the same as or inside of a path of one of the errors
+func isErrorPath(path string, errs []PathError) bool {
+	for _, e := range errs {
+		if path == e.Path || strings.HasPrefix(path, e.Path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Unmarshals node into a new value of type t. All the values that can't be unmarshaled are reported,
+// each as PathError, with checkRequired also all the required fields that are not set
+// (see ValidateRequiredFields). More errors are returned in pkgutil.ErrorList.
+func unmarshalAll(node interface{}, t reflect.Type, checkRequired bool, isJSON bool) (reflect.Value, error) {
+	pruned, removed, errs := collect(node, t, "", isJSON)
+
+	var list pkgutil.ErrorList
+	for _, e := range errs {
+		list = append(list, e)
+	}
+	if removed {
+		return reflect.New(t), list.Err()
+	}
+
+	v, err := unmarshalNode(pruned, t, isJSON)
+	if err != nil {
+		return v, list.Append(PathError{Err: err}).Err()
+	}
+
+	if checkRequired && isTraversable(t) {
+		for _, err := range pkgutil.Errors(ValidateRequiredFields(v.Elem())) {
+			// values that were left out because of their errors are reported already
+			if e, ok := err.(PathError); ok && isErrorPath(e.Path, errs) {
+				continue
+			}
+			list = append(list, err)
+		}
+	}
+
+	return v, list.Err()
+}
+
+// Unmarshals JSON document into out using its UnmarshalYAML methods so the same checks apply,
+// with checkRequired the required fields are checked as well (see ValidateRequiredFields).
+// Errors are returned as PathError pointing to the value that caused them,
+// all of them in pkgutil.ErrorList when there are more.
+func UnmarshalJSON(data []byte, out interface{}, checkRequired bool) error {
+	node, err := ParseJSON(data)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("can't unmarshal into %T, pointer is required", out)
+	}
+
+	result, err := unmarshalAll(node, v.Type().Elem(), checkRequired, true)
+	if err != nil {
+		return err
+	}
+
+	v.Elem().Set(result.Elem())
+	return nil
+}
+
+var yamlSyntaxRegexp = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// Returns yaml syntax error as PositionError, yaml reports only lines
+func yamlSyntaxError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	match := yamlSyntaxRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	return PositionError{Position: Position{Line: line}, Err: SyntaxError{fmt.Errorf("yaml: %s", match[2])}}
+}
+
+// Unmarshals YAML or JSON document into out, with checkRequired the required fields are checked
+// as well (see ValidateRequiredFields). Errors of the values are returned as PathError pointing
+// to the value, all of them in pkgutil.ErrorList when there are more, syntax errors as PositionError.
+func UnmarshalDocument(data []byte, out interface{}, checkRequired bool) error {
+	if IsJSON(data) {
+		return UnmarshalJSON(data, out, checkRequired)
+	}
+
+	err := yaml.Unmarshal(data, out)
+	if err == nil && checkRequired {
+		err = ValidateRequiredFields(out)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// the document is read again as plain values to find all the ones that caused errors
+	var node interface{}
+	if nodeErr := yaml.Unmarshal(data, &node); nodeErr != nil {
+		return yamlSyntaxError(nodeErr)
+	}
+	if _, allErr := unmarshalAll(node, reflect.TypeOf(out).Elem(), checkRequired, false); allErr != nil {
+		return allErr
+	}
+	return err
+}
+
+// Unmarshals YAML or JSON document into out, the required fields are not checked
+func Unmarshal(data []byte, out interface{}) error {
+	if IsJSON(data) {
+		return UnmarshalJSON(data, out, false)
+	}
+	return yamlSyntaxError(yaml.Unmarshal(data, out))
+}
@@ -0,0 +1,264 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+)
+
+type J struct {
+	Name     string        `yaml:"name"`
+	Replicas *int          `yaml:"replicas,omitempty"`
+	Items    []JI          `yaml:"items,omitempty"`
+	Extra    map[string]JI `yaml:"extra,omitempty"`
+}
+
+type JI struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value,omitempty"`
+}
+
+func (ji *JI) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type JIAlias JI
+	var st struct {
+		JIAlias   `yaml:",inline"`
+		Leftovers map[string]interface{} `yaml:",inline"`
+	}
+	if err := unmarshal(&st); err != nil {
+		return err
+	}
+
+	if len(st.Leftovers) > 0 {
+		return NewExcessKeysErrorFromMap("JI", st.Leftovers)
+	}
+
+	*ji = JI(st.JIAlias)
+	return nil
+}
+
+func TestIsJSON(t *testing.T) {
+	tests := []struct {
+		JSON bool
+		Data string
+	}{
+		{true, `{"version": "0.1-dev"}`},
+		{true, "\n\t {}"},
+		{false, "version: 0.1-dev"},
+		{false, `["a"]`},
+		{false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Data, func(t *testing.T) {
+			if IsJSON([]byte(tt.Data)) != tt.JSON {
+				t.Errorf("Expected IsJSON(%q) to be %t", tt.Data, tt.JSON)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	two := 2
+	tests := []struct {
+		Name    string
+		Succeed bool
+		Data    string
+		Result  J
+		Path    string
+	}{
+		{
+			"Valid",
+			true,
+			`{"name": "a", "replicas": 2, "items": [{"key": "k", "value": "v"}]}`,
+			J{Name: "a", Replicas: &two, Items: []JI{{Key: "k", Value: "v"}}},
+			"",
+		},
+		{
+			"String is not a number",
+			false,
+			`{"name": "a", "replicas": "2"}`,
+			J{},
+			"/replicas",
+		},
+		{
+			"Number is kept as written",
+			true,
+			`{"name": "a", "items": [{"key": "k", "value": 1.50}]}`,
+			J{Name: "a", Items: []JI{{Key: "k", Value: "1.50"}}},
+			"",
+		},
+		{
+			"Big number is kept as written",
+			true,
+			`{"name": "a", "items": [{"key": "k", "value": 12345678901234567890}, {"key": "l", "value": true}]}`,
+			J{Name: "a", Items: []JI{{Key: "k", Value: "12345678901234567890"}, {Key: "l", Value: "true"}}},
+			"",
+		},
+		{
+			"Fraction is not an integer",
+			false,
+			`{"name": "a", "replicas": 2.0}`,
+			J{},
+			"/replicas",
+		},
+		{
+			"Object is not a string",
+			false,
+			`{"name": "a", "items": [{"key": {"a": "b"}}]}`,
+			J{},
+			"/items/0/key",
+		},
+		{
+			"Excess key",
+			false,
+			`{"name": "a", "items": [{"key": "k"}, {"key": "l", "val": "v"}]}`,
+			J{},
+			"/items/1/val",
+		},
+		{
+			"Required field",
+			false,
+			`{"name": "a", "items": [{"value": "v"}]}`,
+			J{},
+			"/items/0/key",
+		},
+		{
+			"Required fields are not checked in maps",
+			true,
+			`{"name": "a", "extra": {"x": {"value": "v"}}}`,
+			J{Name: "a", Extra: map[string]JI{"x": {Value: "v"}}},
+			"",
+		},
+		{
+			"Escaped map key",
+			false,
+			`{"name": "a", "extra": {"a/b~c": {"val": "v"}}}`,
+			J{},
+			"/extra/a~1b~0c/val",
+		},
+		{
+			"Invalid JSON",
+			false,
+			`{"name": "a",}`,
+			J{},
+			"",
+		},
+		{
+			"Data after the document",
+			false,
+			`{"name": "a"} {}`,
+			J{},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var j J
+			err := UnmarshalJSON([]byte(tt.Data), &j, true)
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to unmarshal %q: %s", tt.Data, err)
+				}
+
+				e, ok := err.(PathError)
+				if tt.Path == "" {
+					if ok {
+						t.Fatalf("Expected syntax error, got: %s", err)
+					}
+					return
+				}
+				if !ok || e.Path != tt.Path {
+					t.Fatalf("Expected error at %q, got: %s", tt.Path, err)
+				}
+				return
+			}
+
+			if !tt.Succeed {
+				t.Fatalf("Expected %q to fail, got: %#v", tt.Data, j)
+			}
+
+			if !reflect.DeepEqual(j, tt.Result) {
+				t.Fatalf("Expected %#v, got %#v", tt.Result, j)
+			}
+		})
+	}
+}
+
+func TestParseJSON_Position(t *testing.T) {
+	_, err := ParseJSON([]byte("{\n  \"a\": 1\n  \"b\": 2\n}"))
+	e, ok := err.(PositionError)
+	if !ok {
+		t.Fatalf("Expected PositionError, got: %v", err)
+	}
+
+	if e.Position != (Position{3, 3}) {
+		t.Fatalf("Expected the error at 3:3, got %s", e.Position)
+	}
+}
+
+func TestUnmarshalDocument_AllErrors(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Data  string
+		Paths []string
+		Codes []pkgutil.ErrorCode
+	}{
+		{
+			"YAML",
+			`
+name: a
+replicas: x
+items:
+- key: k
+  val: v
+  other: o
+- value: v
+`,
+			[]string{"/items/0/other", "/items/0/val", "/replicas", "/items/1/key"},
+			[]pkgutil.ErrorCode{ErrorCode_ExcessKey, ErrorCode_ExcessKey, ErrorCode_InvalidValue, ErrorCode_RequiredField},
+		},
+		{
+			"JSON",
+			`{"replicas": "2", "items": [{"key": "k"}, {"key": 1, "val": "v"}]}`,
+			[]string{"/items/1/val", "/replicas", "/name"},
+			[]pkgutil.ErrorCode{ErrorCode_ExcessKey, ErrorCode_InvalidValue, ErrorCode_RequiredField},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var j J
+			err := UnmarshalDocument([]byte(tt.Data), &j, true)
+
+			var paths []string
+			var codes []pkgutil.ErrorCode
+			for _, e := range pkgutil.Errors(err) {
+				pe, ok := e.(PathError)
+				if !ok {
+					t.Fatalf("Expected PathError, got: %#v", e)
+				}
+				paths = append(paths, pe.Path)
+				codes = append(codes, pkgutil.CodeOf(pe))
+			}
+
+			if !reflect.DeepEqual(paths, tt.Paths) {
+				t.Fatalf("Expected errors at %q, got %q (%s)", tt.Paths, paths, err)
+			}
+			if !reflect.DeepEqual(codes, tt.Codes) {
+				t.Fatalf("Expected codes %q, got %q (%s)", tt.Codes, codes, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalDocument_SyntaxError(t *testing.T) {
+	for _, data := range []string{"a: [", `{"a": }`} {
+		var j J
+		err := UnmarshalDocument([]byte(data), &j, true)
+		if code := pkgutil.CodeOf(err); code != ErrorCode_Syntax {
+			t.Errorf("Expected syntax error for %q, got %s (%v)", data, code, err)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Position in a file, Column is 0 when only the line is known
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	if p.Column == 0 {
+		return strconv.Itoa(p.Line)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// PositionError is an error located in a file. File and Source (the line at the position)
+// are filled in by whoever knows the file, the decoders work only with the data.
+type PositionError struct {
+	File     string
+	Position Position
+	Source   string
+	Err      error
+}
+
+func (e PositionError) Error() string {
+	var message string
+	if e.File != "" {
+		message = fmt.Sprintf("%s:%s: %s", e.File, e.Position, e.Err)
+	} else {
+		message = fmt.Sprintf("line %s: %s", e.Position, e.Err)
+	}
+
+	if e.Source == "" {
+		return message
+	}
+	message += "\n    " + e.Source
+
+	if e.Position.Column == 0 {
+		return message
+	}
+
+	// caret under the column, tabs are kept so it lines up
+	caret := ""
+	for i, c := range e.Source {
+		if i >= e.Position.Column-1 {
+			break
+		}
+		if c == '\t' {
+			caret += "\t"
+		} else {
+			caret += " "
+		}
+	}
+	return message + "\n    " + caret + "^"
+}
+
+// Returns error located in the file with the line at the position as its source
+func NewPositionError(file string, data []byte, position Position, err error) PositionError {
+	lines := strings.Split(string(data), "\n")
+	source := ""
+	if position.Line > 0 && position.Line <= len(lines) {
+		source = strings.TrimRight(lines[position.Line-1], "\r")
+	}
+	return PositionError{File: file, Position: position, Source: source, Err: err}
+}
+
+// PathError is an error of the value at Path, a JSON pointer (RFC 6901) into the document.
+// Context says what was being done, e.g. "failed to unmarshal OpenCompose".
+type PathError struct {
+	Context string
+	Path    string
+	Err     error
+}
+
+func (e PathError) Error() string {
+	if e.Context != "" {
+		return fmt.Sprintf("%s: %q: %s", e.Context, e.Path, e.Err)
+	}
+	return fmt.Sprintf("%q: %s", e.Path, e.Err)
+}
+
+// Returns the message of the error without the path
+func (e PathError) Message() string {
+	if e.Context != "" {
+		return fmt.Sprintf("%s: %s", e.Context, e.Err)
+	}
+	return e.Err.Error()
+}
+
+// Adds context to err keeping its path and position so they can be still reported
+func Wrapf(err error, format string, args ...interface{}) error {
+	context := fmt.Sprintf(format, args...)
+	switch e := err.(type) {
+	case PathError:
+		if e.Context != "" {
+			context += ": " + e.Context
+		}
+		e.Context = context
+		return e
+	case PositionError:
+		e.Err = Wrapf(e.Err, format, args...)
+		return e
+	case SyntaxError:
+		e.Err = fmt.Errorf("%s: %s", context, e.Err)
+		return e
+	case pkgutil.ErrorList:
+		var list pkgutil.ErrorList
+		for _, item := range e {
+			list = append(list, Wrapf(item, format, args...))
+		}
+		return list
+	default:
+		return fmt.Errorf("%s: %s", context, err)
+	}
+}
+
+// Escapes JSON pointer segment
+func EscapePathSegment(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+// PositionIndex maps paths (JSON pointers) of the values in a document to their positions.
+// Items of sequences are also available by the value of their "name" key instead of the index,
+// e.g. "/services/web/containers/nginx", so the values can be found after the documents are merged.
+type PositionIndex map[string]Position
+
+// Returns position of the value at path or of its closest parent that is in the index
+// together with the path that was found
+func (pi PositionIndex) Lookup(path string) (Position, string, bool) {
+	for {
+		if p, ok := pi[path]; ok {
+			return p, path, true
+		}
+		if path == "" {
+			return Position{}, "", false
+		}
+		path = path[:strings.LastIndex(path, "/")]
+	}
+}
+
+// Adds paths using names of sequence items for all the paths in the index.
+// When more items of a sequence have the same name (e.g. a duplicate) only the first one
+// is identified by it, the others keep their index.
+func (pi PositionIndex) addNamePaths(names map[string]string) {
+	// path of the first item with the name for each sequence and name
+	first := make(map[string]string)
+	for path, name := range names {
+		key := path[:strings.LastIndex(path, "/")] + "/" + name
+		if f, ok := first[key]; !ok || itemIndex(path) < itemIndex(f) {
+			first[key] = path
+		}
+	}
+
+	for path, position := range pi {
+		segments := strings.Split(path, "/")
+		prefix := ""
+		var named []string
+		for _, segment := range segments[1:] {
+			prefix += "/" + segment
+			if name, ok := names[prefix]; ok && first[prefix[:strings.LastIndex(prefix, "/")]+"/"+name] == prefix {
+				segment = EscapePathSegment(name)
+			}
+			named = append(named, segment)
+		}
+
+		if alias := "/" + strings.Join(named, "/"); len(named) > 0 && alias != path {
+			if _, ok := pi[alias]; !ok {
+				pi[alias] = position
+			}
+		}
+	}
+}
+
+// Reports whether the path points to an item of a sequence
+func isItemPath(path string) bool {
+	_, err := strconv.Atoi(path[strings.LastIndex(path, "/")+1:])
+	return path != "" && err == nil
+}
+
+// Returns index of the sequence item at path
+func itemIndex(path string) int {
+	i, _ := strconv.Atoi(path[strings.LastIndex(path, "/")+1:])
+	return i
+}
+
+// Returns index of the values in YAML or JSON document (JSON is parsed as YAML), keys are indexed
+// at the position of the key and sequence items at the position of their value.
+// Broken documents that can't be parsed give an empty index.
+func IndexPositions(data []byte) PositionIndex {
+	index := make(PositionIndex)
+
+	var document yaml3.Node
+	if err := yaml3.Unmarshal(data, &document); err != nil || len(document.Content) == 0 {
+		return index
+	}
+
+	pi := &positionIndexer{
+		index:     index,
+		names:     make(map[string]string),
+		following: make(map[*yaml3.Node]bool),
+	}
+	root := document.Content[0]
+	index[""] = Position{Line: root.Line, Column: root.Column}
+	pi.node(root, "")
+
+	index.addNamePaths(pi.names)
+	return index
+}
+
+type positionIndexer struct {
+	index PositionIndex
+	// names of sequence items by their path
+	names map[string]string
+	// anchors of the aliases being followed, an alias can be inside of its own anchor
+	following map[*yaml3.Node]bool
+}
+
+// Returns the node the alias refers to or nil if the alias is being followed already
+func (pi *positionIndexer) resolve(n *yaml3.Node) *yaml3.Node {
+	if n.Kind != yaml3.AliasNode {
+		return n
+	}
+	if n.Alias == nil || pi.following[n.Alias] {
+		return nil
+	}
+	return n.Alias
+}
+
+// Indexes the content of the node at path, values of aliases are indexed at the anchor
+// since that's where they are written
+func (pi *positionIndexer) node(n *yaml3.Node, path string) {
+	switch n.Kind {
+	case yaml3.AliasNode:
+		if anchor := pi.resolve(n); anchor != nil {
+			pi.following[anchor] = true
+			pi.node(anchor, path)
+			delete(pi.following, anchor)
+		}
+	case yaml3.SequenceNode:
+		for i, item := range n.Content {
+			itemPath := path + "/" + strconv.Itoa(i)
+			pi.index[itemPath] = Position{Line: item.Line, Column: item.Column}
+			pi.node(item, itemPath)
+		}
+	case yaml3.MappingNode:
+		pi.mapping(n, path, false)
+	}
+}
+
+// Indexes keys of the mapping, merged keys ("<<") are indexed only if the mapping doesn't set them
+func (pi *positionIndexer) mapping(n *yaml3.Node, path string, merged bool) {
+	var merges []*yaml3.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Kind == yaml3.ScalarNode && key.Tag == "!!merge" {
+			merges = append(merges, value)
+			continue
+		}
+
+		keyPath := path + "/" + EscapePathSegment(key.Value)
+		if _, ok := pi.index[keyPath]; ok && merged {
+			continue
+		}
+		pi.index[keyPath] = Position{Line: key.Line, Column: key.Column}
+		pi.node(value, keyPath)
+
+		if key.Value == "name" && isItemPath(path) {
+			if name := pi.resolve(value); name != nil && name.Kind == yaml3.ScalarNode {
+				pi.names[path] = name.Value
+			}
+		}
+	}
+
+	for _, m := range merges {
+		items := []*yaml3.Node{m}
+		if m.Kind == yaml3.SequenceNode {
+			items = m.Content
+		}
+		for _, item := range items {
+			if item = pi.resolve(item); item != nil && item.Kind == yaml3.MappingNode && !pi.following[item] {
+				pi.following[item] = true
+				pi.mapping(item, path, true)
+				delete(pi.following, item)
+			}
+		}
+	}
+}
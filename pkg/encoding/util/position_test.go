@@ -0,0 +1,159 @@
+package util
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIndexPositions(t *testing.T) {
+	yamlFile := `version: 0.1-dev  # comment
+x-anchors:
+  env: &env
+  - name: A
+    value: "a: # b"
+services:
+- name: web
+  labels: {a: b,
+    c: d}
+  containers:
+  - name: "nginx"
+    image: nginx
+    command: |
+      foo:
+      - bar
+    ports:
+    - port: 80
+      type: external
+  -   name: sidecar
+      image: busybox
+- name: db
+  containers: []
+volumes:
+  - name: data
+    size: 1Gi
+x-services:
+- name: web
+  env:
+  - name: A
+  - name: A
+    value: b
+`
+
+	anchorsFile := `x-common: &common
+  image: nginx
+  env: &env
+  - name: A
+services:
+- <<: *common
+  name: web
+  image: nginx:1.11
+  containers: *env
+- &loop
+  name: loop
+  self: *loop
+`
+
+	jsonFile := `{
+  "version": "0.1-dev",
+  "services": [
+    {
+      "name": "web",
+      "containers": [{"name": "nginx", "image": "nginx"}]
+    }
+  ]
+}`
+
+	tests := []struct {
+		Name     string
+		Data     string
+		Path     string
+		Position Position
+	}{
+		{"YAML root", yamlFile, "", Position{1, 1}},
+		{"YAML key with comment", yamlFile, "/version", Position{1, 1}},
+		{"YAML item in anchor", yamlFile, "/x-anchors/env/0/value", Position{5, 5}},
+		{"YAML sequence item", yamlFile, "/services/0", Position{7, 3}},
+		{"YAML flow mapping", yamlFile, "/services/0/labels", Position{8, 3}},
+		{"YAML key after flow mapping", yamlFile, "/services/0/containers", Position{10, 3}},
+		{"YAML nested item", yamlFile, "/services/0/containers/0/image", Position{12, 5}},
+		{"YAML key after block scalar", yamlFile, "/services/0/containers/0/ports/0/type", Position{18, 7}},
+		{"YAML item with more spaces", yamlFile, "/services/0/containers/1/image", Position{20, 7}},
+		{"YAML item by name", yamlFile, "/services/web/containers/nginx/ports/0/port", Position{17, 7}},
+		{"YAML quoted name", yamlFile, "/services/web/containers/nginx", Position{11, 5}},
+		{"YAML second item", yamlFile, "/services/db/containers", Position{22, 3}},
+		{"YAML indented sequence", yamlFile, "/volumes/data/size", Position{25, 5}},
+		{"YAML first of items with the same name", yamlFile, "/x-services/web/env/A", Position{29, 5}},
+		{"YAML item with the same name by index", yamlFile, "/x-services/web/env/1/value", Position{31, 5}},
+		{"YAML merged key", anchorsFile, "/services/0/env", Position{3, 3}},
+		{"YAML key overriding merged key", anchorsFile, "/services/0/image", Position{8, 3}},
+		{"YAML alias", anchorsFile, "/services/web/containers/A", Position{4, 5}},
+		{"YAML alias inside its anchor", anchorsFile, "/services/loop/self", Position{12, 3}},
+		{"JSON key", jsonFile, "/version", Position{2, 3}},
+		{"JSON item by index", jsonFile, "/services/0/containers/0/image", Position{6, 40}},
+		{"JSON item by name", jsonFile, "/services/web/containers/nginx", Position{6, 22}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			index := IndexPositions([]byte(tt.Data))
+			position, ok := index[tt.Path]
+			if !ok {
+				t.Fatalf("Path %q not found in index %#v", tt.Path, index)
+			}
+			if position != tt.Position {
+				t.Fatalf("Expected %q at %s, got %s", tt.Path, tt.Position, position)
+			}
+		})
+	}
+}
+
+func TestIndexPositions_Broken(t *testing.T) {
+	index := IndexPositions([]byte("services:\n- name: web\n  image: [nginx\n"))
+	if len(index) != 0 {
+		t.Fatalf("Expected empty index of a broken document, got %#v", index)
+	}
+}
+
+func TestPositionIndex_Lookup(t *testing.T) {
+	index := PositionIndex{
+		"":              {1, 1},
+		"/services":     {2, 1},
+		"/services/web": {3, 3},
+	}
+
+	position, path, ok := index.Lookup("/services/web/containers/nginx")
+	if !ok || path != "/services/web" || position != (Position{3, 3}) {
+		t.Fatalf("Expected the service to be found, got %q at %s", path, position)
+	}
+
+	if _, _, ok := (PositionIndex{}).Lookup("/services"); ok {
+		t.Fatal("Expected nothing to be found in an empty index")
+	}
+}
+
+func TestPositionError_Error(t *testing.T) {
+	data := []byte("services:\n- name: web\n\tportz: 80\n")
+	err := NewPositionError("file.yaml", data, Position{3, 2}, errors.New("excess key"))
+
+	expected := "file.yaml:3:2: excess key\n    \tportz: 80\n    \t^"
+	if err.Error() != expected {
+		t.Fatalf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestWrapf(t *testing.T) {
+	err := Wrapf(PathError{Path: "/a", Err: errors.New("invalid")}, "failed to unmarshal %s", "OpenCompose")
+	e, ok := err.(PathError)
+	if !ok || e.Path != "/a" {
+		t.Fatalf("Expected PathError keeping the path, got %#v", err)
+	}
+
+	expected := `failed to unmarshal OpenCompose: "/a": invalid`
+	if err.Error() != expected {
+		t.Fatalf("Expected %q, got %q", expected, err.Error())
+	}
+
+	if err := Wrapf(errors.New("invalid"), "context"); err.Error() != "context: invalid" {
+		t.Fatalf("Expected plain error with context, got %q", err)
+	}
+}
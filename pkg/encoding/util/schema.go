@@ -0,0 +1,180 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Schema is the subset of JSON Schema (draft-07) used to describe OpenCompose formats
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	PatternProperties    map[string]*Schema `json:"patternProperties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *int64             `json:"minimum,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	// Properties that can be set only together with the listed ones
+	Dependencies map[string][]string `json:"dependencies,omitempty"`
+}
+
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Matches values with parameter or variable references, they are replaced before the file is decoded
+// so the value can be of any type and format until then
+const ReferencePattern = `\$\{`
+
+// SchemaDescriber is implemented by types whose schema differs from what their Go type implies,
+// e.g. because of custom unmarshalling. JSONSchema gets the generated schema and returns the one to use.
+type SchemaDescriber interface {
+	JSONSchema(generated *Schema) *Schema
+}
+
+// Returns schema that accepts values valid for s as well as strings with references,
+// description and default value of s are moved to the returned schema
+func WithReferences(s *Schema) *Schema {
+	value := *s
+	value.Description = ""
+	value.Default = nil
+	return &Schema{
+		Description: s.Description,
+		Default:     s.Default,
+		AnyOf: []*Schema{
+			&value,
+			{Type: "string", Pattern: ReferencePattern},
+		},
+	}
+}
+
+// Generates schema for values of type t as they are unmarshaled from YAML.
+// Struct fields are named after their yaml tags and the ones that are not omitempty are required,
+// the same way as ValidateRequiredFields checks them. Fields are documented by "description"
+// and "default" tags, the default value is written in YAML.
+func GenerateSchema(t reflect.Type) (*Schema, error) {
+	return generateSchema(t, true)
+}
+
+func generateSchema(t reflect.Type, checkRequired bool) (*Schema, error) {
+	if t.Kind() == reflect.Ptr {
+		return generateSchema(t.Elem(), checkRequired)
+	}
+
+	var s *Schema
+	switch t.Kind() {
+	case reflect.Struct:
+		s = &Schema{
+			Type:       "object",
+			Properties: make(map[string]*Schema),
+			// extension keys are allowed everywhere
+			PatternProperties:    map[string]*Schema{"^" + extensionKeyPrefix: {}},
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" || f.Tag.Get("yaml") == "-" {
+				continue
+			}
+
+			fs, err := generateSchema(f.Type, checkRequired)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %s", f.Name, err)
+			}
+
+			fs.Description = f.Tag.Get("description")
+			if d := f.Tag.Get("default"); d != "" {
+				if err := yaml.Unmarshal([]byte(d), &fs.Default); err != nil {
+					return nil, fmt.Errorf("field %q: invalid default value %q: %s", f.Name, d, err)
+				}
+			}
+
+			name := yamlFieldName(f)
+			s.Properties[name] = fs
+			if checkRequired && IsRequiredField(f) {
+				s.Required = append(s.Required, name)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, err := generateSchema(t.Elem(), checkRequired)
+		if err != nil {
+			return nil, err
+		}
+		s = &Schema{Type: "array", Items: items}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %q", t.Key())
+		}
+		// ValidateRequiredFields doesn't go into maps
+		values, err := generateSchema(t.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		s = &Schema{Type: "object", AdditionalProperties: values}
+	case reflect.String:
+		s = &Schema{Type: "string"}
+	case reflect.Bool:
+		s = WithReferences(&Schema{Type: "boolean"})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = WithReferences(&Schema{Type: "integer"})
+	case reflect.Float32, reflect.Float64:
+		s = WithReferences(&Schema{Type: "number"})
+	case reflect.Interface:
+		s = &Schema{}
+	default:
+		return nil, fmt.Errorf("unsupported type %q", t)
+	}
+
+	if d, ok := reflect.Zero(t).Interface().(SchemaDescriber); ok {
+		s = d.JSONSchema(s)
+	}
+
+	return s, nil
+}
+
+// Returns the schema of property name of the objects s describes or nil if there is no such property
+func (s *Schema) Property(name string) *Schema {
+	if s.Items != nil {
+		return s.Items.Property(name)
+	}
+	if p, ok := s.Properties[name]; ok {
+		return p
+	}
+	if values, ok := s.AdditionalProperties.(*Schema); ok {
+		return values.Property(name)
+	}
+	for _, alternative := range s.AnyOf {
+		if p := alternative.Property(name); p != nil {
+			return p
+		}
+	}
+	return nil
+}
+
+// Returns the object schema of the properties of s, e.g. of the items of an array, or nil if it has none
+func (s *Schema) Object() *Schema {
+	if s.Items != nil {
+		return s.Items.Object()
+	}
+	if s.Properties != nil {
+		return s
+	}
+	if values, ok := s.AdditionalProperties.(*Schema); ok {
+		return values.Object()
+	}
+	for _, alternative := range s.AnyOf {
+		if o := alternative.Object(); o != nil {
+			return o
+		}
+	}
+	return nil
+}
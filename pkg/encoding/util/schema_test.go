@@ -0,0 +1,76 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+type schemaItem struct {
+	Name     string            `yaml:"name"`
+	Count    *int              `yaml:"count,omitempty"`
+	Labels   map[string]string `yaml:"labels,omitempty"`
+	internal string
+}
+
+type schemaColor string
+
+func (c schemaColor) JSONSchema(generated *Schema) *Schema {
+	generated.Enum = []string{"red", "green"}
+	return generated
+}
+
+type schemaRoot struct {
+	Items     []schemaItem          `yaml:"items"`
+	Templates map[string]schemaItem `yaml:"templates,omitempty"`
+	Color     schemaColor           `yaml:"color,omitempty"`
+	Ignored   string                `yaml:"-"`
+}
+
+func TestGenerateSchema(t *testing.T) {
+	integer := WithReferences(&Schema{Type: "integer"})
+	extensions := map[string]*Schema{"^x-": {}}
+	item := func(required []string) *Schema {
+		return &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"name":   {Type: "string"},
+				"count":  integer,
+				"labels": {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+			},
+			PatternProperties:    extensions,
+			AdditionalProperties: false,
+			Required:             required,
+		}
+	}
+
+	expected := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"items":     {Type: "array", Items: item([]string{"name"})},
+			"templates": {Type: "object", AdditionalProperties: item(nil)},
+			"color":     {Type: "string", Enum: []string{"red", "green"}},
+		},
+		PatternProperties:    extensions,
+		AdditionalProperties: false,
+		Required:             []string{"items"},
+	}
+
+	schema, err := GenerateSchema(reflect.TypeOf(schemaRoot{}))
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %s", err)
+	}
+
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("Expected\n%#v\ngot\n%#v", expected, schema)
+	}
+}
+
+func TestGenerateSchema_UnsupportedType(t *testing.T) {
+	type unsupported struct {
+		Values map[int]string `yaml:"values"`
+	}
+
+	if _, err := GenerateSchema(reflect.TypeOf(unsupported{})); err == nil {
+		t.Errorf("Expected to fail for map with non-string keys")
+	}
+}
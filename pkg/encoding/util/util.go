@@ -4,7 +4,13 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
 )
 
 const (
@@ -28,6 +34,16 @@ func ValidateResourceName(name string) error {
 	return nil
 }
 
+// Schema of the names ValidateResourceName accepts
+func ResourceNameSchema() *Schema {
+	maxLength := maxResourceNameLength
+	return &Schema{
+		Type:      "string",
+		Pattern:   "^" + resourceNameRegexpString + "$",
+		MaxLength: &maxLength,
+	}
+}
+
 type ExcessKeysError struct {
 	Path       string
 	ExcessKeys []string
@@ -37,11 +53,24 @@ func (e ExcessKeysError) Error() string {
 	return fmt.Sprintf("excess keys in %q: %#v", e.Path, e.ExcessKeys)
 }
 
+// Keys starting with "x-" are extensions, e.g. for holding YAML anchors, and are ignored
+const extensionKeyPrefix = "x-"
+
+// Removes extension keys from keys caught by the inline map so only the excess ones are left
+func RemoveExtensionKeys(m map[string]interface{}) {
+	for k := range m {
+		if strings.HasPrefix(k, extensionKeyPrefix) {
+			delete(m, k)
+		}
+	}
+}
+
 func NewExcessKeysErrorFromMap(path string, m map[string]interface{}) ExcessKeysError {
 	var keys []string
 	for k := range m {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 	return ExcessKeysError{Path: path, ExcessKeys: keys}
 }
 
@@ -68,13 +97,21 @@ func isTraversable(t reflect.Type) bool {
 	switch t.Kind() {
 	case reflect.Struct:
 		return true
-	case reflect.Slice, reflect.Array:
+	case reflect.Slice, reflect.Array, reflect.Ptr:
 		return isTraversable(t.Elem())
 	default:
 		return false
 	}
 }
 
+// Partial is implemented by types whose values can leave out required fields
+// because they are completed later, e.g. by a template they extend
+type Partial interface {
+	IsPartial() bool
+}
+
+// Checks that all the required fields in i are set. Every unset field is reported
+// as PathError pointing to it, all of them are returned in pkgutil.ErrorList.
 func ValidateRequiredFields(i interface{}) error {
 	var v reflect.Value
 	var ok bool
@@ -82,43 +119,152 @@ func ValidateRequiredFields(i interface{}) error {
 		v = reflect.ValueOf(i)
 	}
 
+	return requiredFieldErrors(v, "").Err()
+}
+
+func requiredFieldErrors(v reflect.Value, pointer string) pkgutil.ErrorList {
 	if v.Kind() == reflect.Ptr {
+		// optional structs are pointers and unset ones have nothing to validate
+		if v.IsNil() {
+			return nil
+		}
 		v = v.Elem()
 	}
 
+	if v.CanInterface() {
+		if p, ok := v.Interface().(Partial); ok && p.IsPartial() {
+			return nil
+		}
+	}
+
+	var errs pkgutil.ErrorList
 	switch t := v.Kind(); t {
 	case reflect.Struct:
 		for i := 0; i < v.NumField(); i++ {
 			fv := v.Field(i)
 			ft := v.Type().Field(i)
+			fieldPointer := pointer + "/" + EscapePathSegment(yamlFieldName(ft))
 
 			if IsRequiredField(ft) {
 				// zero value means it wasn't set
 				// in cases when the zero value is valid value you need to make it a pointer to that value
 				if IsUnset(fv) {
-					return fmt.Errorf("Required field %q has unset value %#v", ft.Name, fv)
+					errs = append(errs, PathError{Path: fieldPointer, Err: RequiredFieldError{Field: ft.Name, Value: fv}})
+					continue
 				}
 			}
 
 			if isTraversable(fv.Type()) {
-				err := ValidateRequiredFields(fv)
-				if err != nil {
-					return err
-				}
+				errs = append(errs, requiredFieldErrors(fv, fieldPointer)...)
 			}
 		}
 	case reflect.Slice, reflect.Array:
 		if isTraversable(v.Type()) {
 			for i := 0; i < v.Len(); i++ {
-				err := ValidateRequiredFields(v.Index(i))
-				if err != nil {
-					return err
-				}
+				errs = append(errs, requiredFieldErrors(v.Index(i), pointer+"/"+strconv.Itoa(i))...)
 			}
 		}
 	default:
-		return fmt.Errorf("unsupported type %q", t)
+		errs = append(errs, PathError{Path: pointer, Err: fmt.Errorf("unsupported type %q", t)})
 	}
 
-	return nil
+	return errs
+}
+
+func yamlFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	return name
+}
+
+func omitUnset(v reflect.Value) (interface{}, error) {
+	if v.CanInterface() {
+		if m, ok := v.Interface().(yaml.Marshaler); ok {
+			if v.Kind() == reflect.Ptr && v.IsNil() {
+				return nil, nil
+			}
+			value, err := m.MarshalYAML()
+			if err != nil {
+				return nil, err
+			}
+			return omitUnset(reflect.ValueOf(value))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return omitUnset(v.Elem())
+	case reflect.Struct:
+		var result yaml.MapSlice
+		for i := 0; i < v.NumField(); i++ {
+			fv := v.Field(i)
+			ft := v.Type().Field(i)
+
+			if ft.PkgPath != "" || ft.Tag.Get("yaml") == "-" || IsUnset(fv) {
+				continue
+			}
+			if fv.Kind() == reflect.Map && fv.Len() == 0 {
+				continue
+			}
+
+			value, err := omitUnset(fv)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, yaml.MapItem{Key: yamlFieldName(ft), Value: value})
+		}
+		return result, nil
+	case reflect.Slice, reflect.Array:
+		result := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			value, err := omitUnset(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = value
+		}
+		return result, nil
+	case reflect.Map:
+		// yaml sorts map keys so the output is stable
+		result := make(map[interface{}]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			value, err := omitUnset(v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			result[key.Interface()] = value
+		}
+		return result, nil
+	case reflect.Invalid:
+		return nil, nil
+	default:
+		return v.Interface(), nil
+	}
+}
+
+// Marshals i into YAML leaving out all the fields that are not set, i.e. the same fields
+// that ValidateRequiredFields treats as unset, regardless of "omitempty".
+// Fields are written in the order they are declared so the output is stable,
+// in the same style as MarshalNode writes.
+func MarshalOmittingUnset(i interface{}) ([]byte, error) {
+	value, err := omitUnset(reflect.ValueOf(i))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml3.Node
+	if err := yaml3.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	return MarshalNode(&node)
 }
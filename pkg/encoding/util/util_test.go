@@ -15,6 +15,15 @@ type S struct {
 
 var integer int
 
+type P struct {
+	Partial bool `yaml:"omitempty"`
+	Ra      int
+}
+
+func (p P) IsPartial() bool {
+	return p.Partial
+}
+
 func TestIsRequiredField(t *testing.T) {
 	tests := []struct {
 		Required bool
@@ -118,6 +127,30 @@ func TestValidateRequiredFields(t *testing.T) {
 				},
 			},
 		}},
+		{true, struct {
+			A *S `yaml:"omitempty"`
+		}{}},
+		{false, struct {
+			A *S `yaml:"omitempty"`
+		}{
+			A: &S{
+				Rb: &integer,
+			},
+		}},
+		{true, struct {
+			A *S `yaml:"omitempty"`
+		}{
+			A: &S{
+				Ra: 42,
+				Rb: &integer,
+			},
+		}},
+		{false, []P{
+			{},
+		}},
+		{true, []P{
+			{Partial: true},
+		}},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,271 @@
+package util
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Returns YAML document of the node in the canonical style: indented by 2 spaces with sequences
+// that are values of mappings not indented ("services:\n- name: web"), the same way yaml.v2
+// writes them. Comments, anchors and aliases of the node are kept.
+func MarshalNode(node *yaml3.Node) ([]byte, error) {
+	clearMergeTags(node)
+
+	var buf bytes.Buffer
+	encoder := yaml3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	// yaml.v3 always indents the sequences, they are moved back by the lines they span
+	var document yaml3.Node
+	if err := yaml3.Unmarshal(buf.Bytes(), &document); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	dedents := make([]int, len(lines)+1)
+	for _, n := range document.Content {
+		sequenceDedents(n, dedents, lines, len(lines)+1)
+	}
+
+	for i, line := range lines {
+		if dedent := dedents[i+1]; dedent > 0 && strings.TrimSpace(line) != "" {
+			lines[i] = line[dedent:]
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// Adds to dedents (by 1-based line) the indentation of block sequences in mappings for the lines
+// they span, node spans the lines up to end (exclusive). Lines of nested sequences are moved
+// by all the sequences they are in.
+func sequenceDedents(node *yaml3.Node, dedents []int, lines []string, end int) {
+	switch node.Kind {
+	case yaml3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			valueEnd := end
+			if i+2 < len(node.Content) {
+				valueEnd = node.Content[i+2].Line
+			}
+
+			// sequences with anchor start at the anchor, the items are 2 columns after their dashes
+			column := 0
+			if value.Kind == yaml3.SequenceNode && len(value.Content) > 0 {
+				column = value.Content[0].Column - 2
+			}
+			if value.Kind == yaml3.SequenceNode && value.Style&yaml3.FlowStyle == 0 && column > key.Column {
+				// head comment of the first item is between the key and the item
+				indent := column - 1
+				for line := key.Line + 1; line < valueEnd && line <= len(lines); line++ {
+					content := lines[line-1]
+					if len(content)-len(strings.TrimLeft(content, " ")) >= indent {
+						dedents[line] += column - key.Column
+					}
+				}
+			}
+
+			sequenceDedents(value, dedents, lines, valueEnd)
+		}
+	case yaml3.SequenceNode:
+		for i, item := range node.Content {
+			itemEnd := end
+			if i+1 < len(node.Content) {
+				itemEnd = node.Content[i+1].Line
+			}
+			sequenceDedents(item, dedents, lines, itemEnd)
+		}
+	}
+}
+
+// yaml.v3 writes merge keys with their tag ("!!merge <<"), without it they are resolved the same
+func clearMergeTags(node *yaml3.Node) {
+	if node.Kind == yaml3.ScalarNode && node.Tag == "!!merge" && node.Style&yaml3.TaggedStyle == 0 {
+		node.Tag = ""
+	}
+	for _, n := range node.Content {
+		clearMergeTags(n)
+	}
+}
+
+// Fields of a struct by their YAML names, inline structs included
+type yamlField struct {
+	index int
+	t     reflect.Type
+	tag   reflect.StructTag
+}
+
+func yamlFields(t reflect.Type, fields map[string]yamlField) map[string]yamlField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if (f.PkgPath != "" && !f.Anonymous) || f.Tag.Get("yaml") == "-" {
+			continue
+		}
+		if isInlineField(f) {
+			if f.Type.Kind() == reflect.Struct {
+				yamlFields(f.Type, fields)
+			}
+			continue
+		}
+		fields[yamlFieldName(f)] = yamlField{index: len(fields), t: f.Type, tag: f.Tag}
+	}
+	return fields
+}
+
+// Booleans of YAML 1.1 that yaml.v2 and other YAML 1.1 parsers read, besides true and false
+var yaml11BoolRegexp = regexp.MustCompile(`^(?:y|Y|yes|Yes|YES|n|N|no|No|NO|on|On|ON|off|Off|OFF)$`)
+
+// Key and value of a mapping, order is the position of the field the key is for
+type nodePair struct {
+	key, value *yaml3.Node
+	order      int
+}
+
+type nodePairs struct {
+	pairs []nodePair
+	less  func(a, b nodePair) bool
+}
+
+func (p nodePairs) Len() int           { return len(p.pairs) }
+func (p nodePairs) Swap(i, j int)      { p.pairs[i], p.pairs[j] = p.pairs[j], p.pairs[i] }
+func (p nodePairs) Less(i, j int) bool { return p.less(p.pairs[i], p.pairs[j]) }
+
+// Reports whether the value can be left out of the canonical format: it's empty, or it's a scalar
+// the canonical encoding leaves out (it's not in keep) and it's empty or the default value of the field.
+// Values with anchors or comments are never left out.
+func isOmittable(key, value *yaml3.Node, f yamlField, path string, keep PositionIndex) bool {
+	if value.Anchor != "" || value.Kind == yaml3.AliasNode || hasNodeComments(key) || hasNodeComments(value) {
+		return false
+	}
+
+	switch value.Kind {
+	case yaml3.MappingNode, yaml3.SequenceNode:
+		return len(value.Content) == 0
+	case yaml3.ScalarNode:
+		if value.Tag == "!!null" {
+			return true
+		}
+		if _, ok := keep[path]; ok {
+			return false
+		}
+		if value.Tag == "!!str" && value.Value == "" {
+			return true
+		}
+		if d, ok := f.tag.Lookup("default"); ok && d == value.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func hasNodeComments(n *yaml3.Node) bool {
+	return n.HeadComment != "" || n.LineComment != "" || n.FootComment != ""
+}
+
+// Rewrites node into the canonical format of values of type t: mapping keys in the order the fields
+// are declared (see yamlFields) and collections in block style. Extension ("x-") and merge keys
+// go first so the anchors they define stay before the aliases, values that can be left out
+// (see isOmittable) are removed. Keep is the index of the value encoded in the canonical format.
+// Mappings with merge keys keep all their keys, the values can override the merged ones.
+func CanonicalizeNode(node *yaml3.Node, t reflect.Type, path string, keep PositionIndex) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml3.ScalarNode:
+		// yaml.v3 quotes the values whose type would change without the quotes,
+		// except for booleans of YAML 1.1 which it reads as strings
+		if node.Style&(yaml3.SingleQuotedStyle|yaml3.DoubleQuotedStyle) != 0 && !yaml11BoolRegexp.MatchString(node.Value) {
+			node.Style &^= yaml3.SingleQuotedStyle | yaml3.DoubleQuotedStyle
+		}
+		return
+	case yaml3.SequenceNode:
+		if len(node.Content) > 0 {
+			node.Style &^= yaml3.FlowStyle
+		}
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		for i, item := range node.Content {
+			CanonicalizeNode(item, elem, path+"/"+strconv.Itoa(i), keep)
+		}
+		return
+	case yaml3.MappingNode:
+		if len(node.Content) > 0 {
+			node.Style &^= yaml3.FlowStyle
+		}
+	default:
+		return
+	}
+
+	var first, known, rest []nodePair
+
+	merged := false
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag == "!!merge" {
+			merged = true
+		}
+	}
+
+	var fields map[string]yamlField
+	if t != nil && t.Kind() == reflect.Struct {
+		fields = yamlFields(t, make(map[string]yamlField))
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		p := nodePair{key: node.Content[i], value: node.Content[i+1]}
+		childPath := path + "/" + EscapePathSegment(p.key.Value)
+
+		switch {
+		case p.key.Tag == "!!merge" || strings.HasPrefix(p.key.Value, "x-") && t != nil && t.Kind() == reflect.Struct:
+			CanonicalizeNode(p.value, nil, childPath, keep)
+			first = append(first, p)
+		case fields != nil:
+			f, ok := fields[p.key.Value]
+			if !ok {
+				CanonicalizeNode(p.value, nil, childPath, keep)
+				rest = append(rest, p)
+				continue
+			}
+			if !merged && isOmittable(p.key, p.value, f, childPath, keep) {
+				continue
+			}
+			CanonicalizeNode(p.value, f.t, childPath, keep)
+			p.order = f.index
+			known = append(known, p)
+		case t != nil && t.Kind() == reflect.Map:
+			CanonicalizeNode(p.value, t.Elem(), childPath, keep)
+			known = append(known, p)
+		default:
+			CanonicalizeNode(p.value, nil, childPath, keep)
+			rest = append(rest, p)
+		}
+	}
+
+	if fields != nil {
+		sort.Stable(nodePairs{known, func(a, b nodePair) bool { return a.order < b.order }})
+	} else {
+		// the same order yaml writes maps in
+		sort.Stable(nodePairs{known, func(a, b nodePair) bool { return a.key.Value < b.key.Value }})
+	}
+
+	node.Content = node.Content[:0]
+	for _, pairs := range [][]nodePair{first, known, rest} {
+		for _, p := range pairs {
+			node.Content = append(node.Content, p.key, p.value)
+		}
+	}
+}
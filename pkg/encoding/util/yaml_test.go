@@ -0,0 +1,107 @@
+package util
+
+import (
+	"testing"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+func TestMarshalNode(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Data   string
+		Output string
+	}{
+		{
+			"Sequences in mappings are not indented",
+			`services:
+    - name: web
+      containers:
+          - name: nginx
+            args:
+                - -g
+      labels:
+          app: web
+volumes:
+  - name: data
+`,
+			`services:
+- name: web
+  containers:
+  - name: nginx
+    args:
+    - -g
+  labels:
+    app: web
+volumes:
+- name: data
+`,
+		},
+		{
+			"Comments, anchors and block scalars",
+			`# head
+x-common: &common
+  image: nginx  # line
+services:
+  # first item
+  - <<: *common
+    command:
+      - |
+        multi
+          line
+    env: []
+`,
+			`# head
+x-common: &common
+  image: nginx # line
+services:
+# first item
+- <<: *common
+  command:
+  - |
+    multi
+      line
+  env: []
+`,
+		},
+		{
+			"Sequence with anchor",
+			`x-env: &env
+  - name: DEBUG
+    value: "0"
+services:
+  - name: web
+    env: *env
+`,
+			`x-env: &env
+- name: DEBUG
+  value: "0"
+services:
+- name: web
+  env: *env
+`,
+		},
+		{
+			"Nested sequences",
+			"- - a\n  - - b\n",
+			"- - a\n  - - b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var node yaml3.Node
+			if err := yaml3.Unmarshal([]byte(tt.Data), &node); err != nil {
+				t.Fatalf("Failed to unmarshal: %s", err)
+			}
+
+			data, err := MarshalNode(&node)
+			if err != nil {
+				t.Fatalf("Failed to marshal: %s", err)
+			}
+			if string(data) != tt.Output {
+				t.Fatalf("Expected:\n%s\ngot:\n%s", tt.Output, data)
+			}
+		})
+	}
+}
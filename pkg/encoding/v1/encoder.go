@@ -0,0 +1,250 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+func convertEnv(env []object.EnvVariable) []EnvVariable {
+	var result []EnvVariable
+	for _, e := range env {
+		result = append(result, EnvVariable{
+			Key:   e.Key,
+			Value: e.Value,
+		})
+	}
+	return result
+}
+
+// Inverse of convertService
+func unconvertService(os *object.Service) Service {
+	s := Service{
+		Name:     ResourceName(os.Name),
+		Replicas: os.Replicas,
+		Labels:   Labels(os.Labels),
+		Enabled:  os.Enabled,
+	}
+
+	if os.Extends != "" {
+		extends := ResourceName(os.Extends)
+		s.Extends = &extends
+	}
+
+	for _, oc := range os.Containers {
+		c := Container{
+			Name:    ResourceName(oc.Name),
+			Image:   ImageRef(oc.Image),
+			Command: oc.Command,
+			Args:    oc.Args,
+			Env:     convertEnv(oc.Environment),
+		}
+
+		for _, p := range oc.Ports {
+			c.Ports = append(c.Ports, Port{
+				Port: PortMapping{
+					ContainerPort: p.Port.ContainerPort,
+					ServicePort:   p.Port.ServicePort,
+				},
+				Type: PortType(p.Type),
+				Host: (*Fqdn)(p.Host),
+				Path: (*PathRegex)(goutil.StringAddrOrNil(p.Path)),
+			})
+		}
+
+		for _, m := range oc.Mounts {
+			mount := Mount{
+				VolumeRef:     ResourceName(m.VolumeRef),
+				MountPath:     m.MountPath,
+				VolumeSubPath: goutil.StringAddrOrNil(m.VolumeSubPath),
+			}
+
+			if m.ReadOnly {
+				mount.ReadOnly = goutil.BoolAddr(true)
+			}
+
+			c.Mounts = append(c.Mounts, mount)
+		}
+
+		if oc.Build != nil {
+			c.Build = &Build{
+				Git: GitSource{
+					URL:        oc.Build.Git.URL,
+					Ref:        goutil.StringAddrOrNil(oc.Build.Git.Ref),
+					ContextDir: goutil.StringAddrOrNil(oc.Build.Git.ContextDir),
+				},
+				Dockerfile: goutil.StringAddrOrNil(oc.Build.Dockerfile),
+				Args:       convertEnv(oc.Build.Args),
+			}
+		}
+
+		s.Containers = append(s.Containers, c)
+	}
+
+	for _, emptydir := range os.EmptyDirVolumes {
+		s.EmptyDirVolumes = append(s.EmptyDirVolumes, EmptyDirVolume{
+			Name: ResourceName(emptydir.Name),
+		})
+	}
+
+	return s
+}
+
+// Inverse of convertVolume
+func unconvertVolume(ov *object.Volume) Volume {
+	v := Volume{
+		Name:       ResourceName(ov.Name),
+		Size:       ov.Size,
+		AccessMode: ov.AccessMode,
+	}
+
+	if ov.StorageClass != nil {
+		storageClass := ResourceName(*ov.StorageClass)
+		v.StorageClass = &storageClass
+	}
+
+	return v
+}
+
+// Inverse of ConvertParameters
+func unconvertParameters(parameters []object.Parameter) []Parameter {
+	var result []Parameter
+	for _, op := range parameters {
+		p := Parameter{
+			Name:        op.Name,
+			DisplayName: goutil.StringAddrOrNil(op.DisplayName),
+			Description: goutil.StringAddrOrNil(op.Description),
+			Value:       goutil.StringAddrOrNil(op.Value),
+		}
+
+		if op.Required {
+			p.Required = goutil.BoolAddr(true)
+		}
+
+		result = append(result, p)
+	}
+	return result
+}
+
+// Inverse of ConvertIncludes
+func unconvertIncludes(includes []object.Include) []Include {
+	var result []Include
+	for _, oi := range includes {
+		i := Include{
+			Sha256: goutil.StringAddrOrNil(oi.Sha256),
+		}
+		if oi.IsURL {
+			i.URL = oi.Location
+		} else {
+			i.Path = oi.Location
+		}
+		result = append(result, i)
+	}
+	return result
+}
+
+type Encoder struct{}
+
+// Marshals object.OpenCompose into OpenCompose file
+// Fields that are not set (or are set to their default values) are left out
+// so decoding the result gives back the same object
+func (e *Encoder) Encode(o *object.OpenCompose) ([]byte, error) {
+	if o.Version != Version {
+		return nil, fmt.Errorf("can't encode version %q as %q", o.Version, Version)
+	}
+
+	v1 := OpenCompose{
+		Version:    VersionString(o.Version),
+		Include:    unconvertIncludes(o.Include),
+		Parameters: unconvertParameters(o.Parameters),
+	}
+
+	for i := range o.Services {
+		v1.Services = append(v1.Services, unconvertService(&o.Services[i]))
+	}
+
+	for i := range o.Volumes {
+		v1.Volumes = append(v1.Volumes, unconvertVolume(&o.Volumes[i]))
+	}
+
+	for name, t := range o.Templates {
+		if v1.Templates == nil {
+			v1.Templates = make(map[string]Service)
+		}
+		v1.Templates[name] = unconvertService(&t)
+	}
+
+	for name, op := range o.Profiles {
+		p := Profile{}
+		for i := range op.Services {
+			p.Services = append(p.Services, unconvertService(&op.Services[i]))
+		}
+		for i := range op.Volumes {
+			p.Volumes = append(p.Volumes, unconvertVolume(&op.Volumes[i]))
+		}
+
+		if v1.Profiles == nil {
+			v1.Profiles = make(map[string]Profile)
+		}
+		v1.Profiles[name] = p
+	}
+
+	data, err := util.MarshalOmittingUnset(v1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OpenCompose: %s", err)
+	}
+
+	return data, nil
+}
+
+// Rewrites OpenCompose file in the canonical format the same as Encode but keeping its comments,
+// extension fields and anchors. Fails if the file can't be decoded or the rewritten file
+// wouldn't decode to the same object.
+func (e *Encoder) Format(data []byte) ([]byte, error) {
+	// every file is formatted on its own so it can be an override with required fields left out
+	decoder := &Decoder{}
+	o, err := decoder.DecodeOverride(data)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := e.Encode(o)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yaml3.Node
+	if err := yaml3.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return canonical, nil
+	}
+	util.CanonicalizeNode(document.Content[0], reflect.TypeOf(OpenCompose{}), "", util.IndexPositions(canonical))
+
+	formatted, err := util.MarshalNode(&document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OpenCompose: %s", err)
+	}
+
+	// aliases can end up before their anchors when the keys are reordered
+	reformatted, err := decoder.DecodeOverride(formatted)
+	if err != nil {
+		return nil, fmt.Errorf("the file can't be formatted without changing it: %s", err)
+	}
+	// unset and empty values are the same, e.g. "labels: {}" and no labels
+	recanonical, err := e.Encode(reformatted)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(recanonical, canonical) {
+		return nil, fmt.Errorf("%s", "the file can't be formatted without changing it")
+	}
+
+	return formatted, nil
+}
@@ -0,0 +1,181 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+func TestEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output string
+	}{
+		{
+			"Canonical file stays the same",
+			`version: 0.1-dev
+include:
+- base.yaml
+- url: https://example.com/redis.yaml
+  sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx
+    command:
+    - nginx
+    args:
+    - -g
+    - daemon off;
+    env:
+    - name: KEY
+      value: value
+    ports:
+    - port: 8080
+      type: external
+      host: example.com
+      path: /web
+    - port: 5000:80
+    mounts:
+    - volumeRef: data
+      mountPath: /data
+      volumeSubPath: web
+      readOnly: true
+    build:
+      git:
+        url: https://github.com/example/web.git
+        ref: master
+      dockerfile: Dockerfile.web
+      args:
+      - name: VERSION
+        value: "1"
+  replicas: 0
+  emptyDirVolumes:
+  - name: cache
+  labels:
+    app: web
+    tier: frontend
+  enabled: false
+volumes:
+- name: data
+  size: 1Gi
+  accessMode: ReadWriteOnce
+  storageClass: fast
+parameters:
+- name: REPLICAS
+  description: Number of replicas
+  value: "2"
+  required: true
+`,
+			"",
+		},
+		{
+			"Defaults and extension keys are left out, fields are ordered",
+			`
+x-common: &common
+  image: nginx
+services:
+- containers:
+  - name: nginx
+    <<: *common
+    x-note: left out
+    ports:
+    - port: 80:80
+      type: internal
+    mounts:
+    - volumeRef: data
+      mountPath: /data
+      readOnly: false
+  name: web
+version: 0.1-dev
+`,
+			`version: 0.1-dev
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx
+    ports:
+    - port: 80
+    mounts:
+    - volumeRef: data
+      mountPath: /data
+`,
+		},
+		{
+			"Templates and profiles",
+			`version: 0.1-dev
+services:
+- name: web
+  extends: base
+templates:
+  base:
+    containers:
+    - name: nginx
+      image: nginx
+profiles:
+  dev:
+    services:
+    - name: web
+      replicas: 1
+    volumes:
+    - name: data
+      size: 100Mi
+  prod:
+    services:
+    - name: web
+      replicas: 3
+`,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			// partial services can't be checked for required fields
+			openCompose, err := (&Decoder{}).DecodeOverride([]byte(test.Input))
+			if err != nil {
+				t.Fatalf("Failed to decode %q: %s", test.Input, err)
+			}
+
+			data, err := (&Encoder{}).Encode(openCompose)
+			if err != nil {
+				t.Fatalf("Failed to encode %s: %s", spew.Sprint(openCompose), err)
+			}
+
+			expected := test.Output
+			if expected == "" {
+				expected = test.Input
+			}
+			if string(data) != expected {
+				t.Fatalf("Expected:\n%s\nGot:\n%s", expected, data)
+			}
+
+			decoded, err := (&Decoder{}).DecodeOverride(data)
+			if err != nil {
+				t.Fatalf("Failed to decode encoded file %q: %s", data, err)
+			}
+			if !reflect.DeepEqual(decoded, openCompose) {
+				t.Fatalf("Encoding isn't lossless, expected: %s\nGot: %s", spew.Sprint(openCompose), spew.Sprint(decoded))
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode_InvalidVersion(t *testing.T) {
+	openCompose := &object.OpenCompose{
+		Version: "1",
+		Services: []object.Service{
+			{Name: "web", Replicas: goutil.Int32Addr(1)},
+		},
+	}
+
+	if data, err := (&Encoder{}).Encode(openCompose); err == nil {
+		t.Fatalf("Expected failure, but succeeded: %q", data)
+	}
+}
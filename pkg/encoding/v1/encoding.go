@@ -9,7 +9,6 @@ import (
 	"github.com/redhat-developer/opencompose/pkg/encoding/util"
 	"github.com/redhat-developer/opencompose/pkg/goutil"
 	"github.com/redhat-developer/opencompose/pkg/object"
-	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -79,6 +78,13 @@ func (pm *PortMapping) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+func (pm PortMapping) MarshalYAML() (interface{}, error) {
+	if pm.ServicePort == pm.ContainerPort {
+		return pm.ContainerPort, nil
+	}
+	return fmt.Sprintf("%d:%d", pm.ContainerPort, pm.ServicePort), nil
+}
+
 type PortType object.PortType
 
 func (pt *PortType) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -100,6 +106,17 @@ func (pt *PortType) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+func (pt PortType) MarshalYAML() (interface{}, error) {
+	switch object.PortType(pt) {
+	case object.PortType_Internal:
+		return "internal", nil
+	case object.PortType_External:
+		return "external", nil
+	default:
+		return nil, fmt.Errorf("failed to marshal port type: invalid port type %d", pt)
+	}
+}
+
 // Fully qualified domain name as defined by RFC 3986
 type Fqdn string
 
@@ -111,10 +128,10 @@ type PathRegex string
 // TODO: Add PathRegex unmarshalling to validate it
 
 type Port struct {
-	Port PortMapping `yaml:"port"`
-	Type PortType    `yaml:"type,omitempty"`
-	Host *Fqdn       `yaml:"host,omitempty"`
-	Path *PathRegex  `yaml:"path,omitempty"`
+	Port PortMapping `yaml:"port" description:"Port to expose as containerPort:servicePort, the servicePort defaults to the containerPort. The containerPort is where the application in the container accepts connections, the servicePort is where the service is accessible for others."`
+	Type PortType    `yaml:"type,omitempty" default:"internal" description:"Whether the port is accessible only from inside the cluster (internal) or from outside of it as well (external)."`
+	Host *Fqdn       `yaml:"host,omitempty" description:"Fully qualified domain name (RFC 3986) the port is exposed at outside of the cluster by an Ingress (or a Route on OpenShift). DNS records pointing to the cluster router have to be set up separately."`
+	Path *PathRegex  `yaml:"path,omitempty" description:"Extended POSIX regex (IEEE Std 1003.1) of the request paths routed to the port, all paths of the host are routed when it is not set. Requires host."`
 }
 
 func (v *Port) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -128,6 +145,7 @@ func (v *Port) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	util.RemoveExtensionKeys(st.Leftovers)
 	if len(st.Leftovers) > 0 {
 		return util.NewExcessKeysErrorFromMap("Port", st.Leftovers)
 	}
@@ -148,8 +166,8 @@ func (v *Port) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 type EnvVariable struct {
-	Key   string `yaml:"name"`
-	Value string `yaml:"value"`
+	Key   string `yaml:"name" description:"Name of the environment variable."`
+	Value string `yaml:"value,omitempty" description:"Value of the environment variable, empty if left out."`
 }
 
 func (raw *EnvVariable) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -163,6 +181,7 @@ func (raw *EnvVariable) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	util.RemoveExtensionKeys(st.Leftovers)
 	if len(st.Leftovers) > 0 {
 		return util.NewExcessKeysErrorFromMap("Env", st.Leftovers)
 	}
@@ -185,19 +204,19 @@ func (lb *Labels) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// Image reference, malformed references are reported by the validation
+// so they don't hide the other errors of the file
 type ImageRef string
 
-// FIXME: implement ImageRef unmarshalling
-
 type Mount struct {
-	VolumeRef ResourceName `yaml:"volumeRef"`
-	MountPath string       `yaml:"mountPath"`
+	VolumeRef ResourceName `yaml:"volumeRef" description:"Name of the volume from the top level volumes or the emptyDirVolumes of the service."`
+	MountPath string       `yaml:"mountPath" description:"Absolute path in the container the volume is mounted at, can't contain ':'."`
 	// these are optional fields so making them as pointer because it helps
 	// to identify whether these fields were given by user or not
 	// if these are not pointer then it is hard to identify what was given
 	// by user and what is the default value
-	VolumeSubPath *string `yaml:"volumeSubPath,omitempty"`
-	ReadOnly      *bool   `yaml:"readOnly,omitempty"`
+	VolumeSubPath *string `yaml:"volumeSubPath,omitempty" description:"Path within the volume that is mounted instead of its root."`
+	ReadOnly      *bool   `yaml:"readOnly,omitempty" default:"false" description:"The volume is mounted read-only if true, read-write otherwise."`
 }
 
 func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -210,6 +229,7 @@ func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	util.RemoveExtensionKeys(st.Leftovers)
 	if len(st.Leftovers) > 0 {
 		return util.NewExcessKeysErrorFromMap("Mount", st.Leftovers)
 	}
@@ -219,12 +239,67 @@ func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+type GitSource struct {
+	URL        string  `yaml:"url" description:"URL of the git repository, e.g. https://github.com/foo/bar.git or git@github.com:foo/bar.git."`
+	Ref        *string `yaml:"ref,omitempty" description:"Branch, tag or commit to build, defaults to the default branch of the repository."`
+	ContextDir *string `yaml:"contextDir,omitempty" description:"Directory within the repository used as the build context, defaults to the repository root."`
+}
+
+func (g *GitSource) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type GitSourceAlias GitSource
+	var st struct {
+		GitSourceAlias `yaml:",inline"`
+		Leftovers      map[string]interface{} `yaml:",inline"` // Catches all undefined fields and must be empty after parsing.
+	}
+	if err := unmarshal(&st); err != nil {
+		return err
+	}
+
+	util.RemoveExtensionKeys(st.Leftovers)
+	if len(st.Leftovers) > 0 {
+		return util.NewExcessKeysErrorFromMap("Git", st.Leftovers)
+	}
+
+	*g = GitSource(st.GitSourceAlias)
+
+	return nil
+}
+
+type Build struct {
+	Git        GitSource     `yaml:"git" description:"Git repository with the source code."`
+	Dockerfile *string       `yaml:"dockerfile,omitempty" default:"Dockerfile" description:"Path to the Dockerfile relative to the contextDir."`
+	Args       []EnvVariable `yaml:"args,omitempty" description:"Build arguments passed to the Docker build (ARG in the Dockerfile)."`
+}
+
+func (b *Build) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type BuildAlias Build
+	var st struct {
+		BuildAlias `yaml:",inline"`
+		Leftovers  map[string]interface{} `yaml:",inline"` // Catches all undefined fields and must be empty after parsing.
+	}
+	if err := unmarshal(&st); err != nil {
+		return err
+	}
+
+	util.RemoveExtensionKeys(st.Leftovers)
+	if len(st.Leftovers) > 0 {
+		return util.NewExcessKeysErrorFromMap("Build", st.Leftovers)
+	}
+
+	*b = Build(st.BuildAlias)
+
+	return nil
+}
+
 type Container struct {
-	Name   ResourceName  `yaml:"name"`
-	Image  ImageRef      `yaml:"image"`
-	Env    []EnvVariable `yaml:"env,omitempty"`
-	Ports  []Port        `yaml:"ports,omitempty"`
-	Mounts []Mount       `yaml:"mounts,omitempty"`
+	Name    ResourceName  `yaml:"name" description:"Name of the container, unique within the service."`
+	Image   ImageRef      `yaml:"image" description:"Image the container is started from as [registry/]repository[:tag][@digest], e.g. nginx:1.11 or registry.example.com:5000/team/app@sha256:..."`
+	Command []string      `yaml:"command,omitempty" description:"Command the container runs instead of the entrypoint of the image."`
+	Args    []string      `yaml:"args,omitempty" description:"Arguments passed to the command instead of the default ones of the image."`
+	Env     []EnvVariable `yaml:"env,omitempty" description:"Environment variables set in the container."`
+	Ports   []Port        `yaml:"ports,omitempty" description:"Ports the container exposes and how they are accessible."`
+	Mounts  []Mount       `yaml:"mounts,omitempty" description:"Volumes mounted in the container."`
+	Build   *Build        `yaml:"build,omitempty" description:"How the image is built from source. Used only with --distro openshift where it generates a BuildConfig, the built image is pushed to the ImageStreamTag derived from the image."`
 }
 
 func (c *Container) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -238,6 +313,7 @@ func (c *Container) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	util.RemoveExtensionKeys(st.Leftovers)
 	if len(st.Leftovers) > 0 {
 		return util.NewExcessKeysErrorFromMap("Container", st.Leftovers)
 	}
@@ -248,7 +324,7 @@ func (c *Container) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 type EmptyDirVolume struct {
-	Name ResourceName `yaml:"name"`
+	Name ResourceName `yaml:"name" description:"Name of the EmptyDir volume the containers' mounts refer to."`
 }
 
 func (e *EmptyDirVolume) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -261,6 +337,7 @@ func (e *EmptyDirVolume) UnmarshalYAML(unmarshal func(interface{}) error) error
 		return err
 	}
 
+	util.RemoveExtensionKeys(st.Leftovers)
 	if len(st.Leftovers) > 0 {
 		return util.NewExcessKeysErrorFromMap("EmptyDirVolume", st.Leftovers)
 	}
@@ -271,11 +348,19 @@ func (e *EmptyDirVolume) UnmarshalYAML(unmarshal func(interface{}) error) error
 }
 
 type Service struct {
-	Name            ResourceName     `yaml:"name"`
-	Containers      []Container      `yaml:"containers"`
-	Replicas        *int32           `yaml:"replicas,omitempty"`
-	EmptyDirVolumes []EmptyDirVolume `yaml:"emptyDirVolumes,omitempty"`
-	Labels          Labels           `yaml:"labels,omitempty"`
+	Name            ResourceName     `yaml:"name" description:"Name of the service."`
+	Extends         *ResourceName    `yaml:"extends,omitempty" description:"Name of the template the service is based on. The service is merged over the template so it needs only the fields it adds or changes."`
+	Containers      []Container      `yaml:"containers" description:"Containers of the service. They are scheduled together in the same pod and can communicate using localhost."`
+	Replicas        *int32           `yaml:"replicas,omitempty" default:"1" description:"Number of desired pods of the service."`
+	EmptyDirVolumes []EmptyDirVolume `yaml:"emptyDirVolumes,omitempty" description:"EmptyDir volumes shared by the containers of the service."`
+	Labels          Labels           `yaml:"labels,omitempty" description:"Labels applied to the objects generated for the service."`
+	Enabled         *bool            `yaml:"enabled,omitempty" default:"true" description:"Disabled services are left out of the conversion, usually set by profiles."`
+}
+
+// Service extending a template gets the missing required fields from it,
+// they are checked once the template is resolved
+func (s Service) IsPartial() bool {
+	return s.Extends != nil
 }
 
 func (s *Service) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -289,6 +374,7 @@ func (s *Service) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	util.RemoveExtensionKeys(st.Leftovers)
 	if len(st.Leftovers) > 0 {
 		return util.NewExcessKeysErrorFromMap("Service", st.Leftovers)
 	}
@@ -299,10 +385,10 @@ func (s *Service) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 type Volume struct {
-	Name         ResourceName  `yaml:"name"`
-	Size         string        `yaml:"size"`
-	AccessMode   string        `yaml:"accessMode"`
-	StorageClass *ResourceName `yaml:"storageClass,omitempty"`
+	Name         ResourceName  `yaml:"name" description:"Name of the volume the containers' mounts refer to."`
+	Size         string        `yaml:"size" description:"Size of the volume as Kubernetes quantity, e.g. 5Gi."`
+	AccessMode   string        `yaml:"accessMode" description:"Access mode the volume should have."`
+	StorageClass *ResourceName `yaml:"storageClass,omitempty" description:"Name of the StorageClass that backs the volume."`
 }
 
 func (v *Volume) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -316,6 +402,7 @@ func (v *Volume) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	util.RemoveExtensionKeys(st.Leftovers)
 	if len(st.Leftovers) > 0 {
 		return util.NewExcessKeysErrorFromMap("Volume", st.Leftovers)
 	}
@@ -325,6 +412,54 @@ func (v *Volume) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+type Parameter struct {
+	Name        string  `yaml:"name" description:"Name of the parameter, referenced as ${NAME} or as ${{NAME}} for values that are not strings."`
+	DisplayName *string `yaml:"displayName,omitempty" description:"Name of the parameter shown in the user interface."`
+	Description *string `yaml:"description,omitempty" description:"Description of the parameter."`
+	Value       *string `yaml:"value,omitempty" description:"Default value of the parameter."`
+	Required    *bool   `yaml:"required,omitempty" default:"false" description:"The parameter has to have a value. Converting to anything but OpenShift Template fails if a required parameter has no default value."`
+}
+
+func (p *Parameter) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type ParameterAlias Parameter
+	var st struct {
+		ParameterAlias `yaml:",inline"`
+		Leftovers      map[string]interface{} `yaml:",inline"` // Catches all undefined fields and must be empty after parsing.
+	}
+	if err := unmarshal(&st); err != nil {
+		return err
+	}
+
+	util.RemoveExtensionKeys(st.Leftovers)
+	if len(st.Leftovers) > 0 {
+		return util.NewExcessKeysErrorFromMap("Parameter", st.Leftovers)
+	}
+
+	*p = Parameter(st.ParameterAlias)
+
+	return nil
+}
+
+// Converts parameters to internal definitions
+func ConvertParameters(parameters []Parameter) []object.Parameter {
+	var result []object.Parameter
+	for _, p := range parameters {
+		op := object.Parameter{
+			Name:        p.Name,
+			DisplayName: goutil.StringOrEmpty(p.DisplayName),
+			Description: goutil.StringOrEmpty(p.Description),
+			Value:       goutil.StringOrEmpty(p.Value),
+		}
+
+		if p.Required != nil {
+			op.Required = *p.Required
+		}
+
+		result = append(result, op)
+	}
+	return result
+}
+
 type VersionString string
 
 func (vs *VersionString) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -343,10 +478,127 @@ func (vs *VersionString) UnmarshalYAML(unmarshal func(interface{}) error) error
 	return nil
 }
 
+// Include is either a string with path or URL or a mapping with one of them and a checksum
+type Include struct {
+	Path   string  `yaml:"path,omitempty" description:"Path to a local file, relative to the directory of the including file."`
+	URL    string  `yaml:"url,omitempty" description:"http or https URL of a remote file."`
+	Sha256 *string `yaml:"sha256,omitempty" description:"Hex encoded sha256 checksum of the file, the file isn't used if it doesn't match. Required for remote files."`
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func (i *Include) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var location string
+	if err := unmarshal(&location); err == nil {
+		if isURL(location) {
+			*i = Include{URL: location}
+		} else {
+			*i = Include{Path: location}
+		}
+		return nil
+	}
+
+	type IncludeAlias Include
+	var st struct {
+		IncludeAlias `yaml:",inline"`
+		Leftovers    map[string]interface{} `yaml:",inline"` // Catches all undefined fields and must be empty after parsing.
+	}
+	err := unmarshal(&st)
+	if err != nil {
+		return err
+	}
+
+	util.RemoveExtensionKeys(st.Leftovers)
+	if len(st.Leftovers) > 0 {
+		return util.NewExcessKeysErrorFromMap("Include", st.Leftovers)
+	}
+
+	switch {
+	case st.Path == "" && st.URL == "":
+		return fmt.Errorf("include has to have either 'path' or 'url'")
+	case st.Path != "" && st.URL != "":
+		return fmt.Errorf("include can't have both 'path' %q and 'url' %q", st.Path, st.URL)
+	case st.URL != "" && !isURL(st.URL):
+		return fmt.Errorf("include url %q: only http and https are supported", st.URL)
+	case st.Path != "" && isURL(st.Path):
+		return fmt.Errorf("include path %q: use 'url' for remote files", st.Path)
+	}
+
+	*i = Include(st.IncludeAlias)
+
+	return nil
+}
+
+func (i Include) MarshalYAML() (interface{}, error) {
+	// short form when there is nothing but the location
+	if i.Sha256 == nil {
+		if i.URL != "" {
+			return i.URL, nil
+		}
+		return i.Path, nil
+	}
+
+	type IncludeAlias Include
+	return IncludeAlias(i), nil
+}
+
+func ConvertIncludes(includes []Include) []object.Include {
+	var result []object.Include
+	for _, i := range includes {
+		include := object.Include{
+			Location: i.Path,
+		}
+		if i.URL != "" {
+			include.Location = i.URL
+			include.IsURL = true
+		}
+		if i.Sha256 != nil {
+			include.Sha256 = *i.Sha256
+		}
+		result = append(result, include)
+	}
+	return result
+}
+
+// Services and volumes in profile only patch the ones defined at the top level
+// so the required fields are not checked
+type Profile struct {
+	Services []Service `yaml:"services,omitempty" description:"Services merged over the ones with the same name, the others are added."`
+	Volumes  []Volume  `yaml:"volumes,omitempty" description:"Volumes merged over the ones with the same name, the others are added."`
+}
+
+func (p *Profile) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type ProfileAlias Profile
+	var st struct {
+		ProfileAlias `yaml:",inline"`
+		Leftovers    map[string]interface{} `yaml:",inline"` // Catches all undefined fields and must be empty after parsing.
+	}
+	err := unmarshal(&st)
+	if err != nil {
+		return err
+	}
+
+	util.RemoveExtensionKeys(st.Leftovers)
+	if len(st.Leftovers) > 0 {
+		return util.NewExcessKeysErrorFromMap("Profile", st.Leftovers)
+	}
+
+	*p = Profile(st.ProfileAlias)
+
+	return nil
+}
+
 type OpenCompose struct {
-	Version  VersionString `yaml:"version"`
-	Services []Service     `yaml:"services"`
-	Volumes  []Volume      `yaml:"volumes,omitempty"`
+	Version VersionString `yaml:"version" description:"Version of the OpenCompose format."`
+	// includes are resolved before the file is decoded
+	Include    []Include          `yaml:"include,omitempty" description:"Other OpenCompose files, as paths or URLs, that are merged before this one."`
+	Services   []Service          `yaml:"services" description:"Services of the application."`
+	Templates  map[string]Service `yaml:"templates,omitempty" description:"Parts that many services have in common, by name. Services use them by extends."`
+	Volumes    []Volume           `yaml:"volumes,omitempty" description:"Persistent volumes the containers can mount."`
+	Parameters []Parameter        `yaml:"parameters,omitempty" description:"Values supplied when the generated artifacts are instantiated."`
+	Profiles   map[string]Profile `yaml:"profiles,omitempty" description:"Patches of services and volumes for different environments, by name. Selected by --profile."`
 }
 
 func (oc *OpenCompose) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -360,6 +612,7 @@ func (oc *OpenCompose) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	util.RemoveExtensionKeys(st.Leftovers)
 	if len(st.Leftovers) > 0 {
 		return util.NewExcessKeysErrorFromMap("OpenCompose", st.Leftovers)
 	}
@@ -369,6 +622,117 @@ func (oc *OpenCompose) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+func convertService(s *Service) object.Service {
+	os := object.Service{
+		Name:   string(s.Name),
+		Labels: object.Labels(s.Labels),
+	}
+
+	os.Replicas = s.Replicas
+	os.Enabled = s.Enabled
+
+	if s.Extends != nil {
+		os.Extends = string(*s.Extends)
+	}
+
+	// convert containers
+	for _, c := range s.Containers {
+		oc := object.Container{
+			Name:    string(c.Name),
+			Image:   string(c.Image),
+			Command: c.Command,
+			Args:    c.Args,
+		}
+		// invalid image is left nil, it's reported by the validation
+		oc.ParsedImage, _ = object.ParseImageRef(oc.Image)
+
+		// convert ports
+		for _, p := range c.Ports {
+			oc.Ports = append(oc.Ports, object.Port{
+				Port: object.PortMapping{
+					ContainerPort: p.Port.ContainerPort,
+					ServicePort:   p.Port.ServicePort,
+				},
+				Type: object.PortType(p.Type),
+				Host: (*string)(p.Host),
+				Path: goutil.StringOrEmpty((*string)(p.Path)),
+			})
+		}
+
+		// convert mounts
+		for _, m := range c.Mounts {
+			mount := object.Mount{
+				VolumeRef: string(m.VolumeRef),
+				MountPath: string(m.MountPath),
+			}
+
+			if m.VolumeSubPath != nil {
+				mount.VolumeSubPath = string(*m.VolumeSubPath)
+			}
+
+			if m.ReadOnly != nil {
+				mount.ReadOnly = *m.ReadOnly
+			}
+
+			oc.Mounts = append(oc.Mounts, mount)
+		}
+
+		// convert env
+		for _, e := range c.Env {
+			oc.Environment = append(oc.Environment, object.EnvVariable{
+				Key:   e.Key,
+				Value: e.Value,
+			})
+		}
+
+		// convert build
+		if c.Build != nil {
+			oc.Build = &object.Build{
+				Git: object.GitSource{
+					URL:        c.Build.Git.URL,
+					Ref:        goutil.StringOrEmpty(c.Build.Git.Ref),
+					ContextDir: goutil.StringOrEmpty(c.Build.Git.ContextDir),
+				},
+				Dockerfile: goutil.StringOrEmpty(c.Build.Dockerfile),
+			}
+
+			for _, a := range c.Build.Args {
+				oc.Build.Args = append(oc.Build.Args, object.EnvVariable{
+					Key:   a.Key,
+					Value: a.Value,
+				})
+			}
+		}
+
+		os.Containers = append(os.Containers, oc)
+	}
+
+	// Add emptyDirVolumes
+	for _, emptydir := range s.EmptyDirVolumes {
+		os.EmptyDirVolumes = append(os.EmptyDirVolumes, object.EmptyDirVolume{
+			Name: string(emptydir.Name),
+		})
+	}
+
+	return os
+}
+
+// TODO: remove the redundant sting conversion
+func convertVolume(v *Volume) object.Volume {
+	ov := object.Volume{
+		Name:       string(v.Name),
+		Size:       v.Size,
+		AccessMode: v.AccessMode,
+	}
+
+	if v.StorageClass != nil {
+		storageClass := string(*v.StorageClass)
+		ov.StorageClass = &storageClass
+	}
+
+	return ov
+}
+
 type Decoder struct{}
 
 // Unmarshals OpenCompose file into object.OpenCompose struct
@@ -379,18 +743,23 @@ type Decoder struct{}
 // https://github.com/golang/go/issues/15314 so hopefully yaml gets something similar
 // otherwise we have to ditch the decoder and write our own using reflect
 func (d *Decoder) Decode(data []byte) (*object.OpenCompose, error) {
+	return d.decode(data, true)
+}
+
+// Unmarshals OpenCompose file that is merged over other files into object.OpenCompose struct
+// Required fields are not checked because the file is expected to set only the fields it
+// overrides; the merged object still has to pass object.OpenCompose.Validate
+func (d *Decoder) DecodeOverride(data []byte) (*object.OpenCompose, error) {
+	return d.decode(data, false)
+}
+
+func (d *Decoder) decode(data []byte, checkRequired bool) (*object.OpenCompose, error) {
 	var v1 OpenCompose
 	// TODO: check for excess fields (see above)
-	err := yaml.Unmarshal(data, &v1)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal OpenCompose: %s", err)
-	}
-
 	// UnmarshalYAML can't check for empty values because in that case it won't get even called
-	// We have to do it here manually
-	err = util.ValidateRequiredFields(v1)
-	if err != nil {
-		return nil, err
+	// so the required fields are checked once the whole file is unmarshaled
+	if err := util.UnmarshalDocument(data, &v1, checkRequired); err != nil {
+		return nil, util.Wrapf(err, "failed to unmarshal OpenCompose")
 	}
 
 	// convert it from our version to internal definitions
@@ -400,88 +769,46 @@ func (d *Decoder) Decode(data []byte) (*object.OpenCompose, error) {
 
 	// convert services
 	for _, s := range v1.Services {
-		os := object.Service{
-			Name:   string(s.Name),
-			Labels: object.Labels(s.Labels),
-		}
-
-		os.Replicas = s.Replicas
-
-		// convert containers
-		for _, c := range s.Containers {
-			oc := object.Container{
-				Name:  string(c.Name),
-				Image: string(c.Image),
-			}
-
-			// convert ports
-			for _, p := range c.Ports {
-				oc.Ports = append(oc.Ports, object.Port{
-					Port: object.PortMapping{
-						ContainerPort: p.Port.ContainerPort,
-						ServicePort:   p.Port.ServicePort,
-					},
-					Type: object.PortType(p.Type),
-					Host: (*string)(p.Host),
-					Path: goutil.StringOrEmpty((*string)(p.Path)),
-				})
-			}
-
-			// convert mounts
-			for _, m := range c.Mounts {
-				mount := object.Mount{
-					VolumeRef: string(m.VolumeRef),
-					MountPath: string(m.MountPath),
-				}
-
-				if m.VolumeSubPath != nil {
-					mount.VolumeSubPath = string(*m.VolumeSubPath)
-				}
-
-				if m.ReadOnly != nil {
-					mount.ReadOnly = *m.ReadOnly
-				}
+		openCompose.Services = append(openCompose.Services, convertService(&s))
+	}
 
-				oc.Mounts = append(oc.Mounts, mount)
-			}
+	// convert volumes
+	for _, v := range v1.Volumes {
+		openCompose.Volumes = append(openCompose.Volumes, convertVolume(&v))
+	}
 
-			// convert env
-			for _, e := range c.Env {
-				oc.Environment = append(oc.Environment, object.EnvVariable{
-					Key:   e.Key,
-					Value: e.Value,
-				})
-			}
+	openCompose.Include = ConvertIncludes(v1.Include)
 
-			os.Containers = append(os.Containers, oc)
+	// convert templates
+	for name, t := range v1.Templates {
+		if t.Name != "" {
+			return nil, fmt.Errorf("template %q: can't have 'name', it is taken from the service extending it", name)
 		}
 
-		// Add emptyDirVolumes
-		for _, emptydir := range s.EmptyDirVolumes {
-			os.EmptyDirVolumes = append(os.EmptyDirVolumes, object.EmptyDirVolume{
-				Name: string(emptydir.Name),
-			})
+		if openCompose.Templates == nil {
+			openCompose.Templates = make(map[string]object.Service)
 		}
-
-		openCompose.Services = append(openCompose.Services, os)
+		openCompose.Templates[name] = convertService(&t)
 	}
 
-	// convert volumes
-	// TODO: remove the redundant sting conversion
-	for _, v := range v1.Volumes {
-		ov := object.Volume{
-			Name:       string(v.Name),
-			Size:       v.Size,
-			AccessMode: v.AccessMode,
+	// convert profiles
+	for name, p := range v1.Profiles {
+		op := object.Profile{}
+		for _, s := range p.Services {
+			op.Services = append(op.Services, convertService(&s))
 		}
-
-		if v.StorageClass != nil {
-			storageClass := string(*v.StorageClass)
-			ov.StorageClass = &storageClass
+		for _, v := range p.Volumes {
+			op.Volumes = append(op.Volumes, convertVolume(&v))
 		}
 
-		openCompose.Volumes = append(openCompose.Volumes, ov)
+		if openCompose.Profiles == nil {
+			openCompose.Profiles = make(map[string]object.Profile)
+		}
+		openCompose.Profiles[name] = op
 	}
 
+	// convert parameters
+	openCompose.Parameters = ConvertParameters(v1.Parameters)
+
 	return openCompose, nil
 }
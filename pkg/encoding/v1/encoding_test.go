@@ -275,11 +275,19 @@ value: "value"
 		},
 
 		{
-			"Failed value is not given",
+			"Success value is empty",
 			true,
 			`
 name: KEY
 value: ""
+`,
+			&EnvVariable{Key: "KEY", Value: ""},
+		},
+		{
+			"Success value is not given",
+			true,
+			`
+name: KEY
 `,
 			&EnvVariable{Key: "KEY", Value: ""},
 		},
@@ -412,6 +420,93 @@ readOnly: true
 	}
 }
 
+func TestBuild_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Succeed  bool
+		RawBuild string
+		Build    *Build
+	}{
+		{
+			"All fields given",
+			true, `
+git:
+  url: https://github.com/foo/bar.git
+  ref: master
+  contextDir: app
+dockerfile: Dockerfile.prod
+args:
+- name: FOO
+  value: bar
+`,
+			&Build{
+				Git: GitSource{
+					URL:        "https://github.com/foo/bar.git",
+					Ref:        goutil.StringAddr("master"),
+					ContextDir: goutil.StringAddr("app"),
+				},
+				Dockerfile: goutil.StringAddr("Dockerfile.prod"),
+				Args: []EnvVariable{
+					{Key: "FOO", Value: "bar"},
+				},
+			},
+		},
+
+		{
+			"Optional fields not given",
+			true, `
+git:
+  url: https://github.com/foo/bar.git
+`,
+			&Build{
+				Git: GitSource{
+					URL: "https://github.com/foo/bar.git",
+				},
+			},
+		},
+
+		{
+			"Giving an extra field in build",
+			false, `
+git:
+  url: https://github.com/foo/bar.git
+strategy: docker
+`,
+			nil,
+		},
+
+		{
+			"Giving an extra field in git",
+			false, `
+git:
+  url: https://github.com/foo/bar.git
+branch: master
+`,
+			nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var build Build
+			err := yaml.Unmarshal([]byte(test.RawBuild), &build)
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("failed to unmarshal 'Build': %#v\nerror: %v", test.RawBuild, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Fatalf("Expected %#v to fail, but succeeded! Build object looks like: %#v", test.RawBuild, build)
+			}
+
+			if !reflect.DeepEqual(build, *test.Build) {
+				t.Fatalf("Expected %#v\ngot %#v", *test.Build, build)
+			}
+		})
+	}
+}
+
 func TestEmptyDirVolume_UnmarshalYAML(t *testing.T) {
 
 	tests := []struct {
@@ -771,8 +866,9 @@ volumes:
 						Name: "frontend",
 						Containers: []object.Container{
 							{
-								Name:  containerName,
-								Image: "tomaskral/kompose-demo-frontend:test",
+								Name:        containerName,
+								Image:       "tomaskral/kompose-demo-frontend:test",
+								ParsedImage: mustParseImageRef("tomaskral/kompose-demo-frontend:test"),
 								Environment: []object.EnvVariable{
 									{
 										Key:   "KEY",
@@ -844,8 +940,9 @@ services:
 						Name: "helloworld",
 						Containers: []object.Container{
 							{
-								Name:  containerName,
-								Image: "tomaskral/nonroot-nginx",
+								Name:        containerName,
+								Image:       "tomaskral/nonroot-nginx",
+								ParsedImage: mustParseImageRef("tomaskral/nonroot-nginx"),
 								Ports: []object.Port{
 									{
 										Port: object.PortMapping{
@@ -865,6 +962,160 @@ services:
 			true, `
 version: 0.1-dev
 services:
+- name: helloworld
+  containers:
+  - image: tomaskral/nonroot-nginx
+    name: test
+- name: debug
+  enabled: false
+  containers:
+  - image: busybox
+    name: test
+profiles:
+  prod:
+    services:
+    - name: helloworld
+      replicas: 3
+      containers:
+      - name: test
+        image: tomaskral/nonroot-nginx:1.0
+    volumes:
+    - name: data
+      size: 10Gi
+`,
+			&object.OpenCompose{
+				Version: Version,
+				Services: []object.Service{
+					{
+						Name: "helloworld",
+						Containers: []object.Container{
+							{
+								Name:        containerName,
+								Image:       "tomaskral/nonroot-nginx",
+								ParsedImage: mustParseImageRef("tomaskral/nonroot-nginx"),
+							},
+						},
+					},
+					{
+						Name:    "debug",
+						Enabled: goutil.BoolAddr(false),
+						Containers: []object.Container{
+							{
+								Name:        containerName,
+								Image:       "busybox",
+								ParsedImage: mustParseImageRef("busybox"),
+							},
+						},
+					},
+				},
+				Profiles: map[string]object.Profile{
+					"prod": {
+						Services: []object.Service{
+							{
+								Name:     "helloworld",
+								Replicas: goutil.Int32Addr(3),
+								Containers: []object.Container{
+									{
+										Name:        containerName,
+										Image:       "tomaskral/nonroot-nginx:1.0",
+										ParsedImage: mustParseImageRef("tomaskral/nonroot-nginx:1.0"),
+									},
+								},
+							},
+						},
+						Volumes: []object.Volume{
+							{
+								Name: "data",
+								Size: "10Gi",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			false, `
+version: 0.1-dev
+services:
+- name: helloworld
+  containers:
+  - image: tomaskral/nonroot-nginx
+    name: test
+profiles:
+  prod:
+    replicas: 3
+`,
+			nil,
+		},
+		{
+			true, `
+version: 0.1-dev
+x-labels: &labels
+  runtime: java
+templates:
+  java:
+    labels: *labels
+    containers:
+    - name: test
+      image: base/java
+services:
+- name: orders
+  extends: java
+  x-owner: team-a
+`,
+			&object.OpenCompose{
+				Version: Version,
+				Services: []object.Service{
+					{
+						Name:    "orders",
+						Extends: "java",
+					},
+				},
+				Templates: map[string]object.Service{
+					"java": {
+						Labels: object.Labels{"runtime": "java"},
+						Containers: []object.Container{
+							{
+								Name:        containerName,
+								Image:       "base/java",
+								ParsedImage: mustParseImageRef("base/java"),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			false, `
+version: 0.1-dev
+templates:
+  java:
+    name: java
+    containers:
+    - name: test
+      image: base/java
+services:
+- name: orders
+  extends: java
+`,
+			nil,
+		},
+		{
+			false, `
+version: 0.1-dev
+services:
+- name: orders
+  owner: team-a
+  containers:
+  - name: test
+    image: base/java
+`,
+			nil,
+		},
+		{
+			true, `
+version: 0.1-dev
+services:
 - name: helloworld
   containers:
   - image: tomaskral/nonroot-nginx
@@ -880,8 +1131,9 @@ services:
 						Name: "helloworld",
 						Containers: []object.Container{
 							{
-								Name:  containerName,
-								Image: "tomaskral/nonroot-nginx",
+								Name:        containerName,
+								Image:       "tomaskral/nonroot-nginx",
+								ParsedImage: mustParseImageRef("tomaskral/nonroot-nginx"),
 								Ports: []object.Port{
 									{
 										Port: object.PortMapping{
@@ -917,8 +1169,9 @@ services:
 						Name: "helloworld",
 						Containers: []object.Container{
 							{
-								Name:  containerName,
-								Image: "tomaskral/nonroot-nginx",
+								Name:        containerName,
+								Image:       "tomaskral/nonroot-nginx",
+								ParsedImage: mustParseImageRef("tomaskral/nonroot-nginx"),
 								Ports: []object.Port{
 									{
 										Port: object.PortMapping{
@@ -984,21 +1237,6 @@ volumes:
 			false, `
 version: 0.1-dev
 services:
-- name: frontend
-  containers:
-  - image: tomaskral/kompose-demo-frontend:test
-    name: test
-    env:
-    - name: KEY
-      value: value
-    - name: KEY2
-`,
-			nil,
-		},
-		{
-			false, `
-version: 0.1-dev
-services:
 - name: frontend
   containers:
   - image: tomaskral/kompose-demo-frontend:test
@@ -1027,7 +1265,7 @@ services:
 			nil,
 		},
 		{
-			false, `
+			true, `
 version: 0.1-dev
 services:
 - name: frontend
@@ -1039,8 +1277,31 @@ services:
       value: value
     - name: KEY2
       value:
+    - name: KEY3
+      value: ""
+    - name: KEY4
 `,
-			nil,
+			&object.OpenCompose{
+				Version: Version,
+				Services: []object.Service{
+					{
+						Name: "frontend",
+						Containers: []object.Container{
+							{
+								Name:        containerName,
+								Image:       "tomaskral/kompose-demo-frontend:test",
+								ParsedImage: mustParseImageRef("tomaskral/kompose-demo-frontend:test"),
+								Environment: []object.EnvVariable{
+									{Key: "KEY", Value: "value"},
+									{Key: "KEY2", Value: ""},
+									{Key: "KEY3", Value: ""},
+									{Key: "KEY4", Value: ""},
+								},
+							},
+						},
+					},
+				},
+			},
 		},
 		{
 			false, `
@@ -1076,8 +1337,9 @@ volumes: []
 						Name: "frontend",
 						Containers: []object.Container{
 							{
-								Name:  containerName,
-								Image: "tomaskral/kompose-demo-frontend:test",
+								Name:        containerName,
+								Image:       "tomaskral/kompose-demo-frontend:test",
+								ParsedImage: mustParseImageRef("tomaskral/kompose-demo-frontend:test"),
 								Environment: []object.EnvVariable{
 									{
 										Key:   "KEY",
@@ -1139,14 +1401,73 @@ services:
 						Replicas: goutil.Int32Addr(2),
 						Containers: []object.Container{
 							{
-								Name:  containerName,
-								Image: "tomaskral/nonroot-nginx",
+								Name:        containerName,
+								Image:       "tomaskral/nonroot-nginx",
+								ParsedImage: mustParseImageRef("tomaskral/nonroot-nginx"),
+							},
+						},
+					},
+				},
+			},
+		},
+		{ // JSON document
+			true, `
+{
+	"version": "0.1-dev",
+	"services": [
+		{
+			"name": "helloworld",
+			"replicas": 2,
+			"containers": [
+				{
+					"name": "test",
+					"image": "tomaskral/nonroot-nginx",
+					"ports": [{"port": 80}, {"port": "8080:80"}]
+				}
+			]
+		}
+	]
+}
+`,
+			&object.OpenCompose{
+				Version: Version,
+				Services: []object.Service{
+					{
+						Name:     "helloworld",
+						Replicas: goutil.Int32Addr(2),
+						Containers: []object.Container{
+							{
+								Name:        containerName,
+								Image:       "tomaskral/nonroot-nginx",
+								ParsedImage: mustParseImageRef("tomaskral/nonroot-nginx"),
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 80}},
+									{Port: object.PortMapping{ContainerPort: 8080, ServicePort: 80}},
+								},
 							},
 						},
 					},
 				},
 			},
 		},
+		{ // JSON document, replicas as a string
+			false, `
+{"version": "0.1-dev", "services": [{"name": "helloworld", "replicas": "2", "containers": [{"name": "test", "image": "nginx"}]}]}
+`,
+			nil,
+		},
+		{ // JSON document, excess key
+			false, `
+{"version": "0.1-dev", "services": [{"name": "helloworld", "owner": "me", "containers": [{"name": "test", "image": "nginx"}]}]}
+`,
+			nil,
+		},
+		{ // JSON document, required value "image" is not given
+			false, `
+{"version": "0.1-dev", "services": [{"name": "helloworld", "containers": [{"name": "test"}]}]}
+`,
+			nil,
+		},
 		{ // testing mounts, one required value "mountPath" -  is not given
 			false, `
 version: 0.1-dev
@@ -1201,8 +1522,9 @@ services:
 						Replicas: goutil.Int32Addr(2),
 						Containers: []object.Container{
 							{
-								Name:  containerName,
-								Image: "tomaskral/nonroot-nginx",
+								Name:        containerName,
+								Image:       "tomaskral/nonroot-nginx",
+								ParsedImage: mustParseImageRef("tomaskral/nonroot-nginx"),
 							},
 						},
 						Labels: object.Labels{
@@ -1215,6 +1537,32 @@ services:
 				},
 			},
 		},
+		// invalid image is reported by the validation together with the other errors
+		{
+			true,
+			`
+version: 0.1-dev
+services:
+- name: helloworld
+  containers:
+  - image: Nginx:1.11
+    name: test
+`,
+			&object.OpenCompose{
+				Version: Version,
+				Services: []object.Service{
+					{
+						Name: "helloworld",
+						Containers: []object.Container{
+							{
+								Name:  containerName,
+								Image: "Nginx:1.11",
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1239,3 +1587,12 @@ services:
 		})
 	}
 }
+
+// Returns the parsed image the decoder sets on the containers
+func mustParseImageRef(image string) *object.ImageRef {
+	ref, err := object.ParseImageRef(image)
+	if err != nil {
+		panic(err)
+	}
+	return ref
+}
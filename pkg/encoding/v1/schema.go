@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+// Returns JSON Schema of the format, editors can use it for completion and validation
+func Schema() (*util.Schema, error) {
+	s, err := util.GenerateSchema(reflect.TypeOf(OpenCompose{}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate schema: %s", err)
+	}
+
+	s.Schema = util.SchemaDraft
+	s.Title = fmt.Sprintf("OpenCompose %s", Version)
+	return s, nil
+}
+
+func (rn ResourceName) JSONSchema(generated *util.Schema) *util.Schema {
+	return util.WithReferences(util.ResourceNameSchema())
+}
+
+func (ir ImageRef) JSONSchema(generated *util.Schema) *util.Schema {
+	return util.WithReferences(&util.Schema{
+		Type:    "string",
+		Pattern: object.ImageRefPattern,
+	})
+}
+
+// Port is either a number or "containerPort:servicePort"
+func (pm PortMapping) JSONSchema(generated *util.Schema) *util.Schema {
+	return util.WithReferences(&util.Schema{
+		AnyOf: []*util.Schema{
+			{Type: "integer"},
+			{Type: "string", Pattern: `^[0-9]+(:[0-9]+)?$`},
+		},
+	})
+}
+
+func (pt PortType) JSONSchema(generated *util.Schema) *util.Schema {
+	return &util.Schema{
+		Type: "string",
+		Enum: []string{"internal", "external"},
+	}
+}
+
+func (p Port) JSONSchema(generated *util.Schema) *util.Schema {
+	generated.Dependencies = map[string][]string{"path": {"host"}}
+	return generated
+}
+
+// Service extending a template gets the containers from it
+func (s Service) JSONSchema(generated *util.Schema) *util.Schema {
+	if len(generated.Required) > 0 {
+		generated.Required = []string{"name"}
+		generated.AnyOf = []*util.Schema{
+			{Required: []string{"containers"}},
+			{Required: []string{"extends"}},
+		}
+	}
+
+	// Kubernetes Services are named after the service, their names are DNS-1035 labels
+	name := generated.Properties["name"]
+	serviceNameMaxLength := 63
+	generated.Properties["name"] = util.WithReferences(&util.Schema{
+		Description: name.Description,
+		Type:        "string",
+		Pattern:     `^[a-z]([-a-z0-9]*[a-z0-9])?$`,
+		MaxLength:   &serviceNameMaxLength,
+	})
+
+	// replicas is an integer or a reference
+	var minimum int64
+	generated.Properties["replicas"].AnyOf[0].Minimum = &minimum
+
+	return generated
+}
+
+func (v Volume) JSONSchema(generated *util.Schema) *util.Schema {
+	// same as Kubernetes resource.Quantity
+	size := generated.Properties["size"]
+	size.Pattern = `^[+-]?[0-9.]+([eEinumkKMGTP]*[-+]?[0-9]*)$`
+	generated.Properties["size"] = util.WithReferences(size)
+
+	accessMode := generated.Properties["accessMode"]
+	accessMode.Enum = object.VolumeAccessModes
+	generated.Properties["accessMode"] = util.WithReferences(accessMode)
+
+	return generated
+}
+
+func (p Parameter) JSONSchema(generated *util.Schema) *util.Schema {
+	generated.Properties["name"].Pattern = `^[A-Za-z_][A-Za-z0-9_]*$`
+	return generated
+}
+
+func (vs VersionString) JSONSchema(generated *util.Schema) *util.Schema {
+	return &util.Schema{
+		Type: "string",
+		Enum: []string{Version},
+	}
+}
+
+// Include is either a path or URL or a mapping with one of them
+func (i Include) JSONSchema(generated *util.Schema) *util.Schema {
+	generated.AnyOf = []*util.Schema{
+		{Required: []string{"path"}},
+		{Required: []string{"url"}},
+	}
+	return &util.Schema{
+		AnyOf: []*util.Schema{
+			{Type: "string"},
+			generated,
+		},
+	}
+}
+
+func (oc OpenCompose) JSONSchema(generated *util.Schema) *util.Schema {
+	// templates get the name from the service extending them
+	if templates, ok := generated.Properties["templates"].AdditionalProperties.(*util.Schema); ok {
+		delete(templates.Properties, "name")
+	}
+	return generated
+}
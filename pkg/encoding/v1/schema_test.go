@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
+)
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %s", err)
+	}
+
+	if !reflect.DeepEqual(schema.Required, []string{"version", "services"}) {
+		t.Errorf("Expected required fields %v, got %v", []string{"version", "services"}, schema.Required)
+	}
+
+	if !reflect.DeepEqual(schema.Properties["version"].Enum, []string{Version}) {
+		t.Errorf("Expected version to be %q, got %v", Version, schema.Properties["version"].Enum)
+	}
+
+	service := schema.Properties["services"].Items
+	if !reflect.DeepEqual(service.Required, []string{"name"}) || len(service.AnyOf) != 2 ||
+		!reflect.DeepEqual(service.AnyOf[0].Required, []string{"containers"}) ||
+		!reflect.DeepEqual(service.AnyOf[1].Required, []string{"extends"}) {
+		t.Errorf("Expected service to require name and either containers or extends, got %#v", service)
+	}
+
+	port := service.Properties["containers"].Items.Properties["ports"].Items
+	if !reflect.DeepEqual(port.Properties["type"].Enum, []string{"internal", "external"}) {
+		t.Errorf("Expected port type to be either internal or external, got %v", port.Properties["type"].Enum)
+	}
+
+	volume := schema.Properties["volumes"].Items
+	if !reflect.DeepEqual(volume.Properties["accessMode"].AnyOf[0].Enum, []string{"ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany"}) {
+		t.Errorf("Unexpected accessMode schema: %#v", volume.Properties["accessMode"].AnyOf[0])
+	}
+
+	if name := service.Properties["name"].AnyOf[0]; name.Pattern == "" || name.MaxLength == nil || *name.MaxLength != 63 ||
+		regexp.MustCompile(name.Pattern).MatchString("my.svc") || regexp.MustCompile(name.Pattern).MatchString("1api") {
+		t.Errorf("Expected name to be a DNS-1035 label, got %#v", name)
+	}
+
+	template, ok := schema.Properties["templates"].AdditionalProperties.(*util.Schema)
+	if !ok {
+		t.Fatalf("Expected schema of templates, got %#v", schema.Properties["templates"].AdditionalProperties)
+	}
+	if _, ok := template.Properties["name"]; ok || template.AnyOf != nil {
+		t.Errorf("Expected template without name and required fields, got %#v", template)
+	}
+}
+
+// Descriptions are shown by 'opencompose explain' so every field has to have one
+func TestSchema_Descriptions(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %s", err)
+	}
+
+	var check func(s *util.Schema, path string)
+	check = func(s *util.Schema, path string) {
+		object := s.Object()
+		if object == nil {
+			return
+		}
+		for name, property := range object.Properties {
+			if property.Description == "" {
+				t.Errorf("Field %q doesn't have description", path+name)
+			}
+			check(property, path+name+".")
+		}
+	}
+	check(schema, "")
+}
@@ -1,9 +1,7 @@
 package encoding
 
 import (
-	"fmt"
-
-	"gopkg.in/yaml.v2"
+	"github.com/redhat-developer/opencompose/pkg/encoding/util"
 )
 
 type Version struct {
@@ -12,9 +10,9 @@ type Version struct {
 
 func GetVersion(data []byte) (string, error) {
 	var v Version
-	err := yaml.Unmarshal(data, &v)
+	err := util.Unmarshal(data, &v)
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal OpenCompose version: %s", err)
+		return "", util.Wrapf(err, "failed to unmarshal OpenCompose version")
 	}
 	return v.Version, nil
 }
@@ -5,6 +5,14 @@ func StringAddr(s string) *string {
 	return &s
 }
 
+// Inverse of StringOrEmpty, returns nil for empty string
+func StringAddrOrNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func StringOrEmpty(p *string) string {
 	if p != nil {
 		return *p
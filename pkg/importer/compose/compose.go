@@ -0,0 +1,836 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/importer"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/pkg/util/validation"
+)
+
+// Versions of docker-compose files that can be imported, files without version follow the Compose Specification
+var versionRegexp = regexp.MustCompile(`^[23](\.[0-9]+)?$`)
+
+// Characters that can't be in names of the Kubernetes objects
+var invalidNameCharsRegexp = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Why some of the keys of services are not imported
+var unsupportedReasons = map[string]string{
+	"container_name": "the container is named after the service",
+	"depends_on":     "services are started in any order",
+	"env_file":       "set the variables in env instead",
+	"links":          "services are reachable by their names",
+	"networks":       "all the services share one network",
+}
+
+// docker-compose file, services and volumes are decoded in the order they are written
+type composeFile struct {
+	Version   interface{}            `yaml:"version"`
+	Services  yaml.MapSlice          `yaml:"services"`
+	Volumes   yaml.MapSlice          `yaml:"volumes"`
+	Leftovers map[string]interface{} `yaml:",inline"`
+}
+
+type converter struct {
+	problems importer.Problems
+	// names of the imported volumes by their names in docker-compose
+	volumes map[string]string
+	// names the imported services and volumes have or are going to have
+	serviceNames map[string]bool
+	volumeNames  map[string]bool
+}
+
+// Imports docker-compose file of version 2 or 3. Returns OpenCompose of the current spec version
+// together with the parts of the file that can't be represented in it and were left out or changed.
+func Import(data []byte) (*object.OpenCompose, []importer.Problem, error) {
+	var file composeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal docker-compose file: %s", err)
+	}
+
+	version, _ := scalar(file.Version)
+	if version != "" && !versionRegexp.MatchString(version) {
+		return nil, nil, fmt.Errorf("unsupported docker-compose version %q, only versions 2 and 3 can be imported", version)
+	}
+	if file.Services == nil {
+		return nil, nil, fmt.Errorf("%s", "there are no services, files of version 1 can't be imported")
+	}
+
+	c := &converter{
+		volumes:      make(map[string]string),
+		serviceNames: reservedNames(file.Services),
+		volumeNames:  reservedNames(file.Volumes),
+	}
+	o := &object.OpenCompose{Version: encoding.CurrentVersion}
+
+	for _, key := range sortedKeys(file.Leftovers) {
+		if !strings.HasPrefix(key, "x-") {
+			c.unsupported(importer.Child("", key), key)
+		}
+	}
+
+	// volumes go first so the mounts can refer to them
+	for _, item := range file.Volumes {
+		name, _ := scalar(item.Key)
+		o.Volumes = append(o.Volumes, c.importVolume(name, item.Value))
+	}
+
+	for _, item := range file.Services {
+		name, _ := scalar(item.Key)
+		o.Services = append(o.Services, c.importService(name, item.Value))
+	}
+
+	return o, c.problems, nil
+}
+
+func (c *converter) unsupported(path string, key string) {
+	if reason, ok := unsupportedReasons[key]; ok {
+		c.problems.Add(path, "%q is not supported, %s", key, reason)
+		return
+	}
+	c.problems.Add(path, "%q is not supported", key)
+}
+
+// Returns the name turned into a name that Kubernetes objects can have, e.g. "web_1" into "web-1"
+func kubernetesName(name string) string {
+	renamed := strings.Trim(invalidNameCharsRegexp.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(renamed) > validation.DNS1123LabelMaxLength {
+		renamed = strings.Trim(renamed[:validation.DNS1123LabelMaxLength], "-")
+	}
+	return renamed
+}
+
+// Returns the names of the items that are kept as they are, the renamed items can't take them
+func reservedNames(items yaml.MapSlice) map[string]bool {
+	names := make(map[string]bool)
+	for _, item := range items {
+		if name, _ := scalar(item.Key); kubernetesName(name) == name {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// Returns the name turned into a name that Kubernetes objects can have (see kubernetesName).
+// Renamed names that are taken get a number, e.g. "web_app" is renamed to "web-app-2" when
+// there is "web-app" as well.
+func (c *converter) rename(path string, what string, name string, taken map[string]bool) string {
+	renamed := kubernetesName(name)
+	if renamed == "" || renamed == name {
+		return name
+	}
+
+	base := renamed
+	if taken[renamed] && len(base) > validation.DNS1123LabelMaxLength-3 {
+		base = strings.Trim(base[:validation.DNS1123LabelMaxLength-3], "-")
+	}
+	unique := renamed
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", base, i)
+	}
+	taken[unique] = true
+
+	if unique != renamed {
+		c.problems.Add(path, "%s %q is renamed to %q, %q is the name of another %s", what, name, unique, renamed, what)
+		return unique
+	}
+	c.problems.Add(path, "%s %q is renamed to %q", what, name, renamed)
+	return renamed
+}
+
+func (c *converter) importVolume(name string, value interface{}) object.Volume {
+	path := importer.Child("/volumes", name)
+
+	v := object.Volume{
+		Name:       c.rename(path, "volume", name, c.volumeNames),
+		Size:       importer.DefaultVolumeSize,
+		AccessMode: "ReadWriteOnce",
+	}
+	c.volumes[name] = v.Name
+
+	config, _ := toMap(value)
+	for _, key := range sortedKeys(config) {
+		if key == "external" {
+			c.problems.Add(importer.Child(path, key), "external volumes can't be referenced, a new volume of %s is created instead", importer.DefaultVolumeSize)
+			continue
+		}
+		c.unsupported(importer.Child(path, key), key)
+	}
+
+	return v
+}
+
+func (c *converter) importService(name string, value interface{}) object.Service {
+	path := importer.Child("/services", name)
+
+	s := object.Service{Name: c.rename(path, "service", name, c.serviceNames)}
+	container := object.Container{Name: s.Name}
+
+	config, ok := toMap(value)
+	if !ok {
+		c.problems.Add(path, "%s", "service has to be a mapping")
+	}
+
+	for _, key := range sortedKeys(config) {
+		keyPath := importer.Child(path, key)
+		value := config[key]
+
+		switch key {
+		case "image":
+			container.Image, _ = scalar(value)
+		case "build":
+			c.importBuild(keyPath, value, &container)
+		case "entrypoint":
+			container.Command = c.importCommand(keyPath, value)
+		case "command":
+			container.Args = c.importCommand(keyPath, value)
+		case "environment":
+			container.Environment = c.importEnvironment(keyPath, value)
+		case "ports":
+			c.importPorts(keyPath, value, &container)
+		case "expose":
+			// imported after the published ports which take precedence
+		case "volumes":
+			c.importMounts(keyPath, value, &s, &container)
+		case "labels":
+			s.Labels = c.importLabels(keyPath, value)
+		case "scale":
+			s.Replicas = c.importReplicas(keyPath, value)
+		case "deploy":
+			c.importDeploy(keyPath, value, &s)
+		case "restart":
+			policy, _ := scalar(value)
+			switch policy {
+			case "always", "unless-stopped":
+			default:
+				c.problems.Add(keyPath, "restart policy %q is not supported, containers are always restarted", policy)
+			}
+		default:
+			c.unsupported(keyPath, key)
+		}
+	}
+
+	if value, ok := config["expose"]; ok {
+		c.importExpose(importer.Child(path, "expose"), value, &container)
+	}
+
+	if container.Image == "" {
+		if container.Build != nil {
+			container.Image = s.Name
+			c.problems.Add(path, "image is not set, the built image is named %q", container.Image)
+		} else {
+			c.problems.Add(path, "%s", "image is not set")
+		}
+	}
+
+	s.Containers = []object.Container{container}
+	return s
+}
+
+// Reports whether the build context is a git repository, the same way as docker does
+func isGitContext(context string) bool {
+	url := strings.SplitN(context, "#", 2)[0]
+	switch {
+	case strings.HasPrefix(url, "git://"), strings.HasPrefix(url, "git@"), strings.HasPrefix(url, "github.com/"):
+		return true
+	case strings.HasPrefix(url, "http://"), strings.HasPrefix(url, "https://"):
+		return strings.HasSuffix(url, ".git")
+	}
+	return false
+}
+
+func (c *converter) importBuild(path string, value interface{}, container *object.Container) {
+	build := &object.Build{}
+
+	var context string
+	if config, ok := toMap(value); ok {
+		for _, key := range sortedKeys(config) {
+			switch key {
+			case "context":
+				context, _ = scalar(config[key])
+			case "dockerfile":
+				build.Dockerfile, _ = scalar(config[key])
+			case "args":
+				build.Args = c.importEnvironment(importer.Child(path, key), config[key])
+			default:
+				c.unsupported(importer.Child(path, key), key)
+			}
+		}
+	} else {
+		context, _ = scalar(value)
+	}
+
+	if !isGitContext(context) {
+		c.problems.Add(path, "build context %q is not a git repository, images can be built only from git", context)
+		return
+	}
+
+	// the fragment selects the ref and the directory in the repository, e.g. "#master:docker"
+	parts := strings.SplitN(context, "#", 2)
+	build.Git.URL = parts[0]
+	if strings.HasPrefix(build.Git.URL, "github.com/") {
+		build.Git.URL = "https://" + build.Git.URL
+	}
+	if len(parts) > 1 {
+		fragment := strings.SplitN(parts[1], ":", 2)
+		build.Git.Ref = fragment[0]
+		if len(fragment) > 1 {
+			build.Git.ContextDir = fragment[1]
+		}
+	}
+
+	container.Build = build
+}
+
+// Returns the command in the exec form, string is split into words the same way as by a shell
+func (c *converter) importCommand(path string, value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		var words []string
+		for _, item := range v {
+			word, _ := scalar(item)
+			words = append(words, word)
+		}
+		return words
+	default:
+		command, _ := scalar(v)
+		words, err := splitWords(command)
+		if err != nil {
+			c.problems.Add(path, "%s", err)
+			return nil
+		}
+		return words
+	}
+}
+
+// Splits s into words like a shell does without expanding anything,
+// quotes and backslashes are removed
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word []rune
+	inWord := false
+	var quote rune
+	escaped := false
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			word = append(word, r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word = append(word, r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]):
+				i++
+				word = append(word, runes[i])
+			default:
+				word = append(word, r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, string(word))
+				word, inWord = nil, false
+			}
+		default:
+			word = append(word, r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("command %q: missing closing %c", s, quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("command %q: ends with a backslash", s)
+	}
+	if inWord {
+		words = append(words, string(word))
+	}
+	return words, nil
+}
+
+// Returns variables given as a list of KEY=VALUE or as a mapping. Variables without value
+// take it from the environment docker-compose is run in, they are imported as ${KEY} and reported.
+func (c *converter) importEnvironment(path string, value interface{}) []object.EnvVariable {
+	var env []object.EnvVariable
+	add := func(path string, key string, value string, ok bool) {
+		if !ok {
+			c.problems.Add(path, "variable %q has no value, docker-compose takes it from its environment; it's imported as \"${%s}\" so it's taken from the variables of 'opencompose convert'", key, key)
+			env = append(env, object.EnvVariable{Key: key, Value: "${" + key + "}"})
+			return
+		}
+		env = append(env, object.EnvVariable{Key: key, Value: unescapeDollars(value)})
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for i, item := range v {
+			s, _ := scalar(item)
+			parts := strings.SplitN(s, "=", 2)
+			if len(parts) == 1 {
+				add(importer.Child(path, strconv.Itoa(i)), parts[0], "", false)
+				continue
+			}
+			add(importer.Child(path, strconv.Itoa(i)), parts[0], parts[1], true)
+		}
+	default:
+		m, ok := toMap(v)
+		if !ok {
+			c.problems.Add(path, "%s", "has to be a list or a mapping")
+			return nil
+		}
+		for _, key := range sortedKeys(m) {
+			value, ok := scalar(m[key])
+			add(importer.Child(path, key), key, value, ok)
+		}
+	}
+	return env
+}
+
+// Returns value with the $$ escapes of docker-compose unescaped to $. The escapes before "{" and "$"
+// are kept, OpenCompose needs them so "${" is not taken for a variable reference as well.
+func unescapeDollars(value string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(value); i++ {
+		if value[i] == '$' && i+1 < len(value) && value[i+1] == '$' {
+			i++
+			if i+1 < len(value) && (value[i+1] == '{' || value[i+1] == '$') {
+				buf.WriteString("$$")
+			} else {
+				buf.WriteByte('$')
+			}
+			continue
+		}
+		buf.WriteByte(value[i])
+	}
+	return buf.String()
+}
+
+// Port as it is published by docker-compose
+type composePort struct {
+	HostIP    string
+	Published int
+	Target    int
+	Protocol  string
+}
+
+// Parses number of the port, ranges are not supported
+func parsePortNumber(s string) (int, error) {
+	if strings.Contains(s, "-") {
+		return 0, fmt.Errorf("port range %q is not supported", s)
+	}
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return port, nil
+}
+
+// Parses port in the short syntax "[[HOST_IP:]PUBLISHED:]TARGET[/PROTOCOL]" or in the long syntax
+func parsePort(value interface{}) (composePort, error) {
+	var p composePort
+
+	if config, ok := toMap(value); ok {
+		for _, key := range sortedKeys(config) {
+			s, _ := scalar(config[key])
+			var err error
+			switch key {
+			case "target":
+				p.Target, err = parsePortNumber(s)
+			case "published":
+				p.Published, err = parsePortNumber(s)
+			case "protocol":
+				p.Protocol = s
+			case "host_ip":
+				p.HostIP = s
+			case "mode":
+			default:
+				err = fmt.Errorf("%q is not supported", key)
+			}
+			if err != nil {
+				return p, err
+			}
+		}
+		if p.Target == 0 {
+			return p, fmt.Errorf("%s", "target port is not set")
+		}
+		return p, nil
+	}
+
+	s, _ := scalar(value)
+	if i := strings.Index(s, "/"); i >= 0 {
+		s, p.Protocol = s[:i], s[i+1:]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return p, fmt.Errorf("invalid port %q", s)
+	}
+
+	var err error
+	if p.Target, err = parsePortNumber(parts[len(parts)-1]); err != nil {
+		return p, err
+	}
+	if len(parts) > 1 && parts[len(parts)-2] != "" {
+		if p.Published, err = parsePortNumber(parts[len(parts)-2]); err != nil {
+			return p, err
+		}
+	}
+	if len(parts) == 3 {
+		p.HostIP = parts[0]
+	}
+	return p, nil
+}
+
+// Adds the port to the container unless the container port is already there
+func (c *converter) addPort(path string, container *object.Container, port object.Port) {
+	for _, p := range container.Ports {
+		if p.Port.ContainerPort == port.Port.ContainerPort {
+			c.problems.Add(path, "container port %d is already imported, only one service port can be mapped to it", port.Port.ContainerPort)
+			return
+		}
+	}
+	container.Ports = append(container.Ports, port)
+}
+
+// Imports published ports. Ports published on a host port are made external, ports published
+// only to the local machine or on a random host port are internal.
+func (c *converter) importPorts(path string, value interface{}, container *object.Container) {
+	items, ok := value.([]interface{})
+	if !ok {
+		c.problems.Add(path, "%s", "has to be a list")
+		return
+	}
+
+	for i, item := range items {
+		itemPath := importer.Child(path, strconv.Itoa(i))
+
+		p, err := parsePort(item)
+		if err != nil {
+			c.problems.Add(itemPath, "%s", err)
+			continue
+		}
+		if p.Protocol != "" && p.Protocol != "tcp" {
+			c.problems.Add(itemPath, "%s ports are not supported", p.Protocol)
+			continue
+		}
+
+		port := object.Port{Port: object.PortMapping{ContainerPort: p.Target, ServicePort: p.Target}}
+		if p.Published != 0 {
+			port.Port.ServicePort = p.Published
+			switch p.HostIP {
+			case "127.0.0.1", "localhost", "::1":
+			default:
+				port.Type = object.PortType_External
+			}
+		}
+		c.addPort(itemPath, container, port)
+	}
+}
+
+// Imports exposed ports as internal ones unless they are published too
+func (c *converter) importExpose(path string, value interface{}, container *object.Container) {
+	items, ok := value.([]interface{})
+	if !ok {
+		c.problems.Add(path, "%s", "has to be a list")
+		return
+	}
+
+	for i, item := range items {
+		itemPath := importer.Child(path, strconv.Itoa(i))
+
+		s, _ := scalar(item)
+		protocol := ""
+		if i := strings.Index(s, "/"); i >= 0 {
+			s, protocol = s[:i], s[i+1:]
+		}
+		if protocol != "" && protocol != "tcp" {
+			c.problems.Add(itemPath, "%s ports are not supported", protocol)
+			continue
+		}
+
+		target, err := parsePortNumber(s)
+		if err != nil {
+			c.problems.Add(itemPath, "%s", err)
+			continue
+		}
+
+		published := false
+		for _, p := range container.Ports {
+			if p.Port.ContainerPort == target {
+				published = true
+			}
+		}
+		if !published {
+			container.Ports = append(container.Ports, object.Port{Port: object.PortMapping{ContainerPort: target, ServicePort: target}})
+		}
+	}
+}
+
+// Volume mounted in the container of docker-compose
+type composeMount struct {
+	Type     string
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// Parses mount in the short syntax "[SOURCE:]TARGET[:MODE]" or in the long syntax
+func parseMount(value interface{}) (composeMount, error) {
+	var m composeMount
+
+	if config, ok := toMap(value); ok {
+		for _, key := range sortedKeys(config) {
+			s, _ := scalar(config[key])
+			switch key {
+			case "type":
+				m.Type = s
+			case "source":
+				m.Source = s
+			case "target":
+				m.Target = s
+			case "read_only":
+				m.ReadOnly = s == "true"
+			default:
+				return m, fmt.Errorf("%q is not supported", key)
+			}
+		}
+		if m.Target == "" {
+			return m, fmt.Errorf("%s", "target is not set")
+		}
+		return m, nil
+	}
+
+	s, _ := scalar(value)
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 1:
+		m.Target = parts[0]
+	case 2, 3:
+		m.Source, m.Target = parts[0], parts[1]
+		if len(parts) == 3 {
+			for _, mode := range strings.Split(parts[2], ",") {
+				if mode == "ro" {
+					m.ReadOnly = true
+				}
+			}
+		}
+	default:
+		return m, fmt.Errorf("invalid volume %q", s)
+	}
+
+	m.Type = "volume"
+	if strings.HasPrefix(m.Source, "/") || strings.HasPrefix(m.Source, ".") || strings.HasPrefix(m.Source, "~") {
+		m.Type = "bind"
+	}
+	return m, nil
+}
+
+// Imports mounts of named volumes, anonymous and tmpfs volumes become EmptyDir volumes of the service
+func (c *converter) importMounts(path string, value interface{}, s *object.Service, container *object.Container) {
+	items, ok := value.([]interface{})
+	if !ok {
+		c.problems.Add(path, "%s", "has to be a list")
+		return
+	}
+
+	for i, item := range items {
+		itemPath := importer.Child(path, strconv.Itoa(i))
+
+		m, err := parseMount(item)
+		if err != nil {
+			c.problems.Add(itemPath, "%s", err)
+			continue
+		}
+
+		mount := object.Mount{MountPath: m.Target, ReadOnly: m.ReadOnly}
+		switch {
+		case m.Type == "bind":
+			c.problems.Add(itemPath, "host path %q can't be mounted, use a volume instead", m.Source)
+			continue
+		case m.Type == "volume" && m.Source != "":
+			name, ok := c.volumes[m.Source]
+			if !ok {
+				c.problems.Add(itemPath, "volume %q is not defined in volumes", m.Source)
+				continue
+			}
+			mount.VolumeRef = name
+		case m.Type == "volume" || m.Type == "tmpfs":
+			mount.VolumeRef = c.emptyDirName(s, m.Target)
+			s.EmptyDirVolumes = append(s.EmptyDirVolumes, object.EmptyDirVolume{Name: mount.VolumeRef})
+		default:
+			c.problems.Add(itemPath, "volumes of type %q are not supported", m.Type)
+			continue
+		}
+		container.Mounts = append(container.Mounts, mount)
+	}
+}
+
+// Returns a name for EmptyDir volume mounted at the path that no other volume has, e.g. "var-cache" for /var/cache
+func (c *converter) emptyDirName(s *object.Service, path string) string {
+	base := strings.Trim(invalidNameCharsRegexp.ReplaceAllString(strings.ToLower(path), "-"), "-")
+	if len(base) > validation.DNS1123LabelMaxLength-3 {
+		base = strings.Trim(base[:validation.DNS1123LabelMaxLength-3], "-")
+	}
+	if base == "" {
+		base = "volume"
+	}
+
+	taken := func(name string) bool {
+		if s.EmptyDirVolumeExists(name) {
+			return true
+		}
+		for _, v := range c.volumes {
+			if v == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	name := base
+	for i := 2; taken(name); i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
+
+// Imports labels that are valid Kubernetes labels
+func (c *converter) importLabels(path string, value interface{}) object.Labels {
+	labels := make(object.Labels)
+	add := func(key string, value string) {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			c.problems.Add(importer.Child(path, key), "label %q is not a valid Kubernetes label: %s", key, strings.Join(errs, ", "))
+			return
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			c.problems.Add(importer.Child(path, key), "value of label %q is not a valid Kubernetes label value: %s", key, strings.Join(errs, ", "))
+			return
+		}
+		labels[key] = value
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			s, _ := scalar(item)
+			parts := strings.SplitN(s, "=", 2)
+			if len(parts) == 1 {
+				parts = append(parts, "")
+			}
+			add(parts[0], parts[1])
+		}
+	default:
+		m, ok := toMap(v)
+		if !ok {
+			c.problems.Add(path, "%s", "has to be a list or a mapping")
+			return nil
+		}
+		for _, key := range sortedKeys(m) {
+			s, _ := scalar(m[key])
+			add(key, s)
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
+
+func (c *converter) importReplicas(path string, value interface{}) *int32 {
+	s, _ := scalar(value)
+	replicas, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || replicas < 0 {
+		c.problems.Add(path, "invalid number of replicas %q", s)
+		return nil
+	}
+	return goutil.Int32Addr(int32(replicas))
+}
+
+// Imports replicas of the deploy section of version 3, the rest applies only to swarm
+func (c *converter) importDeploy(path string, value interface{}, s *object.Service) {
+	config, ok := toMap(value)
+	if !ok {
+		c.problems.Add(path, "%s", "has to be a mapping")
+		return
+	}
+
+	for _, key := range sortedKeys(config) {
+		keyPath := importer.Child(path, key)
+		switch key {
+		case "replicas":
+			s.Replicas = c.importReplicas(keyPath, config[key])
+		case "mode":
+			if mode, _ := scalar(config[key]); mode != "replicated" {
+				c.problems.Add(keyPath, "mode %q is not supported", mode)
+			}
+		default:
+			c.unsupported(keyPath, key)
+		}
+	}
+}
+
+// Returns scalar value as a string, false for null and collections
+func scalar(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil, yaml.MapSlice, map[interface{}]interface{}, []interface{}:
+		return "", false
+	case string:
+		return v, true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// Returns mapping decoded from YAML with string keys, mappings nested in yaml.MapSlice are decoded as yaml.MapSlice too
+func toMap(value interface{}) (map[string]interface{}, bool) {
+	result := make(map[string]interface{})
+	switch m := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range m {
+			key, _ := scalar(item.Key)
+			result[key] = item.Value
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			key, _ := scalar(k)
+			result[key] = v
+		}
+	default:
+		return nil, false
+	}
+	return result, true
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
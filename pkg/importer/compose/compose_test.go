@@ -0,0 +1,383 @@
+package compose
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Succeed  bool
+		File     string
+		Object   *object.OpenCompose
+		Problems []string
+	}{
+		{
+			"Version 1",
+			false,
+			`
+web:
+  image: nginx
+`,
+			nil,
+			nil,
+		},
+		{
+			"Unsupported version",
+			false,
+			`
+version: "4"
+services:
+  web:
+    image: nginx
+`,
+			nil,
+			nil,
+		},
+		{
+			"Services",
+			true,
+			`
+version: "3"
+services:
+  web_app:
+    image: nginx:1.11
+    command: nginx -g "daemon off;"
+    environment:
+    - MODE=prod
+    - SECRET
+    ports:
+    - "8080:80"
+    - "127.0.0.1:9090:9090"
+    - "443"
+    - "5000-5005:5000-5005"
+    expose:
+    - 80
+    - 3000
+    depends_on:
+    - db
+    deploy:
+      replicas: 2
+  db:
+    image: mysql:5.7
+    entrypoint: ["docker-entrypoint.sh", "mysqld"]
+    environment:
+      MYSQL_PORT: 3306
+      TZ:
+    labels:
+      com.example.team: db
+      description: The database
+    restart: on-failure
+networks:
+  default:
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name:     "web-app",
+						Replicas: goutil.Int32Addr(2),
+						Containers: []object.Container{
+							{
+								Name:  "web-app",
+								Image: "nginx:1.11",
+								Args:  []string{"nginx", "-g", "daemon off;"},
+								Environment: []object.EnvVariable{
+									{Key: "MODE", Value: "prod"},
+									{Key: "SECRET", Value: "${SECRET}"},
+								},
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_External},
+									{Port: object.PortMapping{ContainerPort: 9090, ServicePort: 9090}, Type: object.PortType_Internal},
+									{Port: object.PortMapping{ContainerPort: 443, ServicePort: 443}, Type: object.PortType_Internal},
+									{Port: object.PortMapping{ContainerPort: 3000, ServicePort: 3000}, Type: object.PortType_Internal},
+								},
+							},
+						},
+					},
+					{
+						Name:   "db",
+						Labels: object.Labels{"com.example.team": "db"},
+						Containers: []object.Container{
+							{
+								Name:    "db",
+								Image:   "mysql:5.7",
+								Command: []string{"docker-entrypoint.sh", "mysqld"},
+								Environment: []object.EnvVariable{
+									{Key: "MYSQL_PORT", Value: "3306"},
+									{Key: "TZ", Value: "${TZ}"},
+								},
+							},
+						},
+					},
+				},
+			},
+			[]string{
+				"/networks",
+				"/services/web_app",
+				"/services/web_app/depends_on",
+				"/services/web_app/environment/1",
+				"/services/web_app/ports/3",
+				"/services/db/environment/TZ",
+				"/services/db/labels/description",
+				"/services/db/restart",
+			},
+		},
+		{
+			"Renamed names and escapes",
+			true,
+			`
+services:
+  web_app:
+    image: nginx
+    environment:
+      PRICE: $$5
+      LITERAL: $${HOME}
+      MIXED: $$$${HOME}
+      REFERENCE: $$${HOME}
+  web-app:
+    image: nginx
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name: "web-app-2",
+						Containers: []object.Container{
+							{
+								Name:  "web-app-2",
+								Image: "nginx",
+								Environment: []object.EnvVariable{
+									{Key: "LITERAL", Value: "$${HOME}"},
+									{Key: "MIXED", Value: "$$$${HOME}"},
+									{Key: "PRICE", Value: "$5"},
+									{Key: "REFERENCE", Value: "$$${HOME}"},
+								},
+							},
+						},
+					},
+					{
+						Name: "web-app",
+						Containers: []object.Container{
+							{Name: "web-app", Image: "nginx"},
+						},
+					},
+				},
+			},
+			[]string{
+				"/services/web_app",
+			},
+		},
+		{
+			"Volumes",
+			true,
+			`
+version: "2.1"
+services:
+  web:
+    image: nginx
+    volumes:
+    - data_1:/data:ro
+    - ./conf:/etc/nginx/conf.d
+    - /var/cache
+    - type: tmpfs
+      target: /var/cache/
+    - type: volume
+      source: logs
+      target: /logs
+    - missing:/missing
+volumes:
+  data_1:
+  logs:
+    external: true
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name: "web",
+						Containers: []object.Container{
+							{
+								Name:  "web",
+								Image: "nginx",
+								Mounts: []object.Mount{
+									{VolumeRef: "data-1", MountPath: "/data", ReadOnly: true},
+									{VolumeRef: "var-cache", MountPath: "/var/cache"},
+									{VolumeRef: "var-cache-2", MountPath: "/var/cache/"},
+									{VolumeRef: "logs", MountPath: "/logs"},
+								},
+							},
+						},
+						EmptyDirVolumes: []object.EmptyDirVolume{
+							{Name: "var-cache"},
+							{Name: "var-cache-2"},
+						},
+					},
+				},
+				Volumes: []object.Volume{
+					{Name: "data-1", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+					{Name: "logs", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+			[]string{
+				"/volumes/data_1",
+				"/volumes/logs/external",
+				"/services/web/volumes/1",
+				"/services/web/volumes/5",
+			},
+		},
+		{
+			"Build",
+			true,
+			`
+services:
+  app:
+    build:
+      context: https://github.com/example/app.git#master:docker
+      dockerfile: Dockerfile.prod
+      args:
+      - VERSION=1
+    ports:
+    - 8080:8080
+  local:
+    image: local
+    build: .
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name: "app",
+						Containers: []object.Container{
+							{
+								Name:  "app",
+								Image: "app",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 8080, ServicePort: 8080}, Type: object.PortType_External},
+								},
+								Build: &object.Build{
+									Git: object.GitSource{
+										URL:        "https://github.com/example/app.git",
+										Ref:        "master",
+										ContextDir: "docker",
+									},
+									Dockerfile: "Dockerfile.prod",
+									Args: []object.EnvVariable{
+										{Key: "VERSION", Value: "1"},
+									},
+								},
+							},
+						},
+					},
+					{
+						Name: "local",
+						Containers: []object.Container{
+							{Name: "local", Image: "local"},
+						},
+					},
+				},
+			},
+			[]string{
+				"/services/app",
+				"/services/local/build",
+			},
+		},
+		{
+			"Long service name with ports",
+			true,
+			`
+services:
+  frontend-nginx-proxy:
+    image: nginx
+    ports:
+    - 80
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name: "frontend-nginx-proxy",
+						Containers: []object.Container{
+							{
+								Name:  "frontend-nginx-proxy",
+								Image: "nginx",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 80}},
+								},
+							},
+						},
+					},
+				},
+			},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			o, problems, err := Import([]byte(tt.File))
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to import: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %s", spew.Sdump(o))
+			}
+
+			if !reflect.DeepEqual(o, tt.Object) {
+				t.Errorf("Expected:\n%s\ngot:\n%s", spew.Sdump(tt.Object), spew.Sdump(o))
+			}
+
+			var paths []string
+			for _, p := range problems {
+				paths = append(paths, p.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.Problems) {
+				t.Errorf("Expected problems at %#v, got %#v", tt.Problems, problems)
+			}
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		Command string
+		Succeed bool
+		Words   []string
+	}{
+		{"", true, nil},
+		{"nginx", true, []string{"nginx"}},
+		{"  nginx   -g  'daemon off;' ", true, []string{"nginx", "-g", "daemon off;"}},
+		{`sh -c "echo \"$HOME\" \n"`, true, []string{"sh", "-c", `echo "$HOME" \n`}},
+		{`echo a\ b ''`, true, []string{"echo", "a b", ""}},
+		{`echo 'a`, false, nil},
+		{`echo a\`, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Command, func(t *testing.T) {
+			words, err := splitWords(tt.Command)
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to split: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %#v", words)
+			}
+
+			if !reflect.DeepEqual(words, tt.Words) {
+				t.Errorf("Expected %#v, got %#v", tt.Words, words)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+)
+
+// Size of the volumes created for the imported ones that don't have any
+const DefaultVolumeSize = "1Gi"
+
+// Problem is a part of the imported file that can't be represented in OpenCompose
+// and is left out or changed
+type Problem struct {
+	// File the value is in, empty when only one file is imported
+	File string
+	// Kind and name of the object the value is in, empty for files that are not made of objects
+	Object string
+	// JSON pointer to the value in the imported file or object
+	Path    string
+	Message string
+}
+
+func (p Problem) Error() string {
+	message := p.Message
+	if p.Path != "" {
+		message = fmt.Sprintf("%s: %s", p.Path, message)
+	}
+	if p.Object != "" {
+		message = fmt.Sprintf("%s: %s", p.Object, message)
+	}
+	return message
+}
+
+// Problems collects problems found while importing a file
+type Problems []Problem
+
+func (p *Problems) Add(path string, format string, args ...interface{}) {
+	*p = append(*p, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Returns path of the value at key (or index) of the value at path
+func Child(path string, key string) string {
+	return path + "/" + encodingutil.EscapePathSegment(key)
+}
+
+// Returns value with $ escaped as $$ so the imported value is not taken for a variable
+// or parameter reference when the OpenCompose file is read
+func EscapeDollars(value string) string {
+	return strings.Replace(value, "$", "$$", -1)
+}
+
+// Returns values with $ escaped, see EscapeDollars
+func EscapeDollarsAll(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = EscapeDollars(value)
+	}
+	return escaped
+}
@@ -0,0 +1,207 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	kyaml "github.com/ghodss/yaml"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	ext_v1beta1 "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+// Separator of YAML documents in one file
+var documentSeparatorRegexp = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// API versions the kinds that can be imported are read from
+var supportedAPIVersions = map[string][]string{
+	"Deployment":            {"apps/v1", "apps/v1beta2", "apps/v1beta1", "extensions/v1beta1"},
+	"Service":               {"v1"},
+	"Ingress":               {"networking.k8s.io/v1", "networking.k8s.io/v1beta1", "extensions/v1beta1"},
+	"PersistentVolumeClaim": {"v1"},
+}
+
+// Manifest is a Kubernetes object read from a file
+type Manifest struct {
+	// File the object is read from
+	File       string
+	APIVersion string
+	Kind       string
+	Name       string
+	// Decoded object, nil when the kind can't be imported
+	Object runtime.Object
+	// The object as it is written, used to find the fields that can't be imported
+	raw map[string]interface{}
+}
+
+func (m *Manifest) String() string {
+	return fmt.Sprintf("%s %q", m.Kind, m.Name)
+}
+
+// Returns the manifests in YAML or JSON data of the file, it can have more YAML documents
+// separated by "---" and the objects of Lists are returned one by one
+func Decode(file string, data []byte) ([]Manifest, error) {
+	var manifests []Manifest
+	for i, document := range documentSeparatorRegexp.Split(string(data), -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+
+		jsonData, err := kyaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return nil, fmt.Errorf("document #%d: %s", i+1, err)
+		}
+
+		var raw map[string]interface{}
+		if err := json.Unmarshal(jsonData, &raw); err != nil {
+			return nil, fmt.Errorf("document #%d: %s", i+1, err)
+		}
+		// documents with only comments
+		if raw == nil {
+			continue
+		}
+
+		decoded, err := decodeObject(file, raw)
+		if err != nil {
+			return nil, fmt.Errorf("document #%d: %s", i+1, err)
+		}
+		manifests = append(manifests, decoded...)
+	}
+	return manifests, nil
+}
+
+func decodeObject(file string, raw map[string]interface{}) ([]Manifest, error) {
+	m := Manifest{File: file, raw: raw}
+	m.APIVersion, _ = raw["apiVersion"].(string)
+	m.Kind, _ = raw["kind"].(string)
+	if metadata, ok := raw["metadata"].(map[string]interface{}); ok {
+		m.Name, _ = metadata["name"].(string)
+	}
+
+	if m.Kind == "" {
+		return nil, fmt.Errorf("%s", "object has no kind")
+	}
+
+	if items, ok := raw["items"].([]interface{}); ok && strings.HasSuffix(m.Kind, "List") {
+		var manifests []Manifest
+		for i, item := range items {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("item #%d of %s is not an object", i+1, m.Kind)
+			}
+			decoded, err := decodeObject(file, object)
+			if err != nil {
+				return nil, fmt.Errorf("item #%d of %s: %s", i+1, m.Kind, err)
+			}
+			manifests = append(manifests, decoded...)
+		}
+		return manifests, nil
+	}
+
+	supported := false
+	for _, version := range supportedAPIVersions[m.Kind] {
+		if m.APIVersion == version {
+			supported = true
+		}
+	}
+	if !supported {
+		return []Manifest{m}, nil
+	}
+
+	var object runtime.Object
+	switch m.Kind {
+	case "Deployment":
+		object = &ext_v1beta1.Deployment{}
+	case "Service":
+		object = &api_v1.Service{}
+	case "Ingress":
+		object = &ext_v1beta1.Ingress{}
+	case "PersistentVolumeClaim":
+		object = &api_v1.PersistentVolumeClaim{}
+	}
+
+	data, err := json.Marshal(convertIngressBackends(raw))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, object); err != nil {
+		return nil, fmt.Errorf("%s: %s", m.String(), err)
+	}
+	m.Object = object
+
+	return []Manifest{m}, nil
+}
+
+// Returns Ingress of networking.k8s.io/v1 with backends written the way of extensions/v1beta1
+// so it can be decoded into the vendored type, other objects are returned as they are
+func convertIngressBackends(raw map[string]interface{}) map[string]interface{} {
+	if raw["kind"] != "Ingress" || raw["apiVersion"] != "networking.k8s.io/v1" {
+		return raw
+	}
+
+	convert := func(backend interface{}) interface{} {
+		b, ok := backend.(map[string]interface{})
+		if !ok {
+			return backend
+		}
+		service, ok := b["service"].(map[string]interface{})
+		if !ok {
+			return backend
+		}
+
+		converted := map[string]interface{}{"serviceName": service["name"]}
+		if port, ok := service["port"].(map[string]interface{}); ok {
+			if number, ok := port["number"]; ok {
+				converted["servicePort"] = number
+			} else {
+				converted["servicePort"] = port["name"]
+			}
+		}
+		return converted
+	}
+
+	copied := copyJSON(raw).(map[string]interface{})
+	spec, _ := copied["spec"].(map[string]interface{})
+	if spec == nil {
+		return copied
+	}
+	if backend, ok := spec["defaultBackend"]; ok {
+		spec["backend"] = convert(backend)
+		delete(spec, "defaultBackend")
+	}
+	rules, _ := spec["rules"].([]interface{})
+	for _, rule := range rules {
+		r, _ := rule.(map[string]interface{})
+		http, _ := r["http"].(map[string]interface{})
+		paths, _ := http["paths"].([]interface{})
+		for _, path := range paths {
+			if p, ok := path.(map[string]interface{}); ok {
+				p["backend"] = convert(p["backend"])
+				delete(p, "pathType")
+			}
+		}
+	}
+	return copied
+}
+
+// Returns deep copy of value decoded from JSON
+func copyJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{})
+		for key, item := range v {
+			m[key] = copyJSON(item)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, item := range v {
+			l[i] = copyJSON(item)
+		}
+		return l
+	default:
+		return v
+	}
+}
@@ -0,0 +1,649 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/importer"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	ext_v1beta1 "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/pkg/util/intstr"
+)
+
+// Fields every imported object can have
+var commonFields = []string{
+	"apiVersion",
+	"kind",
+	"metadata/name",
+}
+
+// Fields of the kinds that are turned into OpenCompose, "*" stands for every item of a list.
+// The values nested in them are imported as well or checked when the object is imported.
+var supportedFields = map[string][]string{
+	"Deployment": {
+		"metadata/labels",
+		"spec/replicas",
+		"spec/selector",
+		"spec/strategy/type",
+		"spec/template/metadata/labels",
+		"spec/template/spec/containers/*/name",
+		"spec/template/spec/containers/*/image",
+		"spec/template/spec/containers/*/command",
+		"spec/template/spec/containers/*/args",
+		"spec/template/spec/containers/*/env/*/name",
+		"spec/template/spec/containers/*/env/*/value",
+		"spec/template/spec/containers/*/ports/*/name",
+		"spec/template/spec/containers/*/ports/*/containerPort",
+		"spec/template/spec/containers/*/ports/*/protocol",
+		"spec/template/spec/containers/*/volumeMounts/*/name",
+		"spec/template/spec/containers/*/volumeMounts/*/mountPath",
+		"spec/template/spec/containers/*/volumeMounts/*/readOnly",
+		"spec/template/spec/containers/*/volumeMounts/*/subPath",
+		"spec/template/spec/volumes/*/name",
+		"spec/template/spec/volumes/*/persistentVolumeClaim/claimName",
+		"spec/template/spec/volumes/*/emptyDir",
+	},
+	"Service": {
+		"metadata/labels",
+		"spec/selector",
+		"spec/type",
+		"spec/ports/*/name",
+		"spec/ports/*/port",
+		"spec/ports/*/targetPort",
+		"spec/ports/*/protocol",
+	},
+	"Ingress": {
+		"metadata/labels",
+		"spec/rules/*/host",
+		"spec/rules/*/http/paths/*/path",
+		"spec/rules/*/http/paths/*/pathType",
+		"spec/rules/*/http/paths/*/backend/serviceName",
+		"spec/rules/*/http/paths/*/backend/servicePort",
+		"spec/rules/*/http/paths/*/backend/service/name",
+		"spec/rules/*/http/paths/*/backend/service/port/number",
+		"spec/rules/*/http/paths/*/backend/service/port/name",
+	},
+	"PersistentVolumeClaim": {
+		"metadata/annotations/volume.beta.kubernetes.io~1storage-class",
+		"spec/accessModes",
+		"spec/resources/requests/storage",
+		"spec/storageClassName",
+	},
+}
+
+// Fields the cluster fills in, manifests exported from it have them
+var ignoredFields = []string{
+	"metadata/uid",
+	"metadata/resourceVersion",
+	"metadata/selfLink",
+	"metadata/generation",
+	"metadata/creationTimestamp",
+	"metadata/managedFields",
+	"metadata/annotations/kubectl.kubernetes.io~1last-applied-configuration",
+	"metadata/annotations/deployment.kubernetes.io~1revision",
+	"status",
+}
+
+// Service port that an Ingress can route to
+type backend struct {
+	// index of the imported service
+	service int
+	port    api_v1.ServicePort
+}
+
+type converter struct {
+	manifests []Manifest
+	// problems of each manifest so they are reported in the order of the manifests
+	problems []importer.Problems
+
+	o *object.OpenCompose
+	// Deployments the services are imported from and their manifests, in the same order as the services
+	deployments         []*ext_v1beta1.Deployment
+	deploymentManifests []int
+	// names of the imported volumes
+	volumes map[string]bool
+	// ports of the Kubernetes Services by their names, more Services can have the same name
+	// when the manifests were converted from OpenCompose
+	backends map[string][]backend
+}
+
+// Imports Deployments, Services, Ingresses and PersistentVolumeClaims. Each Deployment becomes a service,
+// the ports of the Services that select its pods become its ports and the Ingresses that route to them
+// give them host and path. Returns OpenCompose of the current spec version together with the parts
+// of the manifests that can't be represented in it and were left out or changed.
+// The values have $ escaped so they are not interpolated when the OpenCompose is read.
+func Import(manifests []Manifest) (*object.OpenCompose, []importer.Problem, error) {
+	c := &converter{
+		manifests: manifests,
+		problems:  make([]importer.Problems, len(manifests)),
+		o:         &object.OpenCompose{Version: encoding.CurrentVersion},
+		volumes:   make(map[string]bool),
+		backends:  make(map[string][]backend),
+	}
+
+	// volumes go first so the mounts can refer to them, Services need the Deployments they select
+	// and Ingresses the Services they route to
+	for _, kind := range []string{"PersistentVolumeClaim", "Deployment", "Service", "Ingress"} {
+		for i := range manifests {
+			if manifests[i].Kind != kind {
+				continue
+			}
+
+			switch o := manifests[i].Object.(type) {
+			case *api_v1.PersistentVolumeClaim:
+				c.importPVC(i, o)
+			case *ext_v1beta1.Deployment:
+				c.importDeployment(i, o)
+			case *api_v1.Service:
+				c.importService(i, o)
+			case *ext_v1beta1.Ingress:
+				c.importIngress(i, o)
+			}
+		}
+	}
+
+	if len(c.o.Services) == 0 {
+		return nil, nil, fmt.Errorf("%s", "there are no Deployments to import")
+	}
+	c.checkContainerPorts()
+
+	var problems []importer.Problem
+	for i := range manifests {
+		m := &manifests[i]
+		if m.Object != nil {
+			c.checkFields(i)
+		} else if versions, ok := supportedAPIVersions[m.Kind]; ok {
+			c.problem(i, "/apiVersion", "apiVersion %q is not supported, it has to be one of %s", m.APIVersion, strings.Join(versions, ", "))
+		} else {
+			c.problem(i, "", "%s", "can't be imported, only Deployments, Services, Ingresses and PersistentVolumeClaims can")
+		}
+		problems = append(problems, c.problems[i]...)
+	}
+
+	return c.o, problems, nil
+}
+
+func (c *converter) problem(i int, path string, format string, args ...interface{}) {
+	m := &c.manifests[i]
+	c.problems[i] = append(c.problems[i], importer.Problem{
+		File:    m.File,
+		Object:  m.String(),
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *converter) addVolume(v object.Volume) {
+	c.o.Volumes = append(c.o.Volumes, v)
+	c.volumes[v.Name] = true
+}
+
+func (c *converter) importPVC(i int, pvc *api_v1.PersistentVolumeClaim) {
+	v := object.Volume{
+		Name:       pvc.Name,
+		Size:       importer.DefaultVolumeSize,
+		AccessMode: string(api_v1.ReadWriteOnce),
+	}
+
+	if size, ok := pvc.Spec.Resources.Requests[api_v1.ResourceStorage]; ok {
+		v.Size = size.String()
+	} else {
+		c.problem(i, "/spec/resources/requests/storage", "size is not set, the volume will have %s", importer.DefaultVolumeSize)
+	}
+
+	if len(pvc.Spec.AccessModes) > 0 {
+		v.AccessMode = string(pvc.Spec.AccessModes[0])
+	}
+	if len(pvc.Spec.AccessModes) > 1 {
+		c.problem(i, "/spec/accessModes", "volume can have only one access mode, %q is used", v.AccessMode)
+	}
+
+	// the vendored types don't have storageClassName yet
+	if spec, ok := c.manifests[i].raw["spec"].(map[string]interface{}); ok {
+		if class, ok := spec["storageClassName"].(string); ok {
+			class = importer.EscapeDollars(class)
+			v.StorageClass = &class
+		}
+	}
+	if class, ok := pvc.Annotations["volume.beta.kubernetes.io/storage-class"]; ok {
+		class = importer.EscapeDollars(class)
+		v.StorageClass = &class
+	}
+
+	c.addVolume(v)
+}
+
+func (c *converter) importDeployment(i int, d *ext_v1beta1.Deployment) {
+	s := object.Service{
+		Name:     d.Name,
+		Replicas: d.Spec.Replicas,
+	}
+
+	// the "service" label is added by the transformers
+	for key, value := range d.Spec.Template.Labels {
+		if key == "service" {
+			continue
+		}
+		if s.Labels == nil {
+			s.Labels = make(object.Labels)
+		}
+		s.Labels[key] = importer.EscapeDollars(value)
+	}
+
+	c.checkLabels(i, d.Labels, &s)
+
+	if strategy := d.Spec.Strategy.Type; strategy != "" && strategy != ext_v1beta1.RollingUpdateDeploymentStrategyType {
+		c.problem(i, "/spec/strategy/type", "strategy %q is not supported, services are updated by rolling update", strategy)
+	}
+
+	// pod volumes by their names, claims are root volumes and EmptyDir volumes belong to the service
+	claims := make(map[string]string)
+	for j, v := range d.Spec.Template.Spec.Volumes {
+		path := importer.Child("/spec/template/spec/volumes", strconv.Itoa(j))
+
+		switch {
+		case v.PersistentVolumeClaim != nil:
+			claim := v.PersistentVolumeClaim.ClaimName
+			claims[v.Name] = claim
+			if !c.volumes[claim] {
+				c.problem(i, path, "PersistentVolumeClaim %q is not imported, a volume of %s is created for it", claim, importer.DefaultVolumeSize)
+				c.addVolume(object.Volume{Name: claim, Size: importer.DefaultVolumeSize, AccessMode: string(api_v1.ReadWriteOnce)})
+			}
+		case v.EmptyDir != nil:
+			if v.EmptyDir.Medium != api_v1.StorageMediumDefault {
+				c.problem(i, path+"/emptyDir/medium", "medium %q is not supported", v.EmptyDir.Medium)
+			}
+			s.EmptyDirVolumes = append(s.EmptyDirVolumes, object.EmptyDirVolume{Name: v.Name})
+		}
+	}
+
+	for j, kc := range d.Spec.Template.Spec.Containers {
+		path := importer.Child("/spec/template/spec/containers", strconv.Itoa(j))
+
+		container := object.Container{
+			Name:    kc.Name,
+			Image:   importer.EscapeDollars(kc.Image),
+			Command: importer.EscapeDollarsAll(kc.Command),
+			Args:    importer.EscapeDollarsAll(kc.Args),
+		}
+
+		// valueFrom is reported as a field that is not supported
+		for _, e := range kc.Env {
+			if e.ValueFrom == nil {
+				container.Environment = append(container.Environment, object.EnvVariable{Key: e.Name, Value: importer.EscapeDollars(e.Value)})
+			}
+		}
+
+		for k, m := range kc.VolumeMounts {
+			mount := object.Mount{
+				MountPath:     importer.EscapeDollars(m.MountPath),
+				VolumeSubPath: importer.EscapeDollars(m.SubPath),
+				ReadOnly:      m.ReadOnly,
+			}
+
+			if claim, ok := claims[m.Name]; ok {
+				mount.VolumeRef = claim
+			} else if s.EmptyDirVolumeExists(m.Name) {
+				mount.VolumeRef = m.Name
+			} else {
+				c.problem(i, importer.Child(importer.Child(path, "volumeMounts"), strconv.Itoa(k)), "volume %q can't be imported, the mount is left out", m.Name)
+				continue
+			}
+			container.Mounts = append(container.Mounts, mount)
+		}
+
+		s.Containers = append(s.Containers, container)
+	}
+
+	c.o.Services = append(c.o.Services, s)
+	c.deployments = append(c.deployments, d)
+	c.deploymentManifests = append(c.deploymentManifests, i)
+}
+
+// Reports the labels of the object the service it's imported into doesn't have,
+// the objects converted from the service get the labels of the service
+func (c *converter) checkLabels(i int, labels map[string]string, s *object.Service) {
+	var keys []string
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// the "service" label is added by the transformers
+		if key == "service" {
+			continue
+		}
+		if value, ok := s.Labels[key]; !ok || value != importer.EscapeDollars(labels[key]) {
+			c.problem(i, importer.Child("/metadata/labels", key), "label %q is not a label of the pods of %q, it's left out", key, s.Name)
+		}
+	}
+}
+
+// Reports the container ports no Service targets, the ports are imported from the Services
+func (c *converter) checkContainerPorts() {
+	for j, d := range c.deployments {
+		s := &c.o.Services[j]
+		for k, kc := range d.Spec.Template.Spec.Containers {
+			for l, cp := range kc.Ports {
+				targeted := false
+				for _, p := range s.Containers[k].Ports {
+					if p.Port.ContainerPort == int(cp.ContainerPort) {
+						targeted = true
+					}
+				}
+				if !targeted {
+					path := fmt.Sprintf("/spec/template/spec/containers/%d/ports/%d", k, l)
+					c.problem(c.deploymentManifests[j], path, "container port %d is not targeted by any Service, it's left out", cp.ContainerPort)
+				}
+			}
+		}
+	}
+}
+
+// Reports whether the selector selects pods with the labels
+func selects(selector map[string]string, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns index of the container and the container port the Service port targets
+func targetPort(d *ext_v1beta1.Deployment, p api_v1.ServicePort) (int, int, bool) {
+	containers := d.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return 0, 0, false
+	}
+
+	if p.TargetPort.Type == intstr.String {
+		for i, c := range containers {
+			for _, cp := range c.Ports {
+				if cp.Name == p.TargetPort.StrVal {
+					return i, int(cp.ContainerPort), true
+				}
+			}
+		}
+		return 0, 0, false
+	}
+
+	target := int(p.TargetPort.IntVal)
+	if target == 0 {
+		target = int(p.Port)
+	}
+
+	// containers don't have to declare the ports, the first one is the main container then
+	for i, c := range containers {
+		for _, cp := range c.Ports {
+			if int(cp.ContainerPort) == target {
+				return i, target, true
+			}
+		}
+	}
+	return 0, target, true
+}
+
+func (c *converter) importService(i int, ks *api_v1.Service) {
+	var selected []int
+	for j, d := range c.deployments {
+		if selects(ks.Spec.Selector, d.Spec.Template.Labels) {
+			selected = append(selected, j)
+		}
+	}
+	switch {
+	case len(ks.Spec.Selector) == 0:
+		c.problem(i, "", "%s", "Service without selector can't be imported")
+		return
+	case len(selected) == 0:
+		c.problem(i, "/spec/selector", "%s", "doesn't select pods of any imported Deployment")
+		return
+	case len(selected) > 1:
+		c.problem(i, "/spec/selector", "selects pods of more Deployments, the ports are added to %q", c.o.Services[selected[0]].Name)
+	}
+
+	index := selected[0]
+	s := &c.o.Services[index]
+	d := c.deployments[index]
+
+	if ks.Name != s.Name {
+		c.problem(i, "/metadata/name", "Service is named after the Deployment %q once converted", s.Name)
+	}
+	c.checkLabels(i, ks.Labels, s)
+
+	portType := object.PortType_Internal
+	switch ks.Spec.Type {
+	case "", api_v1.ServiceTypeClusterIP:
+	case api_v1.ServiceTypeLoadBalancer:
+		portType = object.PortType_External
+	case api_v1.ServiceTypeNodePort:
+		portType = object.PortType_External
+		c.problem(i, "/spec/type", "%s", "NodePort Service is imported as external ports which are exposed by LoadBalancer Service")
+	default:
+		c.problem(i, "/spec/type", "Service of type %q can't be imported", ks.Spec.Type)
+		return
+	}
+
+	for j, p := range ks.Spec.Ports {
+		path := importer.Child("/spec/ports", strconv.Itoa(j))
+
+		if p.Protocol != "" && p.Protocol != api_v1.ProtocolTCP {
+			c.problem(i, importer.Child(path, "protocol"), "%s ports are not supported", p.Protocol)
+			continue
+		}
+
+		containerIndex, target, ok := targetPort(d, p)
+		if !ok {
+			c.problem(i, importer.Child(path, "targetPort"), "%q is not a port of the containers of Deployment %q", p.TargetPort.String(), d.Name)
+			continue
+		}
+
+		container := &s.Containers[containerIndex]
+		exposed := false
+		for _, cp := range container.Ports {
+			if cp.Port.ContainerPort == target {
+				exposed = true
+			}
+		}
+		if exposed {
+			c.problem(i, path, "container port %d is already exposed, only one service port can be mapped to it", target)
+			continue
+		}
+
+		container.Ports = append(container.Ports, object.Port{
+			Port: object.PortMapping{
+				ContainerPort: target,
+				ServicePort:   int(p.Port),
+			},
+			Type: portType,
+		})
+		c.backends[ks.Name] = append(c.backends[ks.Name], backend{service: index, port: p})
+	}
+}
+
+// Returns the imported port the Ingress backend routes to and the service it's a port of
+func (c *converter) ingressPort(b ext_v1beta1.IngressBackend) (*object.Port, *object.Service) {
+	for _, candidate := range c.backends[b.ServiceName] {
+		if b.ServicePort.Type == intstr.String && candidate.port.Name != b.ServicePort.StrVal ||
+			b.ServicePort.Type == intstr.Int && candidate.port.Port != b.ServicePort.IntVal {
+			continue
+		}
+
+		s := &c.o.Services[candidate.service]
+		for j := range s.Containers {
+			for k := range s.Containers[j].Ports {
+				if s.Containers[j].Ports[k].Port.ServicePort == int(candidate.port.Port) {
+					return &s.Containers[j].Ports[k], s
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Returns pathType of the path of the Ingress rule as it is written, the vendored type doesn't have it
+func (c *converter) pathType(i int, rule int, path int) string {
+	var node interface{} = c.manifests[i].raw
+	for _, key := range []interface{}{"spec", "rules", rule, "http", "paths", path, "pathType"} {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			node = n[fmt.Sprint(key)]
+		case []interface{}:
+			index, ok := key.(int)
+			if !ok || index >= len(n) {
+				return ""
+			}
+			node = n[index]
+		default:
+			return ""
+		}
+	}
+	pathType, _ := node.(string)
+	return pathType
+}
+
+func (c *converter) importIngress(i int, ingress *ext_v1beta1.Ingress) {
+	// the service of the first imported path, the labels are checked against it
+	var routed *object.Service
+
+	for j, rule := range ingress.Spec.Rules {
+		rulePath := importer.Child("/spec/rules", strconv.Itoa(j))
+
+		if rule.HTTP == nil {
+			continue
+		}
+		if rule.Host == "" {
+			c.problem(i, rulePath, "%s", "rule without host can't be imported")
+			continue
+		}
+
+		for k, p := range rule.HTTP.Paths {
+			path := importer.Child(rulePath+"/http/paths", strconv.Itoa(k))
+
+			port, s := c.ingressPort(p.Backend)
+			if port == nil {
+				c.problem(i, importer.Child(path, "backend"), "%s:%s is not a port of an imported Service", p.Backend.ServiceName, p.Backend.ServicePort.String())
+				continue
+			}
+			host := importer.EscapeDollars(rule.Host)
+			pathValue := importer.EscapeDollars(p.Path)
+			if port.Host != nil && (*port.Host != host || port.Path != pathValue) {
+				c.problem(i, path, "service port %d is already routed from %s%s, a port can have only one host and path", port.Port.ServicePort, *port.Host, port.Path)
+				continue
+			}
+
+			if pathType := c.pathType(i, j, k); pathType == "Exact" {
+				c.problem(i, importer.Child(path, "pathType"), "%s", "pathType \"Exact\" is not supported, the path is imported as a prefix")
+			}
+
+			port.Host = &host
+			port.Path = pathValue
+			if routed == nil {
+				routed = s
+			}
+		}
+	}
+
+	if routed != nil {
+		c.checkLabels(i, ingress.Labels, routed)
+	}
+}
+
+// Reports the fields of the manifest that are not imported
+func (c *converter) checkFields(i int) {
+	var patterns [][]string
+	for _, fields := range [][]string{commonFields, supportedFields[c.manifests[i].Kind], ignoredFields} {
+		for _, field := range fields {
+			patterns = append(patterns, strings.Split(field, "/"))
+		}
+	}
+
+	c.checkNestedFields(i, c.manifests[i].raw, "", nil, patterns)
+}
+
+func (c *converter) checkNestedFields(i int, node interface{}, path string, segments []string, patterns [][]string) {
+	check := func(key string, value interface{}) {
+		if isEmpty(value) {
+			return
+		}
+
+		fieldSegments := append(append([]string{}, segments...), encodingutil.EscapePathSegment(key))
+		supported, nested := matchFields(fieldSegments, patterns)
+		switch {
+		case supported:
+		case nested:
+			c.checkNestedFields(i, value, importer.Child(path, key), fieldSegments, patterns)
+		default:
+			c.problem(i, importer.Child(path, key), "%q is not supported", key)
+		}
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(n) {
+			check(key, n[key])
+		}
+	case []interface{}:
+		for j, item := range n {
+			check(strconv.Itoa(j), item)
+		}
+	}
+}
+
+// Reports whether the field is supported as a whole and whether there are supported fields nested in it
+func matchFields(segments []string, patterns [][]string) (bool, bool) {
+	nested := false
+	for _, pattern := range patterns {
+		matches := true
+		for j := 0; j < len(segments) && j < len(pattern); j++ {
+			if pattern[j] != "*" && pattern[j] != segments[j] {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+
+		if len(segments) >= len(pattern) {
+			return true, false
+		}
+		nested = true
+	}
+	return false, nested
+}
+
+// Reports whether the value is the same as if it wasn't set
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
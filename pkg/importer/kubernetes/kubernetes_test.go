@@ -0,0 +1,611 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	kyaml "github.com/ghodss/yaml"
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	transform_kubernetes "github.com/redhat-developer/opencompose/pkg/transform/kubernetes"
+	"k8s.io/client-go/pkg/api"
+)
+
+func TestImportTransformed(t *testing.T) {
+	o := &object.OpenCompose{
+		Version: encoding.CurrentVersion,
+		Services: []object.Service{
+			{
+				Name: "web",
+				Containers: []object.Container{
+					{
+						Name:    "nginx",
+						Image:   "nginx:1.11",
+						Command: []string{"nginx"},
+						Args:    []string{"-g", "daemon off;"},
+						Environment: []object.EnvVariable{
+							{Key: "MODE", Value: "prod"},
+							{Key: "LOG", Value: "debug"},
+						},
+						Ports: []object.Port{
+							{Port: object.PortMapping{ContainerPort: 9090, ServicePort: 9090}, Type: object.PortType_Internal},
+							{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_External, Host: goutil.StringAddr("example.com"), Path: "/web"},
+						},
+						Mounts: []object.Mount{
+							{VolumeRef: "data", MountPath: "/data", VolumeSubPath: "web", ReadOnly: true},
+							{VolumeRef: "cache", MountPath: "/var/cache"},
+						},
+					},
+					{
+						Name:  "sidecar",
+						Image: "busybox",
+						Mounts: []object.Mount{
+							{VolumeRef: "cache", MountPath: "/cache"},
+						},
+					},
+				},
+				EmptyDirVolumes: []object.EmptyDirVolume{
+					{Name: "cache"},
+				},
+				Replicas: goutil.Int32Addr(3),
+				Labels:   object.Labels{"team": "frontend"},
+			},
+			{
+				Name: "db",
+				Containers: []object.Container{
+					{
+						Name:  "mysql",
+						Image: "mysql",
+						Ports: []object.Port{
+							{Port: object.PortMapping{ContainerPort: 3306, ServicePort: 3306}, Type: object.PortType_Internal},
+						},
+					},
+				},
+			},
+		},
+		Volumes: []object.Volume{
+			{Name: "data", Size: "5Gi", AccessMode: "ReadWriteMany", StorageClass: goutil.StringAddr("fast")},
+		},
+	}
+
+	transformer := transform_kubernetes.Transformer{}
+	objects, err := transformer.Transform(o)
+	if err != nil {
+		t.Fatalf("Failed to transform: %s", err)
+	}
+
+	var manifests []Manifest
+	for _, obj := range objects {
+		gvk, _, err := api.Scheme.ObjectKind(obj)
+		if err != nil {
+			t.Fatalf("Failed to get kind of %T: %s", obj, err)
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+		data, err := kyaml.Marshal(obj)
+		if err != nil {
+			t.Fatalf("Failed to marshal %T: %s", obj, err)
+		}
+
+		decoded, err := Decode("", data)
+		if err != nil {
+			t.Fatalf("Failed to decode %T: %s", obj, err)
+		}
+		manifests = append(manifests, decoded...)
+	}
+
+	imported, problems, err := Import(manifests)
+	if err != nil {
+		t.Fatalf("Failed to import: %s", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %#v", problems)
+	}
+
+	if !reflect.DeepEqual(imported, o) {
+		t.Errorf("Expected:\n%s\ngot:\n%s", spew.Sdump(o), spew.Sdump(imported))
+	}
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Succeed   bool
+		Manifests string
+		Object    *object.OpenCompose
+		Problems  []string
+	}{
+		{
+			"No Deployments",
+			false,
+			`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+  - port: 80
+`,
+			nil,
+			nil,
+		},
+		{
+			"Selectors and named ports",
+			true,
+			`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  selector:
+    matchLabels:
+      app: web
+  strategy:
+    type: Recreate
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+        ports:
+        - name: http
+          containerPort: 80
+        env:
+        - name: MODE
+          value: prod
+        - name: POD
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        volumeMounts:
+        - name: config
+          mountPath: /etc/nginx
+      volumes:
+      - name: config
+        configMap:
+          name: nginx
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+spec:
+  type: NodePort
+  selector:
+    app: web
+  ports:
+  - port: 8080
+    targetPort: http
+  - port: 53
+    protocol: UDP
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: frontend
+spec:
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: frontend
+            port:
+              number: 8080
+  - http:
+      paths:
+      - backend:
+          service:
+            name: frontend
+            port:
+              number: 8080
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name:   "web",
+						Labels: object.Labels{"app": "web"},
+						Containers: []object.Container{
+							{
+								Name:  "nginx",
+								Image: "nginx",
+								Environment: []object.EnvVariable{
+									{Key: "MODE", Value: "prod"},
+								},
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_External, Host: goutil.StringAddr("example.com"), Path: "/"},
+								},
+							},
+						},
+					},
+				},
+			},
+			[]string{
+				`Deployment "web": /spec/strategy/type`,
+				`Deployment "web": /spec/template/spec/containers/0/volumeMounts/0`,
+				`Deployment "web": /metadata/namespace`,
+				`Deployment "web": /spec/template/spec/containers/0/env/1/valueFrom`,
+				`Deployment "web": /spec/template/spec/volumes/0/configMap`,
+				`Service "frontend": /metadata/name`,
+				`Service "frontend": /spec/type`,
+				`Service "frontend": /spec/ports/1/protocol`,
+				`Ingress "frontend": /spec/rules/1`,
+				`ConfigMap "nginx": `,
+			},
+		},
+		{
+			"Labels, container ports and path types",
+			true,
+			`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+    tier: frontend
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+        ports:
+        - containerPort: 80
+        - containerPort: 9090
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    app: web
+    service: web
+    owner: ops
+spec:
+  selector:
+    app: web
+  ports:
+  - port: 80
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  labels:
+    app: other
+spec:
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /api
+        pathType: Exact
+        backend:
+          service:
+            name: web
+            port:
+              number: 80
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  labels:
+    app: web
+spec:
+  resources:
+    requests:
+      storage: 1Gi
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name:   "web",
+						Labels: object.Labels{"app": "web"},
+						Containers: []object.Container{
+							{
+								Name:  "nginx",
+								Image: "nginx",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 80}, Type: object.PortType_Internal, Host: goutil.StringAddr("example.com"), Path: "/api"},
+								},
+							},
+						},
+					},
+				},
+				Volumes: []object.Volume{
+					{Name: "data", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+			[]string{
+				`Deployment "web": /metadata/labels/tier`,
+				`Deployment "web": /spec/template/spec/containers/0/ports/1`,
+				`Service "web": /metadata/labels/owner`,
+				`Ingress "web": /spec/rules/0/http/paths/0/pathType`,
+				`Ingress "web": /metadata/labels/app`,
+				`PersistentVolumeClaim "data": /metadata/labels`,
+			},
+		},
+		{
+			"Volumes",
+			true,
+			`
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: PersistentVolumeClaim
+  metadata:
+    name: data
+    annotations:
+      volume.beta.kubernetes.io/storage-class: slow
+  spec:
+    accessModes:
+    - ReadWriteOnce
+    - ReadOnlyMany
+- apiVersion: extensions/v1beta1
+  kind: Deployment
+  metadata:
+    name: db
+  spec:
+    template:
+      metadata:
+        labels:
+          service: db
+      spec:
+        containers:
+        - name: postgres
+          image: postgres
+          volumeMounts:
+          - name: data
+            mountPath: /var/lib/postgresql
+          - name: logs
+            mountPath: /logs
+          - name: tmp
+            mountPath: /tmp
+        volumes:
+        - name: data
+          persistentVolumeClaim:
+            claimName: data
+        - name: logs
+          persistentVolumeClaim:
+            claimName: logs
+        - name: tmp
+          emptyDir:
+            medium: Memory
+- apiVersion: extensions/v1beta1
+  kind: PersistentVolumeClaim
+  metadata:
+    name: old
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name: "db",
+						Containers: []object.Container{
+							{
+								Name:  "postgres",
+								Image: "postgres",
+								Mounts: []object.Mount{
+									{VolumeRef: "data", MountPath: "/var/lib/postgresql"},
+									{VolumeRef: "logs", MountPath: "/logs"},
+									{VolumeRef: "tmp", MountPath: "/tmp"},
+								},
+							},
+						},
+						EmptyDirVolumes: []object.EmptyDirVolume{
+							{Name: "tmp"},
+						},
+					},
+				},
+				Volumes: []object.Volume{
+					{Name: "data", Size: "1Gi", AccessMode: "ReadWriteOnce", StorageClass: goutil.StringAddr("slow")},
+					{Name: "logs", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+			[]string{
+				`PersistentVolumeClaim "data": /spec/resources/requests/storage`,
+				`PersistentVolumeClaim "data": /spec/accessModes`,
+				`Deployment "db": /spec/template/spec/volumes/1`,
+				`Deployment "db": /spec/template/spec/volumes/2/emptyDir/medium`,
+				`PersistentVolumeClaim "old": /apiVersion`,
+			},
+		},
+		{
+			"Dollar signs are escaped",
+			true,
+			`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: app
+        image: app
+        command: ["sh", "-c", "echo $HOME"]
+        env:
+        - name: DIR
+          value: ${HOME}/x
+`,
+			&object.OpenCompose{
+				Version: encoding.CurrentVersion,
+				Services: []object.Service{
+					{
+						Name:   "web",
+						Labels: object.Labels{"app": "web"},
+						Containers: []object.Container{
+							{
+								Name:    "app",
+								Image:   "app",
+								Command: []string{"sh", "-c", "echo $$HOME"},
+								Environment: []object.EnvVariable{
+									{Key: "DIR", Value: "$${HOME}/x"},
+								},
+							},
+						},
+					},
+				},
+			},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			manifests, err := Decode("manifests.yaml", []byte(tt.Manifests))
+			if err != nil {
+				t.Fatalf("Failed to decode: %s", err)
+			}
+
+			o, problems, err := Import(manifests)
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to import: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %s", spew.Sdump(o))
+			}
+
+			if !reflect.DeepEqual(o, tt.Object) {
+				t.Errorf("Expected:\n%s\ngot:\n%s", spew.Sdump(tt.Object), spew.Sdump(o))
+			}
+
+			var paths []string
+			for _, p := range problems {
+				paths = append(paths, p.Object+": "+p.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.Problems) {
+				t.Errorf("Expected problems at %#v, got %#v", tt.Problems, problems)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Succeed   bool
+		Manifests string
+		Objects   []string
+	}{
+		{
+			"Documents",
+			true,
+			`
+# comment
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+---
+{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "password"}}
+`,
+			[]string{`Service "web"`, `Secret "password"`},
+		},
+		{
+			"List",
+			true,
+			`
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: web
+- apiVersion: v1
+  kind: ServiceList
+  items:
+  - apiVersion: v1
+    kind: Service
+    metadata:
+      name: web
+`,
+			[]string{`Deployment "web"`, `Service "web"`},
+		},
+		{
+			"Without kind",
+			false,
+			`
+apiVersion: v1
+metadata:
+  name: web
+`,
+			nil,
+		},
+		{
+			"Wrong type",
+			false,
+			`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports: 80
+`,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			manifests, err := Decode("manifests.yaml", []byte(tt.Manifests))
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to decode: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %#v", manifests)
+			}
+
+			var objects []string
+			for _, m := range manifests {
+				objects = append(objects, m.String())
+			}
+			if !reflect.DeepEqual(objects, tt.Objects) {
+				t.Errorf("Expected %#v, got %#v", tt.Objects, objects)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+)
+
+// Matches "# opencompose:ignore" comments, optionally followed by the rules to ignore
+var ignoreCommentRegexp = regexp.MustCompile(`(^|\s)#\s*opencompose:ignore(\s+([-a-z0-9,\s]*))?\s*$`)
+
+// Ignore suppresses problems of the value a "# opencompose:ignore" comment is attached to
+// and of all the values nested in it
+type Ignore struct {
+	// Paths of the value, both by index and by names of the list items
+	Paths []string
+	// Rules that are ignored, all of them when empty
+	Rules []string
+}
+
+// Reports whether the problem is suppressed
+func (i Ignore) Matches(p Problem) bool {
+	if len(i.Rules) > 0 {
+		found := false
+		for _, rule := range i.Rules {
+			if rule == p.Rule {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, path := range i.Paths {
+		if p.Path == path || strings.HasPrefix(p.Path, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the ignore comments of YAML document. A comment at the end of a line applies to the value
+// on that line, a comment on its own line to the value on the next line, e.g.
+//
+//	# opencompose:ignore image-tag
+//	- name: web
+//	  image: nginx  # opencompose:ignore
+func IgnoreComments(data []byte) []Ignore {
+	lines := strings.Split(string(data), "\n")
+
+	var index encodingutil.PositionIndex
+	var ignores []Ignore
+	for i, line := range lines {
+		match := ignoreCommentRegexp.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+
+		// comment on its own line is attached to the next line that is not empty or a comment
+		target := i
+		if strings.TrimSpace(line[:match[0]]) == "" {
+			target = -1
+			for j := i + 1; j < len(lines); j++ {
+				if trimmed := strings.TrimSpace(lines[j]); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+					target = j
+					break
+				}
+			}
+			if target < 0 {
+				continue
+			}
+		}
+
+		if index == nil {
+			index = encodingutil.IndexPositions(data)
+		}
+
+		var rules []string
+		if match[6] >= 0 {
+			rules = strings.FieldsFunc(line[match[6]:match[7]], func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			})
+		}
+
+		if paths := valuePaths(index, target+1); len(paths) > 0 {
+			ignores = append(ignores, Ignore{Paths: paths, Rules: rules})
+		}
+	}
+	return ignores
+}
+
+// Returns paths of the outermost value starting on the line, e.g. of the list item
+// rather than its first key
+func valuePaths(index encodingutil.PositionIndex, line int) []string {
+	var paths []string
+	depth := -1
+	for path, position := range index {
+		if position.Line != line || path == "" {
+			continue
+		}
+
+		d := strings.Count(path, "/")
+		switch {
+		case depth < 0 || d < depth:
+			depth = d
+			paths = []string{path}
+		case d == depth:
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Returns ignores of the template values mapped to the services that extend the templates, directly
+// or through other templates, as the problems are reported for the services the values end up in.
+// services and templates map their names to the name of the template they extend.
+func TemplateIgnores(ignores []Ignore, services, templates map[string]string) []Ignore {
+	var mapped []Ignore
+	for service, template := range services {
+		servicePath := "/services/" + encodingutil.EscapePathSegment(service)
+
+		// the chain is cut where the templates extend each other
+		visited := make(map[string]bool)
+		for template != "" && !visited[template] {
+			visited[template] = true
+			templatePath := "/templates/" + encodingutil.EscapePathSegment(template)
+
+			for _, ignore := range ignores {
+				var paths []string
+				for _, path := range ignore.Paths {
+					if path == templatePath || strings.HasPrefix(path, templatePath+"/") {
+						paths = append(paths, servicePath+path[len(templatePath):])
+					}
+				}
+				if len(paths) > 0 {
+					mapped = append(mapped, Ignore{Paths: paths, Rules: ignore.Rules})
+				}
+			}
+
+			template = templates[template]
+		}
+	}
+	return mapped
+}
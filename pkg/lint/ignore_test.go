@@ -0,0 +1,105 @@
+package lint
+
+import (
+	"testing"
+)
+
+func TestIgnoreComments(t *testing.T) {
+	data := []byte(`version: 0.1-dev
+services:
+- name: web
+  containers:
+  - name: nginx
+    image: nginx  # opencompose:ignore image-tag
+    env:
+    - name: DB_PASSWORD  # opencompose:ignore
+      value: hunter2
+# opencompose:ignore image-tag, plain-secret-env
+
+- name: worker
+  containers:
+  - name: worker
+    image: worker
+    command: "echo # opencompose:ignore"
+`)
+
+	ignores := IgnoreComments(data)
+
+	tests := []struct {
+		Problem Problem
+		Ignored bool
+	}{
+		{Problem{Rule: "image-tag", Path: "/services/web/containers/nginx/image"}, true},
+		{Problem{Rule: "plain-secret-env", Path: "/services/web/containers/nginx/image"}, false},
+		{Problem{Rule: "plain-secret-env", Path: "/services/web/containers/nginx/env/DB_PASSWORD/value"}, true},
+		{Problem{Rule: "image-tag", Path: "/services/worker/containers/worker/image"}, true},
+		{Problem{Rule: "shared-rwo-volume", Path: "/services/worker/replicas"}, false},
+		{Problem{Rule: "image-tag", Path: "/services/web/containers/nginx-old/image"}, false},
+		{Problem{Rule: "image-tag", Path: "/services/web/containers/nginx/command"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Problem.Rule+tt.Problem.Path, func(t *testing.T) {
+			ignored := false
+			for _, ignore := range ignores {
+				if ignore.Matches(tt.Problem) {
+					ignored = true
+				}
+			}
+			if ignored != tt.Ignored {
+				t.Errorf("Expected ignored to be %v, got %v; ignores: %#v", tt.Ignored, ignored, ignores)
+			}
+		})
+	}
+}
+
+func TestTemplateIgnores(t *testing.T) {
+	data := []byte(`version: 0.1-dev
+templates:
+  base:
+    containers:
+    - name: nginx
+      image: nginx  # opencompose:ignore image-tag
+  web:
+    extends: base
+services:
+- name: frontend
+  extends: web
+- name: backend
+  extends: base
+- name: other
+  containers:
+  - name: nginx
+    image: nginx
+`)
+
+	ignores := IgnoreComments(data)
+	ignores = append(ignores, TemplateIgnores(ignores, map[string]string{"frontend": "web", "backend": "base"}, map[string]string{"web": "base"})...)
+
+	tests := []struct {
+		Problem Problem
+		Ignored bool
+	}{
+		{Problem{Rule: "image-tag", Path: "/services/frontend/containers/nginx/image"}, true},
+		{Problem{Rule: "image-tag", Path: "/services/backend/containers/nginx/image"}, true},
+		{Problem{Rule: "image-tag", Path: "/services/other/containers/nginx/image"}, false},
+		{Problem{Rule: "plain-secret-env", Path: "/services/frontend/containers/nginx/image"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Problem.Rule+tt.Problem.Path, func(t *testing.T) {
+			ignored := false
+			for _, ignore := range ignores {
+				if ignore.Matches(tt.Problem) {
+					ignored = true
+				}
+			}
+			if ignored != tt.Ignored {
+				t.Errorf("Expected ignored to be %v, got %v; ignores: %#v", tt.Ignored, ignored, ignores)
+			}
+		})
+	}
+
+	// templates extending each other don't loop
+	TemplateIgnores(ignores, map[string]string{"frontend": "a"}, map[string]string{"a": "b", "b": "a"})
+}
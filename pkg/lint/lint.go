@@ -0,0 +1,182 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+type Severity int
+
+const (
+	// Rule is disabled
+	Severity_Off Severity = iota
+	Severity_Warning
+	Severity_Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Severity_Off:
+		return "off"
+	case Severity_Warning:
+		return "warning"
+	case Severity_Error:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range []Severity{Severity_Off, Severity_Warning, Severity_Error} {
+		if s == severity.String() {
+			return severity, nil
+		}
+	}
+	return Severity_Off, fmt.Errorf("unknown severity %q, must be either %q, %q or %q", s, Severity_Off, Severity_Warning, Severity_Error)
+}
+
+// Problem is a place where OpenCompose doesn't follow the practice a rule checks
+type Problem struct {
+	Rule     string
+	Code     pkgutil.ErrorCode
+	Severity Severity
+	// JSON pointer to the value, items of lists are identified by their names
+	Path    string
+	Message string
+}
+
+// Reports problem at the value at segments
+type reportFunc func(segments []string, format string, args ...interface{})
+
+// Rule checks for a practice that is not required for OpenCompose to be valid
+// but leads to problems once the application is deployed
+type Rule struct {
+	Name        string
+	Code        pkgutil.ErrorCode
+	Description string
+	// Severity the rule has unless configured otherwise
+	Severity Severity
+	Check    func(o *object.OpenCompose, report reportFunc)
+}
+
+// Returns the rule with the name or nil if there is no such rule
+func GetRule(name string) *Rule {
+	for i := range Rules {
+		if Rules[i].Name == name {
+			return &Rules[i]
+		}
+	}
+	return nil
+}
+
+// Config changes severity of the rules, rules set to "off" are disabled
+type Config struct {
+	Rules map[string]Severity
+}
+
+// Config file as it is written, e.g.
+//
+//	rules:
+//	  image-tag: error
+//	  plain-secret-env: off
+type configFile struct {
+	Rules map[string]string `yaml:"rules"`
+}
+
+// Loads config from YAML, the rules that are not listed keep their default severity
+func LoadConfig(data []byte) (*Config, error) {
+	var file configFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lint config: %s", err)
+	}
+
+	config := &Config{Rules: make(map[string]Severity)}
+	for name, value := range file.Rules {
+		if GetRule(name) == nil {
+			return nil, fmt.Errorf("lint config: unknown rule %q", name)
+		}
+
+		severity, err := ParseSeverity(value)
+		if err != nil {
+			return nil, fmt.Errorf("lint config: rule %q: %s", name, err)
+		}
+		config.Rules[name] = severity
+	}
+	return config, nil
+}
+
+// Returns severity of the rule with the config applied, config can be nil
+func (c *Config) severity(rule *Rule) Severity {
+	if c != nil {
+		if severity, ok := c.Rules[rule.Name]; ok {
+			return severity
+		}
+	}
+	return rule.Severity
+}
+
+// Sorts problems by path so they are reported in a stable order
+type byPath []Problem
+
+func (p byPath) Len() int      { return len(p) }
+func (p byPath) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p byPath) Less(i, j int) bool {
+	if p[i].Path != p[j].Path {
+		return p[i].Path < p[j].Path
+	}
+	return p[i].Rule < p[j].Rule
+}
+
+// Runs the enabled rules over OpenCompose, config can be nil for the default severities
+func Lint(o *object.OpenCompose, config *Config) []Problem {
+	var problems []Problem
+	for i := range Rules {
+		rule := &Rules[i]
+		severity := config.severity(rule)
+		if severity == Severity_Off {
+			continue
+		}
+
+		rule.Check(o, func(segments []string, format string, args ...interface{}) {
+			problems = append(problems, Problem{
+				Rule:     rule.Name,
+				Code:     rule.Code,
+				Severity: severity,
+				Path:     joinPath(segments...),
+				Message:  fmt.Sprintf(format, args...),
+			})
+		})
+	}
+
+	sort.Stable(byPath(problems))
+	return problems
+}
+
+// Returns the highest severity of the problems, Severity_Off when there are none
+func MaxSeverity(problems []Problem) Severity {
+	max := Severity_Off
+	for _, p := range problems {
+		if p.Severity > max {
+			max = p.Severity
+		}
+	}
+	return max
+}
+
+func joinPath(segments ...string) string {
+	var escaped []string
+	for _, s := range segments {
+		escaped = append(escaped, encodingutil.EscapePathSegment(s))
+	}
+	if len(escaped) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(escaped, "/")
+}
@@ -0,0 +1,168 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Succeed bool
+		Config  string
+		Rules   map[string]Severity
+	}{
+		{"Empty", true, "", map[string]Severity{}},
+		{"Severities", true, "rules:\n  image-tag: error\n  plain-secret-env: off\n", map[string]Severity{"image-tag": Severity_Error, "plain-secret-env": Severity_Off}},
+		{"Unknown rule", false, "rules:\n  foo: error\n", nil},
+		{"Unknown severity", false, "rules:\n  image-tag: fatal\n", nil},
+		{"Invalid YAML", false, "rules: [", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			config, err := LoadConfig([]byte(tt.Config))
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to load config: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %#v", config)
+			}
+
+			if !reflect.DeepEqual(config.Rules, tt.Rules) {
+				t.Errorf("Expected rules %#v, got %#v", tt.Rules, config.Rules)
+			}
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Object   *object.OpenCompose
+		Config   *Config
+		Problems []string
+	}{
+		{
+			"Image tags",
+			&object.OpenCompose{
+				Services: []object.Service{
+					{
+						Name: "web",
+						Containers: []object.Container{
+							{Name: "untagged", Image: "nginx"},
+							{Name: "latest", Image: "nginx:latest"},
+							{Name: "tagged", Image: "nginx:1.11"},
+							{Name: "digest", Image: "nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+							{Name: "built", Image: "app", Build: &object.Build{Git: object.GitSource{URL: "https://github.com/foo/bar.git"}}},
+						},
+					},
+				},
+			},
+			nil,
+			[]string{
+				"image-tag /services/web/containers/latest/image warning",
+				"image-tag /services/web/containers/untagged/image warning",
+			},
+		},
+		{
+			"ReadWriteOnce volume shared by replicas and services",
+			&object.OpenCompose{
+				Services: []object.Service{
+					{
+						Name:       "web",
+						Replicas:   goutil.Int32Addr(2),
+						Containers: []object.Container{{Name: "web", Image: "web:v1", Mounts: []object.Mount{{VolumeRef: "data", MountPath: "/data"}}}},
+					},
+					{
+						Name:       "worker",
+						Containers: []object.Container{{Name: "worker", Image: "worker:v1", Mounts: []object.Mount{{VolumeRef: "data", MountPath: "/data"}}}},
+					},
+					{
+						Name:            "cache",
+						Replicas:        goutil.Int32Addr(3),
+						Containers:      []object.Container{{Name: "cache", Image: "cache:v1", Mounts: []object.Mount{{VolumeRef: "data", MountPath: "/data"}}}},
+						EmptyDirVolumes: []object.EmptyDirVolume{{Name: "data"}},
+					},
+				},
+				Volumes: []object.Volume{
+					{Name: "data", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+			nil,
+			[]string{
+				"shared-rwo-volume /services/web/replicas warning",
+				"shared-rwo-volume /volumes/data/accessMode warning",
+			},
+		},
+		{
+			"Secrets in environment",
+			&object.OpenCompose{
+				Services: []object.Service{
+					{
+						Name: "web",
+						Containers: []object.Container{
+							{
+								Name:  "web",
+								Image: "web:v1",
+								Environment: []object.EnvVariable{
+									{Key: "DB_PASSWORD", Value: "hunter2"},
+									{Key: "api_key", Value: "abc"},
+									{Key: "EMPTY_SECRET", Value: ""},
+									{Key: "DB_USER", Value: "admin"},
+								},
+								Build: &object.Build{
+									Git:  object.GitSource{URL: "https://github.com/foo/bar.git"},
+									Args: []object.EnvVariable{{Key: "NPM_TOKEN", Value: "abc"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			&Config{Rules: map[string]Severity{"plain-secret-env": Severity_Error}},
+			[]string{
+				"plain-secret-env /services/web/containers/web/build/args/NPM_TOKEN/value error",
+				"plain-secret-env /services/web/containers/web/env/DB_PASSWORD/value error",
+				"plain-secret-env /services/web/containers/web/env/api_key/value error",
+			},
+		},
+		{
+			"Disabled rule",
+			&object.OpenCompose{
+				Services: []object.Service{
+					{Name: "web", Containers: []object.Container{{Name: "web", Image: "nginx"}}},
+				},
+			},
+			&Config{Rules: map[string]Severity{"image-tag": Severity_Off}},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var problems []string
+			for _, p := range Lint(tt.Object, tt.Config) {
+				problems = append(problems, p.Rule+" "+p.Path+" "+p.Severity.String())
+			}
+			if !reflect.DeepEqual(problems, tt.Problems) {
+				t.Errorf("Expected problems\n%#v\ngot\n%#v", tt.Problems, problems)
+			}
+		})
+	}
+}
+
+func TestMaxSeverity(t *testing.T) {
+	if s := MaxSeverity(nil); s != Severity_Off {
+		t.Errorf("Expected %s without problems, got %s", Severity_Off, s)
+	}
+	if s := MaxSeverity([]Problem{{Severity: Severity_Warning}, {Severity: Severity_Error}, {Severity: Severity_Warning}}); s != Severity_Error {
+		t.Errorf("Expected %s, got %s", Severity_Error, s)
+	}
+}
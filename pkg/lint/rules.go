@@ -0,0 +1,139 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-developer/opencompose/pkg/object"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+)
+
+// Codes of the problems, one for each rule
+const (
+	ErrorCode_ImageTag       pkgutil.ErrorCode = "OC4001"
+	ErrorCode_SharedRWO      pkgutil.ErrorCode = "OC4002"
+	ErrorCode_PlainSecretEnv pkgutil.ErrorCode = "OC4003"
+)
+
+// All the rules, in the order they are run
+var Rules = []Rule{
+	{
+		Name:        "image-tag",
+		Code:        ErrorCode_ImageTag,
+		Description: "The image has no tag or uses 'latest', so the deployed version changes with every push",
+		Severity:    Severity_Warning,
+		Check:       checkImageTag,
+	},
+	{
+		Name:        "shared-rwo-volume",
+		Code:        ErrorCode_SharedRWO,
+		Description: "The ReadWriteOnce volume is mounted by more than one pod, it can be attached to a single node only",
+		Severity:    Severity_Warning,
+		Check:       checkSharedRWO,
+	},
+	{
+		Name:        "plain-secret-env",
+		Code:        ErrorCode_PlainSecretEnv,
+		Description: "The environment variable looks like a secret, its value is written in plain text",
+		Severity:    Severity_Warning,
+		Check:       checkPlainSecretEnv,
+	},
+}
+
+// Images are pinned by a tag other than "latest" or by digest. Built images are tagged by the build.
+func checkImageTag(o *object.OpenCompose, report reportFunc) {
+	for _, s := range o.Services {
+		for _, c := range s.Containers {
+			if c.Build != nil {
+				continue
+			}
+
+			image, err := c.ImageRef()
+			if err != nil || image.Digest != "" {
+				continue
+			}
+
+			switch image.Tag {
+			case "":
+				report([]string{"services", s.Name, "containers", c.Name, "image"}, "image %q has no tag, it defaults to 'latest'", c.Image)
+			case "latest":
+				report([]string{"services", s.Name, "containers", c.Name, "image"}, "image %q uses tag 'latest'", c.Image)
+			}
+		}
+	}
+}
+
+// ReadWriteOnce volumes can be attached to one node only, so pods of more replicas or services
+// mounting the same volume can't be scheduled on different nodes
+func checkSharedRWO(o *object.OpenCompose, report reportFunc) {
+	for _, v := range o.Volumes {
+		if v.AccessMode != "ReadWriteOnce" {
+			continue
+		}
+
+		var services []string
+		for _, s := range o.Services {
+			if !mountsVolume(&s, v.Name) {
+				continue
+			}
+			services = append(services, strconv.Quote(s.Name))
+
+			if s.Replicas != nil && *s.Replicas > 1 {
+				report([]string{"services", s.Name, "replicas"}, "%d replicas mount ReadWriteOnce volume %q", *s.Replicas, v.Name)
+			}
+		}
+
+		if len(services) > 1 {
+			report([]string{"volumes", v.Name, "accessMode"}, "ReadWriteOnce volume %q is mounted by services %s", v.Name, strings.Join(services, ", "))
+		}
+	}
+}
+
+// Reports whether a container of the service mounts the volume from the top level volumes
+func mountsVolume(s *object.Service, volume string) bool {
+	if s.EmptyDirVolumeExists(volume) {
+		return false
+	}
+	for _, c := range s.Containers {
+		for _, m := range c.Mounts {
+			if m.VolumeRef == volume {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Names of environment variables that usually hold secrets
+var secretEnvNameRegexp = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|CREDENTIALS?)`)
+
+// Secrets are not written into the file, they end up in plain text in the generated objects as well
+func checkPlainSecretEnv(o *object.OpenCompose, report reportFunc) {
+	check := func(env []object.EnvVariable, kind string, segments ...string) {
+		for _, e := range env {
+			if e.Value != "" && secretEnvNameRegexp.MatchString(e.Key) {
+				report(append(append([]string{}, segments...), e.Key, "value"), "%s %q looks like a secret but its value is written in plain text", kind, e.Key)
+			}
+		}
+	}
+
+	for _, s := range o.Services {
+		for _, c := range s.Containers {
+			check(c.Environment, "environment variable", "services", s.Name, "containers", c.Name, "env")
+			if c.Build != nil {
+				check(c.Build.Args, "build arg", "services", s.Name, "containers", c.Name, "build", "args")
+			}
+		}
+	}
+}
+
+// Returns descriptions of the codes of all the rules
+func ErrorCodeDescriptions() map[pkgutil.ErrorCode]string {
+	descriptions := make(map[pkgutil.ErrorCode]string)
+	for _, rule := range Rules {
+		descriptions[rule.Code] = fmt.Sprintf("%s (%s)", rule.Description, rule.Name)
+	}
+	return descriptions
+}
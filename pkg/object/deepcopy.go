@@ -0,0 +1,169 @@
+package object
+
+func copyStringPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func copyInt32Ptr(i *int32) *int32 {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	return &c
+}
+
+func copyBoolPtr(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	return &c
+}
+
+func (c *Container) DeepCopy() Container {
+	out := *c
+
+	if c.Command != nil {
+		out.Command = make([]string, len(c.Command))
+		copy(out.Command, c.Command)
+	}
+
+	if c.Args != nil {
+		out.Args = make([]string, len(c.Args))
+		copy(out.Args, c.Args)
+	}
+
+	if c.Environment != nil {
+		out.Environment = make([]EnvVariable, len(c.Environment))
+		copy(out.Environment, c.Environment)
+	}
+
+	if c.Ports != nil {
+		out.Ports = make([]Port, len(c.Ports))
+		for i, p := range c.Ports {
+			out.Ports[i] = p
+			out.Ports[i].Host = copyStringPtr(p.Host)
+		}
+	}
+
+	if c.Mounts != nil {
+		out.Mounts = make([]Mount, len(c.Mounts))
+		copy(out.Mounts, c.Mounts)
+	}
+
+	if c.ParsedImage != nil {
+		image := *c.ParsedImage
+		out.ParsedImage = &image
+	}
+
+	if c.Build != nil {
+		b := *c.Build
+		if c.Build.Args != nil {
+			b.Args = make([]EnvVariable, len(c.Build.Args))
+			copy(b.Args, c.Build.Args)
+		}
+		out.Build = &b
+	}
+
+	return out
+}
+
+func (s *Service) DeepCopy() Service {
+	out := *s
+
+	if s.Containers != nil {
+		out.Containers = make([]Container, len(s.Containers))
+		for i := range s.Containers {
+			out.Containers[i] = s.Containers[i].DeepCopy()
+		}
+	}
+
+	out.Replicas = copyInt32Ptr(s.Replicas)
+
+	if s.EmptyDirVolumes != nil {
+		out.EmptyDirVolumes = make([]EmptyDirVolume, len(s.EmptyDirVolumes))
+		copy(out.EmptyDirVolumes, s.EmptyDirVolumes)
+	}
+
+	if s.Labels != nil {
+		out.Labels = make(Labels, len(s.Labels))
+		for k, v := range s.Labels {
+			out.Labels[k] = v
+		}
+	}
+
+	out.Enabled = copyBoolPtr(s.Enabled)
+
+	return out
+}
+
+func (v *Volume) DeepCopy() Volume {
+	out := *v
+	out.StorageClass = copyStringPtr(v.StorageClass)
+	return out
+}
+
+func deepCopyServices(services []Service) []Service {
+	if services == nil {
+		return nil
+	}
+	out := make([]Service, len(services))
+	for i := range services {
+		out[i] = services[i].DeepCopy()
+	}
+	return out
+}
+
+func deepCopyVolumes(volumes []Volume) []Volume {
+	if volumes == nil {
+		return nil
+	}
+	out := make([]Volume, len(volumes))
+	for i := range volumes {
+		out[i] = volumes[i].DeepCopy()
+	}
+	return out
+}
+
+// Returns copy of OpenCompose that doesn't share any data with the original one
+// so it can be modified (e.g. merged) independently
+func (o *OpenCompose) DeepCopy() *OpenCompose {
+	out := &OpenCompose{
+		Version:  o.Version,
+		Services: deepCopyServices(o.Services),
+		Volumes:  deepCopyVolumes(o.Volumes),
+	}
+
+	if o.Include != nil {
+		out.Include = make([]Include, len(o.Include))
+		copy(out.Include, o.Include)
+	}
+
+	if o.Parameters != nil {
+		out.Parameters = make([]Parameter, len(o.Parameters))
+		copy(out.Parameters, o.Parameters)
+	}
+
+	if o.Templates != nil {
+		out.Templates = make(map[string]Service, len(o.Templates))
+		for name, t := range o.Templates {
+			out.Templates[name] = t.DeepCopy()
+		}
+	}
+
+	if o.Profiles != nil {
+		out.Profiles = make(map[string]Profile, len(o.Profiles))
+		for name, p := range o.Profiles {
+			out.Profiles[name] = Profile{
+				Services: deepCopyServices(p.Services),
+				Volumes:  deepCopyVolumes(p.Volumes),
+			}
+		}
+	}
+
+	return out
+}
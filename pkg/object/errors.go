@@ -0,0 +1,108 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+)
+
+// FieldError is an error of the value at Path in the OpenCompose file, e.g. "/services/web/replicas".
+// Items of lists are identified by their names where they have one, otherwise by their index.
+type FieldError struct {
+	Path      string
+	ErrorCode pkgutil.ErrorCode
+	Err       error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Code() pkgutil.ErrorCode {
+	return e.ErrorCode
+}
+
+// Codes of the errors found by Validate
+const (
+	ErrorCode_InvalidName          pkgutil.ErrorCode = "OC2001"
+	ErrorCode_InvalidEnvVariable   pkgutil.ErrorCode = "OC2002"
+	ErrorCode_InvalidMountPath     pkgutil.ErrorCode = "OC2003"
+	ErrorCode_DuplicateMountPath   pkgutil.ErrorCode = "OC2004"
+	ErrorCode_UndefinedVolume      pkgutil.ErrorCode = "OC2005"
+	ErrorCode_InvalidGitSource     pkgutil.ErrorCode = "OC2006"
+	ErrorCode_InvalidDockerfile    pkgutil.ErrorCode = "OC2007"
+	ErrorCode_BuiltImageDigest     pkgutil.ErrorCode = "OC2008"
+	ErrorCode_NegativeReplicas     pkgutil.ErrorCode = "OC2009"
+	ErrorCode_InvalidLabelValue    pkgutil.ErrorCode = "OC2010"
+	ErrorCode_InvalidSize          pkgutil.ErrorCode = "OC2011"
+	ErrorCode_InvalidAccessMode    pkgutil.ErrorCode = "OC2012"
+	ErrorCode_InvalidParameterName pkgutil.ErrorCode = "OC2013"
+	ErrorCode_DuplicateParameter   pkgutil.ErrorCode = "OC2014"
+	ErrorCode_InvalidImage         pkgutil.ErrorCode = "OC2015"
+	ErrorCode_DuplicateEnvVariable pkgutil.ErrorCode = "OC2016"
+	ErrorCode_InvalidPort          pkgutil.ErrorCode = "OC2018"
+	ErrorCode_DuplicateServicePort pkgutil.ErrorCode = "OC2019"
+)
+
+// Codes of the problems found by Warnings
+const (
+	ErrorCode_NonPortableEnvVariable pkgutil.ErrorCode = "OC2017"
+)
+
+var ErrorCodeDescriptions = map[pkgutil.ErrorCode]string{
+	ErrorCode_InvalidName:            "The name is not valid for the Kubernetes objects named after it",
+	ErrorCode_InvalidEnvVariable:     "The environment variable is not valid",
+	ErrorCode_InvalidMountPath:       "The mount path is not absolute",
+	ErrorCode_DuplicateMountPath:     "Another volume is mounted at the same path",
+	ErrorCode_UndefinedVolume:        "The mount refers to a volume that is not defined",
+	ErrorCode_InvalidGitSource:       "The git URL or context directory is not valid",
+	ErrorCode_InvalidDockerfile:      "The Dockerfile path is not relative to the context directory",
+	ErrorCode_BuiltImageDigest:       "The image that is built is referenced by digest",
+	ErrorCode_NegativeReplicas:       "The number of replicas is negative",
+	ErrorCode_InvalidLabelValue:      "The label value is not valid",
+	ErrorCode_InvalidSize:            "The volume size is not a valid quantity",
+	ErrorCode_InvalidAccessMode:      "The volume access mode is not known",
+	ErrorCode_InvalidParameterName:   "The parameter name is not valid",
+	ErrorCode_DuplicateParameter:     "The parameter is defined more than once",
+	ErrorCode_InvalidImage:           "The image reference is not valid",
+	ErrorCode_DuplicateEnvVariable:   "The environment variable is defined more than once",
+	ErrorCode_NonPortableEnvVariable: "The environment variable name is not portable",
+	ErrorCode_InvalidPort:            "The port number is not between 1 and 65535",
+	ErrorCode_DuplicateServicePort:   "Another port of the service uses the same service port",
+}
+
+func joinPath(segments []string) string {
+	path := ""
+	for _, s := range segments {
+		// "~" and "/" are escaped the same way as in JSON pointer
+		path += "/" + strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+	}
+	return path
+}
+
+func fieldErrorf(code pkgutil.ErrorCode, segments []string, format string, args ...interface{}) error {
+	return &FieldError{Path: joinPath(segments), ErrorCode: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Returns errors of err (which can be pkgutil.ErrorList) for the value at segments
+// with prefix added to their messages, the paths of the errors are relative to that value
+func wrapFieldError(err error, prefix string, segments ...string) error {
+	var errs pkgutil.ErrorList
+	for _, e := range pkgutil.Errors(err) {
+		path := joinPath(segments)
+		code := pkgutil.CodeOf(e)
+		if fe, ok := e.(*FieldError); ok {
+			path += fe.Path
+		}
+
+		message := e.Error()
+		if prefix != "" {
+			message = prefix + ": " + message
+		}
+
+		errs = append(errs, &FieldError{Path: path, ErrorCode: code, Err: errors.New(message)})
+	}
+	return errs.Err()
+}
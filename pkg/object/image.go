@@ -0,0 +1,86 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+)
+
+// ImageRef is an image reference split into its parts following the docker reference grammar:
+//
+//	reference := [registry '/'] repository [':' tag] ['@' digest]
+//
+// e.g. "registry.example.com:5000/team/app:1.0" or "nginx@sha256:...".
+// Parts that are not given are left empty, no defaults are filled in.
+type ImageRef struct {
+	// Hostname of the registry with an optional port, e.g. "registry.example.com:5000".
+	// The first component is a registry only if it contains '.' or ':' or is "localhost".
+	Registry string
+	// Path of the repository in the registry, e.g. "team/app" or "nginx"
+	Repository string
+	Tag        string
+	// Content addressable digest, e.g. "sha256:..."
+	Digest string
+}
+
+// Regular expression of the image references in the syntax of JSON Schema (ECMA 262)
+var ImageRefPattern = strings.Replace(reference.ReferenceRegexp.String(), "[[:xdigit:]]", "[0-9a-fA-F]", -1)
+
+// Parses image reference, malformed references (e.g. with uppercase letters in the repository
+// or an invalid tag) are rejected
+func ParseImageRef(image string) (*ImageRef, error) {
+	named, err := reference.ParseNamed(image)
+	if err != nil {
+		// the grammar allows only lowercase repositories but the error doesn't say so
+		if _, lowerErr := reference.ParseNamed(strings.ToLower(image)); lowerErr == nil {
+			return nil, fmt.Errorf("invalid image reference %q: repository name must be lowercase", image)
+		}
+		return nil, fmt.Errorf("invalid image reference %q: %s", image, err)
+	}
+
+	r := &ImageRef{}
+	r.Registry, r.Repository = splitRegistry(named.Name())
+	if tagged, ok := named.(reference.Tagged); ok {
+		r.Tag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		r.Digest = digested.Digest().String()
+	}
+
+	return r, nil
+}
+
+// Splits name into registry and repository, the first component is a registry only if
+// it looks like a hostname. reference.SplitHostname takes any first component.
+func splitRegistry(name string) (string, string) {
+	i := strings.Index(name, "/")
+	if i < 0 {
+		return "", name
+	}
+
+	first := name[:i]
+	if first == "localhost" || strings.ContainsAny(first, ".:") {
+		return first, name[i+1:]
+	}
+	return "", name
+}
+
+// Name is the reference without tag and digest, i.e. the registry and repository
+func (r *ImageRef) Name() string {
+	if r.Registry == "" {
+		return r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+func (r *ImageRef) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseImageRef(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		Image    string
+		Succeed  bool
+		Expected *ImageRef
+	}{
+		{"nginx", true, &ImageRef{Repository: "nginx"}},
+		{"nginx:1.11", true, &ImageRef{Repository: "nginx", Tag: "1.11"}},
+		{"library/nginx:latest", true, &ImageRef{Repository: "library/nginx", Tag: "latest"}},
+		{"docker.io/library/nginx:1.11", true, &ImageRef{Registry: "docker.io", Repository: "library/nginx", Tag: "1.11"}},
+		{"localhost/app", true, &ImageRef{Registry: "localhost", Repository: "app"}},
+		{"registry.example.com:5000/team/app:v1", true, &ImageRef{Registry: "registry.example.com:5000", Repository: "team/app", Tag: "v1"}},
+		{"app@" + digest, true, &ImageRef{Repository: "app", Digest: digest}},
+		{"app:v1@" + digest, true, &ImageRef{Repository: "app", Tag: "v1", Digest: digest}},
+		{"", false, nil},
+		{"Nginx", false, nil},
+		{"nginx:", false, nil},
+		{"nginx:1.11:2", false, nil},
+		{"nginx@sha256:123", false, nil},
+		{"-nginx", false, nil},
+		{"team//app", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Image, func(t *testing.T) {
+			ref, err := ParseImageRef(tt.Image)
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to parse: %s", err)
+				}
+				t.Logf("Failed with error: %s", err)
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %#v", ref)
+			}
+
+			if !reflect.DeepEqual(ref, tt.Expected) {
+				t.Fatalf("Expected %#v, got %#v", tt.Expected, ref)
+			}
+
+			if ref.String() != tt.Image {
+				t.Errorf("Expected %q, got %q", tt.Image, ref.String())
+			}
+		})
+	}
+}
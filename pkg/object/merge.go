@@ -0,0 +1,415 @@
+package object
+
+import (
+	"fmt"
+)
+
+// Merging OpenCompose objects
+//
+// Services, volumes, parameters, containers and emptyDirVolumes are matched by name,
+// environment variables by key, ports by container port, mounts by mount path, labels,
+// templates and profiles by key. Matched items are merged, the new ones are appended.
+// Scalar fields set in the override replace the original values while unset (empty) ones keep them.
+// Items that are not matched have to be complete because the override files are
+// decoded without checking for the required fields, the only exception is merging
+// of templates and profiles which are partial by definition.
+
+func checkDuplicateNames(what string, names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("%s %q: defined more than once, can't decide which one to merge with", what, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Checks environment and ports for the required fields, these are always
+// complete items, merged or not
+func (c *Container) checkItemsComplete() error {
+	for _, e := range c.Environment {
+		if e.Key == "" {
+			return fmt.Errorf("%s", "environment variable without 'name'")
+		}
+	}
+	for _, p := range c.Ports {
+		if p.Port.ContainerPort == 0 {
+			return fmt.Errorf("%s", "ports entry without 'port'")
+		}
+	}
+	return nil
+}
+
+func (c *Container) checkComplete() error {
+	if c.Name == "" {
+		return fmt.Errorf("%s", "container without 'name' can't be merged with any container")
+	}
+	if c.Image == "" {
+		return fmt.Errorf("container %q: is not defined before so it has to have 'image'", c.Name)
+	}
+	if err := c.checkItemsComplete(); err != nil {
+		return fmt.Errorf("container %q: %v", c.Name, err)
+	}
+	return nil
+}
+
+func (s *Service) checkComplete() error {
+	// the missing fields can come from the template, it is checked once the template is resolved
+	if s.Extends != "" {
+		return nil
+	}
+
+	if len(s.Containers) == 0 {
+		return fmt.Errorf("service %q: is not defined before so it has to have 'containers'", s.Name)
+	}
+	for _, c := range s.Containers {
+		if err := c.checkComplete(); err != nil {
+			return fmt.Errorf("service %q: %v", s.Name, err)
+		}
+	}
+	return nil
+}
+
+func (v *Volume) checkComplete() error {
+	if v.Size == "" || v.AccessMode == "" {
+		return fmt.Errorf("volume %q: is not defined before so it has to have 'size' and 'accessMode'", v.Name)
+	}
+	return nil
+}
+
+func (e *EnvVariable) merge(override *EnvVariable) {
+	e.Value = override.Value
+}
+
+func (p *Port) merge(override *Port) {
+	// port is defined by all its fields together so it is replaced as a whole
+	*p = *override
+}
+
+func (m *Mount) merge(override *Mount) {
+	*m = *override
+}
+
+func (c *Container) merge(override *Container) error {
+	if err := override.checkItemsComplete(); err != nil {
+		return err
+	}
+
+	if override.Image != "" {
+		c.Image = override.Image
+		c.ParsedImage = override.ParsedImage
+	}
+
+	// command and args are replaced as a whole, merging the arguments wouldn't make sense
+	if override.Command != nil {
+		c.Command = override.Command
+	}
+	if override.Args != nil {
+		c.Args = override.Args
+	}
+
+	var keys []string
+	for _, e := range override.Environment {
+		keys = append(keys, e.Key)
+	}
+	if err := checkDuplicateNames("environment variable", keys); err != nil {
+		return err
+	}
+	for _, oe := range override.Environment {
+		found := false
+		for i := range c.Environment {
+			if c.Environment[i].Key == oe.Key {
+				c.Environment[i].merge(&oe)
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.Environment = append(c.Environment, oe)
+		}
+	}
+
+	for _, op := range override.Ports {
+		found := false
+		for i := range c.Ports {
+			if c.Ports[i].Port.ContainerPort == op.Port.ContainerPort {
+				c.Ports[i].merge(&op)
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.Ports = append(c.Ports, op)
+		}
+	}
+
+	for _, om := range override.Mounts {
+		found := false
+		for i := range c.Mounts {
+			if c.Mounts[i].MountPath == om.MountPath {
+				c.Mounts[i].merge(&om)
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.Mounts = append(c.Mounts, om)
+		}
+	}
+
+	if override.Build != nil {
+		c.Build = override.Build
+	}
+
+	return nil
+}
+
+func (s *Service) merge(override *Service, requireComplete bool) error {
+	var names []string
+	for _, c := range s.Containers {
+		names = append(names, c.Name)
+	}
+	if err := checkDuplicateNames("container", names); err != nil {
+		return err
+	}
+	names = nil
+	for _, c := range override.Containers {
+		names = append(names, c.Name)
+	}
+	if err := checkDuplicateNames("container", names); err != nil {
+		return err
+	}
+
+	for _, oc := range override.Containers {
+		found := false
+		for i := range s.Containers {
+			if s.Containers[i].Name == oc.Name {
+				if err := s.Containers[i].merge(&oc); err != nil {
+					return fmt.Errorf("container %q: %v", oc.Name, err)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			if requireComplete {
+				if err := oc.checkComplete(); err != nil {
+					return err
+				}
+			}
+			s.Containers = append(s.Containers, oc)
+		}
+	}
+
+	if override.Replicas != nil {
+		s.Replicas = override.Replicas
+	}
+
+	if override.Enabled != nil {
+		s.Enabled = override.Enabled
+	}
+
+	if override.Extends != "" {
+		s.Extends = override.Extends
+	}
+
+	for _, oe := range override.EmptyDirVolumes {
+		if !s.EmptyDirVolumeExists(oe.Name) {
+			s.EmptyDirVolumes = append(s.EmptyDirVolumes, oe)
+		}
+	}
+
+	if len(override.Labels) > 0 {
+		labels := make(Labels)
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		for k, v := range override.Labels {
+			labels[k] = v
+		}
+		s.Labels = labels
+	}
+
+	return nil
+}
+
+func (v *Volume) merge(override *Volume) {
+	if override.Size != "" {
+		v.Size = override.Size
+	}
+
+	if override.AccessMode != "" {
+		v.AccessMode = override.AccessMode
+	}
+
+	if override.StorageClass != nil {
+		v.StorageClass = override.StorageClass
+	}
+}
+
+func (p *Parameter) merge(override *Parameter) {
+	if override.DisplayName != "" {
+		p.DisplayName = override.DisplayName
+	}
+
+	if override.Description != "" {
+		p.Description = override.Description
+	}
+
+	if override.Value != "" {
+		p.Value = override.Value
+	}
+
+	if override.Required {
+		p.Required = true
+	}
+}
+
+func (o *OpenCompose) checkDuplicateNames() error {
+	var names []string
+	for _, s := range o.Services {
+		names = append(names, s.Name)
+	}
+	if err := checkDuplicateNames("service", names); err != nil {
+		return err
+	}
+
+	names = nil
+	for _, v := range o.Volumes {
+		names = append(names, v.Name)
+	}
+	if err := checkDuplicateNames("volume", names); err != nil {
+		return err
+	}
+
+	names = nil
+	for _, p := range o.Parameters {
+		names = append(names, p.Name)
+	}
+	return checkDuplicateNames("parameter", names)
+}
+
+func (p *Profile) merge(override *Profile) error {
+	// profile has the same structure as the top level so it can be merged the same way
+	po := &OpenCompose{Services: p.Services, Volumes: p.Volumes}
+	if err := po.merge(&OpenCompose{Services: override.Services, Volumes: override.Volumes}, false); err != nil {
+		return err
+	}
+
+	p.Services = po.Services
+	p.Volumes = po.Volumes
+
+	return nil
+}
+
+// Merges override into the receiver.
+// The result isn't validated, call Validate once all the objects are merged.
+func (o *OpenCompose) Merge(override *OpenCompose) error {
+	if err := o.merge(override, true); err != nil {
+		return err
+	}
+
+	for name, ot := range override.Templates {
+		t, ok := o.Templates[name]
+		if !ok {
+			if o.Templates == nil {
+				o.Templates = make(map[string]Service)
+			}
+			o.Templates[name] = ot
+			continue
+		}
+
+		if err := t.merge(&ot, false); err != nil {
+			return fmt.Errorf("template %q: %v", name, err)
+		}
+		o.Templates[name] = t
+	}
+
+	for name, op := range override.Profiles {
+		p, ok := o.Profiles[name]
+		if !ok {
+			if o.Profiles == nil {
+				o.Profiles = make(map[string]Profile)
+			}
+			o.Profiles[name] = op
+			continue
+		}
+
+		if err := p.merge(&op); err != nil {
+			return fmt.Errorf("profile %q: %v", name, err)
+		}
+		o.Profiles[name] = p
+	}
+
+	return nil
+}
+
+func (o *OpenCompose) merge(override *OpenCompose, requireComplete bool) error {
+	if o.Version != override.Version {
+		return fmt.Errorf("can't merge OpenCompose version %q into version %q", override.Version, o.Version)
+	}
+
+	if err := o.checkDuplicateNames(); err != nil {
+		return err
+	}
+	if err := override.checkDuplicateNames(); err != nil {
+		return err
+	}
+
+	for _, os := range override.Services {
+		found := false
+		for i := range o.Services {
+			if o.Services[i].Name == os.Name {
+				if err := o.Services[i].merge(&os, requireComplete); err != nil {
+					return fmt.Errorf("service %q: %v", os.Name, err)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			if requireComplete {
+				if err := os.checkComplete(); err != nil {
+					return err
+				}
+			}
+			o.Services = append(o.Services, os)
+		}
+	}
+
+	for _, ov := range override.Volumes {
+		found := false
+		for i := range o.Volumes {
+			if o.Volumes[i].Name == ov.Name {
+				o.Volumes[i].merge(&ov)
+				found = true
+				break
+			}
+		}
+		if !found {
+			if requireComplete {
+				if err := ov.checkComplete(); err != nil {
+					return err
+				}
+			}
+			o.Volumes = append(o.Volumes, ov)
+		}
+	}
+
+	for _, op := range override.Parameters {
+		found := false
+		for i := range o.Parameters {
+			if o.Parameters[i].Name == op.Name {
+				o.Parameters[i].merge(&op)
+				found = true
+				break
+			}
+		}
+		if !found {
+			o.Parameters = append(o.Parameters, op)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,305 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+)
+
+func TestOpenCompose_Merge(t *testing.T) {
+	base := func() *OpenCompose {
+		return &OpenCompose{
+			Version: "0.1-dev",
+			Services: []Service{
+				{
+					Name:     "web",
+					Replicas: goutil.Int32Addr(1),
+					Labels:   Labels{"tier": "frontend", "team": "a"},
+					Containers: []Container{
+						{
+							Name:  "nginx",
+							Image: "nginx:1.11",
+							Environment: []EnvVariable{
+								{Key: "MODE", Value: "dev"},
+								{Key: "DEBUG", Value: "1"},
+							},
+							Ports: []Port{
+								{Port: PortMapping{ContainerPort: 80, ServicePort: 80}, Type: PortType_Internal},
+							},
+							Mounts: []Mount{
+								{VolumeRef: "data", MountPath: "/data"},
+							},
+						},
+					},
+				},
+			},
+			Volumes: []Volume{
+				{Name: "data", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+			},
+			Parameters: []Parameter{
+				{Name: "TAG", Value: "latest"},
+			},
+		}
+	}
+
+	tests := []struct {
+		Name     string
+		Succeed  bool
+		Override *OpenCompose
+		Result   *OpenCompose
+	}{
+		{
+			"Empty override",
+			true,
+			&OpenCompose{Version: "0.1-dev"},
+			base(),
+		},
+		{
+			"Different versions",
+			false,
+			&OpenCompose{Version: "0.2"},
+			nil,
+		},
+		{
+			"Override scalars and merge by keys",
+			true,
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name:     "web",
+						Replicas: goutil.Int32Addr(3),
+						Labels:   Labels{"team": "b"},
+						Containers: []Container{
+							{
+								Name:        "nginx",
+								Image:       "nginx:1.12",
+								ParsedImage: &ImageRef{Repository: "nginx", Tag: "1.12"},
+								Args:        []string{"-c", "/etc/nginx/prod.conf"},
+								Environment: []EnvVariable{
+									{Key: "MODE", Value: "prod"},
+									{Key: "WORKERS", Value: "4"},
+								},
+								Ports: []Port{
+									{Port: PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: PortType_External, Host: goutil.StringAddr("example.com")},
+									{Port: PortMapping{ContainerPort: 443, ServicePort: 443}, Type: PortType_Internal},
+								},
+								Mounts: []Mount{
+									{VolumeRef: "data", MountPath: "/data", ReadOnly: true},
+								},
+							},
+						},
+					},
+				},
+				Volumes: []Volume{
+					{Name: "data", Size: "10Gi"},
+				},
+				Parameters: []Parameter{
+					{Name: "TAG", Value: "v1"},
+				},
+			},
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name:     "web",
+						Replicas: goutil.Int32Addr(3),
+						Labels:   Labels{"tier": "frontend", "team": "b"},
+						Containers: []Container{
+							{
+								Name:        "nginx",
+								Image:       "nginx:1.12",
+								ParsedImage: &ImageRef{Repository: "nginx", Tag: "1.12"},
+								Args:        []string{"-c", "/etc/nginx/prod.conf"},
+								Environment: []EnvVariable{
+									{Key: "MODE", Value: "prod"},
+									{Key: "DEBUG", Value: "1"},
+									{Key: "WORKERS", Value: "4"},
+								},
+								Ports: []Port{
+									{Port: PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: PortType_External, Host: goutil.StringAddr("example.com")},
+									{Port: PortMapping{ContainerPort: 443, ServicePort: 443}, Type: PortType_Internal},
+								},
+								Mounts: []Mount{
+									{VolumeRef: "data", MountPath: "/data", ReadOnly: true},
+								},
+							},
+						},
+					},
+				},
+				Volumes: []Volume{
+					{Name: "data", Size: "10Gi", AccessMode: "ReadWriteOnce"},
+				},
+				Parameters: []Parameter{
+					{Name: "TAG", Value: "v1"},
+				},
+			},
+		},
+		{
+			"Add service, container and volume",
+			true,
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name: "web",
+						Containers: []Container{
+							{Name: "sidecar", Image: "sidecar"},
+						},
+					},
+					{
+						Name: "db",
+						Containers: []Container{
+							{Name: "postgres", Image: "postgres"},
+						},
+					},
+				},
+				Volumes: []Volume{
+					{Name: "db", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name:     "web",
+						Replicas: goutil.Int32Addr(1),
+						Labels:   Labels{"tier": "frontend", "team": "a"},
+						Containers: []Container{
+							base().Services[0].Containers[0],
+							{Name: "sidecar", Image: "sidecar"},
+						},
+					},
+					{
+						Name: "db",
+						Containers: []Container{
+							{Name: "postgres", Image: "postgres"},
+						},
+					},
+				},
+				Volumes: []Volume{
+					{Name: "data", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+					{Name: "db", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+				},
+				Parameters: []Parameter{
+					{Name: "TAG", Value: "latest"},
+				},
+			},
+		},
+		{
+			"New container without image",
+			false,
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name: "web",
+						Containers: []Container{
+							{Name: "sidecar"},
+						},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"New service without containers",
+			false,
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{Name: "db", Replicas: goutil.Int32Addr(2)},
+				},
+			},
+			nil,
+		},
+		{
+			"New volume without size",
+			false,
+			&OpenCompose{
+				Version: "0.1-dev",
+				Volumes: []Volume{
+					{Name: "db", AccessMode: "ReadWriteOnce"},
+				},
+			},
+			nil,
+		},
+		{
+			"Port without container port",
+			false,
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name: "web",
+						Containers: []Container{
+							{
+								Name:  "nginx",
+								Ports: []Port{{Type: PortType_Internal}},
+							},
+						},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"Service defined twice",
+			false,
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{Name: "web", Replicas: goutil.Int32Addr(2)},
+					{Name: "web", Replicas: goutil.Int32Addr(3)},
+				},
+			},
+			nil,
+		},
+		{
+			"Environment variable defined twice",
+			false,
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name: "web",
+						Containers: []Container{
+							{
+								Name: "nginx",
+								Environment: []EnvVariable{
+									{Key: "MODE", Value: "prod"},
+									{Key: "MODE", Value: "test"},
+								},
+							},
+						},
+					},
+				},
+			},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			o := base()
+			err := o.Merge(test.Override)
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to merge %s\nErr: %s", spew.Sprint(test.Override), err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, override: %s\nMerged: %s", spew.Sprint(test.Override), spew.Sprint(o))
+				return
+			}
+
+			if !reflect.DeepEqual(o, test.Result) {
+				t.Errorf("Expected: %s\nGot: %s", spew.Sprint(test.Result), spew.Sprint(o))
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package object
 
 import (
 	"fmt"
-
-	"strings"
-
+	"net/url"
 	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
 	"k8s.io/client-go/pkg/api/resource"
 	"k8s.io/client-go/pkg/util/validation"
 )
@@ -30,6 +33,12 @@ type Port struct {
 	Path string
 }
 
+// Returns the name the transformers give to the container port, ports of a container
+// exposing the same container port share it
+func (p *Port) ContainerPortName() string {
+	return fmt.Sprintf("port-%d", p.Port.ContainerPort)
+}
+
 type EnvVariable struct {
 	Key   string
 	Value string
@@ -44,12 +53,31 @@ type Mount struct {
 
 type Labels map[string]string
 
+type GitSource struct {
+	URL        string
+	Ref        string
+	ContextDir string
+}
+
+type Build struct {
+	Git        GitSource
+	Dockerfile string
+	Args       []EnvVariable
+}
+
 type Container struct {
-	Name        string
-	Image       string
+	Name  string
+	Image string
+	// Image split into its parts, the decoder and merging set it together with Image.
+	// It's nil when the image is not valid or the container is not decoded, see ImageRef.
+	ParsedImage *ImageRef
+	// Command replaces the entrypoint of the image, Args its default arguments
+	Command     []string
+	Args        []string
 	Environment []EnvVariable
 	Ports       []Port
 	Mounts      []Mount
+	Build       *Build
 }
 
 type EmptyDirVolume struct {
@@ -62,6 +90,10 @@ type Service struct {
 	Replicas        *int32
 	EmptyDirVolumes []EmptyDirVolume
 	Labels          Labels
+	// Services are enabled unless set otherwise, disabled ones are dropped when profiles are resolved
+	Enabled *bool
+	// Name of the template the service is merged over, empty once the templates are resolved
+	Extends string
 }
 
 type Volume struct {
@@ -71,12 +103,45 @@ type Volume struct {
 	StorageClass *string
 }
 
-type OpenCompose struct {
-	Version  string
+// Parameter is a value that is supplied when the generated artifacts are instantiated,
+// e.g. when OpenShift Template is processed. It is referenced as ${NAME} from string
+// fields or as ${{NAME}} from fields of other types.
+type Parameter struct {
+	Name        string
+	DisplayName string
+	Description string
+	Value       string
+	Required    bool
+}
+
+// Include references another OpenCompose file that is merged before the including one
+type Include struct {
+	// Path relative to the including file or http(s) URL
+	Location string
+	IsURL    bool
+	// Expected sha256 checksum (hex) of the file, empty if not given
+	Sha256 string
+}
+
+// Profile patches services and volumes when it is selected,
+// they are merged the same way as multiple OpenCompose files
+type Profile struct {
 	Services []Service
 	Volumes  []Volume
 }
 
+type OpenCompose struct {
+	Version string
+	// Includes are resolved when the files are read, they are kept only so the file can be encoded back
+	Include    []Include
+	Services   []Service
+	Volumes    []Volume
+	Parameters []Parameter
+	Profiles   map[string]Profile
+	// Templates are partial services (without name) that services can extend
+	Templates map[string]Service
+}
+
 // Given the name of 'emptyDirVolume' this function searches
 // if service receiver has that 'emptyDirVolume'
 func (s *Service) EmptyDirVolumeExists(name string) bool {
@@ -99,160 +164,391 @@ func (o *OpenCompose) VolumeExists(name string) bool {
 	return false
 }
 
+// Rules a name has to follow to be used for a Kubernetes object or field the transformers name after it
+type nameRule struct {
+	// What is named, used in error messages
+	object   string
+	validate func(value string) []string
+}
+
 // Documentation about the valid identifiers can be found at
 // https://github.com/kubernetes/community/blob/master/contributors/design-proposals/identifiers.md
-func validateName(name string) error {
-	if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+var (
+	// Services, Deployments, DeploymentConfigs and Ingresses are named after services
+	// and the name is the value of their "service" label, Service names are the strictest
+	serviceNameRules   = []nameRule{{"Service", validation.IsDNS1035Label}}
+	containerNameRules = []nameRule{{"container", validation.IsDNS1123Label}}
+	// ports of containers are named after the container port (see Port.ContainerPortName)
+	containerPortNameRules = []nameRule{{"container port", validation.IsValidPortName}}
+	// volumes name both PersistentVolumeClaims and volumes of pods
+	volumeNameRules = []nameRule{
+		{"PersistentVolumeClaim", validation.IsDNS1123Subdomain},
+		{"pod volume", validation.IsDNS1123Label},
+	}
+	storageClassNameRules = []nameRule{{"StorageClass", validation.IsDNS1123Subdomain}}
+)
+
+// Checks name against the rules of all the objects named after it
+func validateName(name string, rules ...nameRule) error {
+	var errs []string
+	for _, rule := range rules {
+		for _, err := range rule.validate(name) {
+			errs = append(errs, fmt.Sprintf("%s name: %s", rule.object, err))
+		}
+		// the following rules would mostly repeat the same problems
+		if len(errs) > 0 {
+			break
+		}
+	}
+	if len(errs) != 0 {
 		return fmt.Errorf("%s", strings.Join(errs, "\n"))
 	}
 	return nil
 }
 
+// Names of environment variables have to be C identifiers so that shells and programs can use them
+var envVariableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Portable names consist of uppercase letters, digits and '_' only, as used by POSIX utilities
+var portableEnvVariableNameRegexp = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
 func (e *EnvVariable) validate() error {
-	// TODO: add more validation tests besides checking for '='
-	if strings.Contains(e.Key, "=") {
-		return fmt.Errorf("Illegal character '=' in environment variable key: %v", e.Key)
+	// any value is valid, including '=' and newlines
+	if !envVariableNameRegexp.MatchString(e.Key) {
+		return fieldErrorf(ErrorCode_InvalidEnvVariable, []string{"name"}, "invalid name %q, must match regexp %q", e.Key, envVariableNameRegexp.String())
 	}
 
-	if strings.Contains(e.Value, "=") {
-		return fmt.Errorf("Illegal character '=' in environment variable value: %v", e.Value)
+	return nil
+}
+
+func (e *EnvVariable) warnings() error {
+	if envVariableNameRegexp.MatchString(e.Key) && !portableEnvVariableNameRegexp.MatchString(e.Key) {
+		return fieldErrorf(ErrorCode_NonPortableEnvVariable, []string{"name"}, "name %q is not portable, use only uppercase letters, digits and '_'", e.Key)
 	}
+
 	return nil
 }
 
+// Validates environment variables of a container or build arguments, segment is the field holding them
+func validateEnvironment(environment []EnvVariable, prefix string, segment string) error {
+	var errs pkgutil.ErrorList
+
+	defined := make(map[string]bool)
+	for i, env := range environment {
+		if err := env.validate(); err != nil {
+			errs = errs.Append(wrapFieldError(err, prefix, segment, env.Key))
+		}
+
+		if defined[env.Key] {
+			errs = errs.Append(fieldErrorf(ErrorCode_DuplicateEnvVariable, []string{segment, strconv.Itoa(i), "name"}, "%s %q: defined more than once", prefix, env.Key))
+		}
+		defined[env.Key] = true
+	}
+
+	return errs.Err()
+}
+
+func environmentWarnings(environment []EnvVariable, prefix string, segment string) error {
+	var warnings pkgutil.ErrorList
+	for _, env := range environment {
+		warnings = warnings.Append(wrapFieldError(env.warnings(), prefix, segment, env.Key))
+	}
+	return warnings.Err()
+}
+
 func (m *Mount) validate() error {
+	var errs pkgutil.ErrorList
 
 	// validate volumeRef
-	if err := validateName(m.VolumeRef); err != nil {
-		return fmt.Errorf("mount %q: invalid name, %v", m.VolumeRef, err)
+	if err := validateName(m.VolumeRef, volumeNameRules...); err != nil {
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidName, []string{"volumeRef"}, "mount %q: invalid name, %v", m.VolumeRef, err))
 	}
 
 	// mountPath should be absolute
 	if !path.IsAbs(m.MountPath) {
-		return fmt.Errorf("mount %q: mountPath %q: is not absolute path", m.VolumeRef, m.MountPath)
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidMountPath, []string{"mountPath"}, "mount %q: mountPath %q: is not absolute path", m.VolumeRef, m.MountPath))
 	}
 
 	// validate volumeSubPath
 	// TODO: if there is someway to do it
 
-	return nil
+	return errs.Err()
+}
+
+// scp-like syntax for git urls, e.g. git@github.com:user/repo.git
+var scpLikeGitURLRegexp = regexp.MustCompile(`^([A-Za-z0-9_.-]+@)?[A-Za-z0-9_.-]+:[^/].*$`)
+
+func (g *GitSource) validate() error {
+	var errs pkgutil.ErrorList
+
+	if strings.Contains(g.URL, "://") {
+		u, err := url.Parse(g.URL)
+		if err != nil {
+			errs = errs.Append(fieldErrorf(ErrorCode_InvalidGitSource, []string{"url"}, "git url %q: %v", g.URL, err))
+		} else {
+			switch u.Scheme {
+			case "http", "https", "git", "ssh", "file":
+			default:
+				errs = errs.Append(fieldErrorf(ErrorCode_InvalidGitSource, []string{"url"}, "git url %q: unsupported scheme %q", g.URL, u.Scheme))
+			}
+		}
+	} else if !scpLikeGitURLRegexp.MatchString(g.URL) {
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidGitSource, []string{"url"}, "git url %q: is not a valid url", g.URL))
+	}
+
+	if path.IsAbs(g.ContextDir) {
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidGitSource, []string{"contextDir"}, "contextDir %q: must be relative to the repository root", g.ContextDir))
+	}
+
+	return errs.Err()
+}
+
+func (b *Build) validate() error {
+	var errs pkgutil.ErrorList
+
+	if err := b.Git.validate(); err != nil {
+		errs = errs.Append(wrapFieldError(err, "", "git"))
+	}
+
+	if path.IsAbs(b.Dockerfile) {
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidDockerfile, []string{"dockerfile"}, "dockerfile %q: must be relative to the context directory", b.Dockerfile))
+	}
+
+	if err := validateEnvironment(b.Args, "build arg", "args"); err != nil {
+		errs = errs.Append(err)
+	}
+
+	return errs.Err()
+}
+
+// Returns the parsed image of the container, the image is parsed only if it isn't already
+func (c *Container) ImageRef() (*ImageRef, error) {
+	if c.ParsedImage != nil {
+		return c.ParsedImage, nil
+	}
+	return ParseImageRef(c.Image)
 }
 
 func (c *Container) validate() error {
+	var errs pkgutil.ErrorList
+
+	// validate container name
+	if err := validateName(c.Name, containerNameRules...); err != nil {
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidName, []string{"name"}, "invalid name, %v", err))
+	}
+
+	// validate the names the ports get in the container
+	for i := range c.Ports {
+		if err := validateName(c.Ports[i].ContainerPortName(), containerPortNameRules...); err != nil {
+			errs = errs.Append(fieldErrorf(ErrorCode_InvalidName, []string{"ports", strconv.Itoa(i)}, "port %q: invalid name, %v", c.Ports[i].ContainerPortName(), err))
+		}
+	}
 
 	// validate image name
-	// TODO: implement me
-	// validate Ports
-	// TODO: implement me
+	image, err := c.ImageRef()
+	if err != nil {
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidImage, []string{"image"}, "%v", err))
+	}
 
-	for _, env := range c.Environment {
-		if err := env.validate(); err != nil {
-			return fmt.Errorf("failed to validate environment variable: %v", err)
+	// validate ports, the service ports are checked by the service as they are shared by its containers
+	for i, p := range c.Ports {
+		for _, port := range []int{p.Port.ContainerPort, p.Port.ServicePort} {
+			if errStrings := validation.IsValidPortNum(port); len(errStrings) != 0 {
+				errs = errs.Append(fieldErrorf(ErrorCode_InvalidPort, []string{"ports", strconv.Itoa(i), "port"}, "port %d: %s", port, strings.Join(errStrings, ", ")))
+			}
+		}
+	}
+
+	if err := validateEnvironment(c.Environment, "environment variable", "env"); err != nil {
+		errs = errs.Append(err)
+	}
+
+	// validate build
+	if c.Build != nil {
+		if err := c.Build.validate(); err != nil {
+			errs = errs.Append(wrapFieldError(err, "build", "build"))
+		}
+
+		// built image is pushed to an ImageStreamTag, digest is known only after the build
+		if image != nil && image.Digest != "" {
+			errs = errs.Append(fieldErrorf(ErrorCode_BuiltImageDigest, []string{"image"}, "build: image %q: can't be referenced by digest when it is built", c.Image))
 		}
 	}
 
 	// validate Mounts
 	allMounts := make(map[string]string)
-	for _, mount := range c.Mounts {
+	for i, mount := range c.Mounts {
 		if err := mount.validate(); err != nil {
-			return fmt.Errorf("failed to validate mount: %v", err)
+			errs = errs.Append(wrapFieldError(err, "failed to validate mount", "mounts", strconv.Itoa(i)))
 		}
 
 		// mountPath should not collide, which means you should not do multiple mounts in same place
 		if v, ok := allMounts[mount.MountPath]; ok {
-			return fmt.Errorf("mount %q: mountPath %q: cannot have same mountPath as %q", mount.VolumeRef, mount.MountPath, v)
+			errs = errs.Append(fieldErrorf(ErrorCode_DuplicateMountPath, []string{"mounts", strconv.Itoa(i), "mountPath"}, "mount %q: mountPath %q: cannot have same mountPath as %q", mount.VolumeRef, mount.MountPath, v))
+			continue
 		}
 		allMounts[mount.MountPath] = mount.VolumeRef
 	}
-	return nil
+
+	return errs.Err()
+}
+
+func (c *Container) warnings() error {
+	var warnings pkgutil.ErrorList
+
+	warnings = warnings.Append(environmentWarnings(c.Environment, "environment variable", "env"))
+	if c.Build != nil {
+		warnings = warnings.Append(wrapFieldError(environmentWarnings(c.Build.Args, "build arg", "args"), "build", "build"))
+	}
+
+	return warnings.Err()
+}
+
+func (s *Service) warnings() error {
+	var warnings pkgutil.ErrorList
+	for cno, cnt := range s.Containers {
+		warnings = warnings.Append(wrapFieldError(cnt.warnings(), fmt.Sprintf("container#%d", cno+1), "containers", cnt.Name))
+	}
+	return warnings.Err()
 }
 
 func (s *Service) validate() error {
-	// validate service name, like it cannot have underscores, etc.
-	if err := validateName(s.Name); err != nil {
-		return fmt.Errorf("invalid name, %v", err)
+	var errs pkgutil.ErrorList
+
+	// validate service name, like it cannot have underscores, dots, etc.
+	if err := validateName(s.Name, serviceNameRules...); err != nil {
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidName, []string{"name"}, "invalid name, %v", err))
 	}
 
 	// validate containers
 	for cno, cnt := range s.Containers {
 		if err := cnt.validate(); err != nil {
-			return fmt.Errorf("container#%d: %v", cno+1, err)
+			errs = errs.Append(wrapFieldError(err, fmt.Sprintf("container#%d", cno+1), "containers", cnt.Name))
+		}
+	}
+
+	// ports of the same type of all the containers become ports of one Kubernetes Service
+	servicePorts := make(map[PortType]map[int]string)
+	for _, c := range s.Containers {
+		for i, p := range c.Ports {
+			if servicePorts[p.Type] == nil {
+				servicePorts[p.Type] = make(map[int]string)
+			}
+			if owner, ok := servicePorts[p.Type][p.Port.ServicePort]; ok {
+				errs = errs.Append(fieldErrorf(ErrorCode_DuplicateServicePort, []string{"containers", c.Name, "ports", strconv.Itoa(i), "port"}, "container %q: service port %d: is already used by container %q", c.Name, p.Port.ServicePort, owner))
+				continue
+			}
+			servicePorts[p.Type][p.Port.ServicePort] = c.Name
 		}
 	}
 
 	// validate replicas
 	if s.Replicas != nil && *s.Replicas < 0 {
-		return fmt.Errorf("%s", "'replicas' can't be negative")
+		errs = errs.Append(fieldErrorf(ErrorCode_NegativeReplicas, []string{"replicas"}, "%s", "'replicas' can't be negative"))
 	}
 
 	// validate emptyDirVolume
 	for _, e := range s.EmptyDirVolumes {
-		if err := validateName(e.Name); err != nil {
-			return fmt.Errorf("emptyDirVolume %q: invalid name, %v", e.Name, err)
+		if err := validateName(e.Name, volumeNameRules...); err != nil {
+			errs = errs.Append(fieldErrorf(ErrorCode_InvalidName, []string{"emptyDirVolumes", e.Name}, "emptyDirVolume %q: invalid name, %v", e.Name, err))
 		}
 	}
 
-	// validate label values
-	for _, v := range s.Labels {
-		errString := validation.IsValidLabelValue(v)
+	// validate label values, in a stable order
+	var keys []string
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		errString := validation.IsValidLabelValue(s.Labels[k])
 		if errString != nil {
-			return fmt.Errorf("Invalid label value: %v", errString)
+			errs = errs.Append(fieldErrorf(ErrorCode_InvalidLabelValue, []string{"labels", k}, "Invalid label value: %v", errString))
 		}
 	}
 
-	return nil
+	return errs.Err()
 }
 
+// Access modes volumes can have
+var VolumeAccessModes = []string{"ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany"}
+
 func validateVolumeMode(volumeMode string) error {
-	switch volumeMode {
-	case "ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany":
-	default:
-		return fmt.Errorf("invalid accessMode: %q, must be either %q, %q or %q", volumeMode, "ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany")
+	for _, mode := range VolumeAccessModes {
+		if volumeMode == mode {
+			return nil
+		}
 	}
-	return nil
+	return fmt.Errorf("invalid accessMode: %q, must be either %q, %q or %q", volumeMode, VolumeAccessModes[0], VolumeAccessModes[1], VolumeAccessModes[2])
 }
 
 func (v *Volume) validate() error {
+	var errs pkgutil.ErrorList
+
 	// validate volume name
-	if err := validateName(v.Name); err != nil {
-		return fmt.Errorf("invalid name, %v", err)
+	if err := validateName(v.Name, volumeNameRules...); err != nil {
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidName, []string{"name"}, "invalid name, %v", err))
 	}
 
 	// validate volume size
 	if _, err := resource.ParseQuantity(v.Size); err != nil {
-		return fmt.Errorf("size %q: %v", v.Size, err)
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidSize, []string{"size"}, "size %q: %v", v.Size, err))
 	}
 
 	// validate volume access mode
 	if err := validateVolumeMode(v.AccessMode); err != nil {
-		return err
+		errs = errs.Append(fieldErrorf(ErrorCode_InvalidAccessMode, []string{"accessMode"}, "%v", err))
 	}
 
 	if v.StorageClass != nil {
-		if err := validateName(*v.StorageClass); err != nil {
-			return fmt.Errorf("storageClass %q: invalid name, %v", *v.StorageClass, err)
+		if err := validateName(*v.StorageClass, storageClassNameRules...); err != nil {
+			errs = errs.Append(fieldErrorf(ErrorCode_InvalidName, []string{"storageClass"}, "storageClass %q: invalid name, %v", *v.StorageClass, err))
 		}
 	}
 
+	return errs.Err()
+}
+
+var parameterNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (p *Parameter) validate() error {
+	if !parameterNameRegexp.MatchString(p.Name) {
+		return fieldErrorf(ErrorCode_InvalidParameterName, []string{"name"}, "invalid name, must match regexp %q", parameterNameRegexp.String())
+	}
+
 	return nil
 }
 
+// Returns problems that don't make OpenCompose invalid but make it less portable,
+// e.g. environment variables that not every shell can use. Each one is FieldError,
+// more of them are returned in pkgutil.ErrorList.
+func (o *OpenCompose) Warnings() error {
+	var warnings pkgutil.ErrorList
+	for _, service := range o.Services {
+		warnings = warnings.Append(wrapFieldError(service.warnings(), fmt.Sprintf("service %q", service.Name), "services", service.Name))
+	}
+	return warnings.Err()
+}
+
 // Does high level (mostly semantic) validation of OpenCompose
-// (e.g. it checks internal object references)
+// (e.g. it checks internal object references).
+// All the problems are reported, more of them in pkgutil.ErrorList, each as FieldError.
 func (o *OpenCompose) Validate() error {
+	var errs pkgutil.ErrorList
+
 	// validating services
 	for _, service := range o.Services {
 		if err := service.validate(); err != nil {
-			return fmt.Errorf("service %q: %v", service.Name, err)
+			errs = errs.Append(wrapFieldError(err, fmt.Sprintf("service %q", service.Name), "services", service.Name))
 		}
 
 		// validate if the mounts are specified in root level volumes
 		// or emptydirvolumes, error out if not found anywhere
 		for cno, container := range service.Containers {
-			for _, mount := range container.Mounts {
+			for i, mount := range container.Mounts {
 				if !o.VolumeExists(mount.VolumeRef) && !service.EmptyDirVolumeExists(mount.VolumeRef) {
-					return fmt.Errorf("volume mount %q in service %q in container#%d does not correspond to either 'root level volume' or 'emptydir volume'",
-						mount.VolumeRef, service.Name, cno+1)
+					errs = errs.Append(fieldErrorf(ErrorCode_UndefinedVolume, []string{"services", service.Name, "containers", container.Name, "mounts", strconv.Itoa(i), "volumeRef"}, "volume mount %q in service %q in container#%d does not correspond to either 'root level volume' or 'emptydir volume'",
+						mount.VolumeRef, service.Name, cno+1))
 				}
 			}
 		}
@@ -261,9 +557,22 @@ func (o *OpenCompose) Validate() error {
 	// validate volumes
 	for _, volume := range o.Volumes {
 		if err := volume.validate(); err != nil {
-			return fmt.Errorf("volume %q: %v", volume.Name, err)
+			errs = errs.Append(wrapFieldError(err, fmt.Sprintf("volume %q", volume.Name), "volumes", volume.Name))
 		}
 	}
 
-	return nil
+	// validate parameters
+	allParameters := make(map[string]bool)
+	for _, parameter := range o.Parameters {
+		if err := parameter.validate(); err != nil {
+			errs = errs.Append(wrapFieldError(err, fmt.Sprintf("parameter %q", parameter.Name), "parameters", parameter.Name))
+		}
+
+		if allParameters[parameter.Name] {
+			errs = errs.Append(fieldErrorf(ErrorCode_DuplicateParameter, []string{"parameters", parameter.Name}, "parameter %q: defined more than once", parameter.Name))
+		}
+		allParameters[parameter.Name] = true
+	}
+
+	return errs.Err()
 }
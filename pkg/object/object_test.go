@@ -2,9 +2,12 @@ package object
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/util"
 )
 
 const (
@@ -95,10 +98,41 @@ func TestContainer_Validate(t *testing.T) {
 		ExpectedSuccess bool
 		Container       *Container
 	}{
+		{
+			"container name with dot",
+			false,
+			&Container{
+				Name:  "web.1",
+				Image: "nginx",
+			},
+		},
+		{
+			"long container name",
+			true,
+			&Container{
+				Name:  "a-long-container-name",
+				Image: "nginx",
+			},
+		},
+		{
+			"long container name with ports",
+			true,
+			&Container{
+				Name:  "a-long-container-name",
+				Image: "nginx",
+				Ports: []Port{
+					{Port: PortMapping{ContainerPort: 80, ServicePort: 80}},
+					{Port: PortMapping{ContainerPort: 443, ServicePort: 443}},
+					{Port: PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: PortType_External},
+				},
+			},
+		},
 		{
 			"invalid mount name",
 			false,
 			&Container{
+				Name:  "web",
+				Image: "nginx",
 				Mounts: []Mount{
 					{
 						VolumeRef: "invalid_mount_name",
@@ -110,6 +144,8 @@ func TestContainer_Validate(t *testing.T) {
 			"mountPath not absolute",
 			false,
 			&Container{
+				Name:  "web",
+				Image: "nginx",
 				Mounts: []Mount{
 					{
 						VolumeRef: "test",
@@ -122,6 +158,8 @@ func TestContainer_Validate(t *testing.T) {
 			"same mountPath in multiple mounts",
 			false,
 			&Container{
+				Name:  "web",
+				Image: "nginx",
 				Mounts: []Mount{
 					{
 						VolumeRef: "test1",
@@ -138,6 +176,8 @@ func TestContainer_Validate(t *testing.T) {
 			"passing '=' in environment variable key",
 			false,
 			&Container{
+				Name:  "web",
+				Image: "nginx",
 				Environment: []EnvVariable{
 					{
 						Key:   "ke=y",
@@ -148,8 +188,10 @@ func TestContainer_Validate(t *testing.T) {
 		},
 		{
 			"passing '=' in environment variable value",
-			false,
+			true,
 			&Container{
+				Name:  "web",
+				Image: "nginx",
 				Environment: []EnvVariable{
 					{
 						Key:   "key",
@@ -158,10 +200,117 @@ func TestContainer_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			"passing environment variable key starting with digit",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "nginx",
+				Environment: []EnvVariable{
+					{
+						Key:   "1KEY",
+						Value: "value",
+					},
+				},
+			},
+		},
+		{
+			"passing '-' in environment variable key",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "nginx",
+				Environment: []EnvVariable{
+					{
+						Key:   "MY-KEY",
+						Value: "value",
+					},
+				},
+			},
+		},
+		{
+			"passing empty environment variable key",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "nginx",
+				Environment: []EnvVariable{
+					{
+						Key:   "",
+						Value: "value",
+					},
+				},
+			},
+		},
+		{
+			"passing multiline environment variable value",
+			true,
+			&Container{
+				Name:  "web",
+				Image: "nginx",
+				Environment: []EnvVariable{
+					{
+						Key:   "CERT",
+						Value: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+					},
+				},
+			},
+		},
+		{
+			"passing JAVA_OPTS with '=' in value",
+			true,
+			&Container{
+				Name:  "web",
+				Image: "nginx",
+				Environment: []EnvVariable{
+					{
+						Key:   "JAVA_OPTS",
+						Value: "-Dfoo=bar -Xmx=512m",
+					},
+				},
+			},
+		},
+		{
+			"passing duplicate environment variable keys",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "nginx",
+				Environment: []EnvVariable{
+					{
+						Key:   "KEY",
+						Value: "a",
+					},
+					{
+						Key:   "KEY",
+						Value: "b",
+					},
+				},
+			},
+		},
+		{
+			"build with duplicate args",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "app:v1",
+				Build: &Build{
+					Git: GitSource{
+						URL: "https://github.com/foo/bar.git",
+					},
+					Args: []EnvVariable{
+						{Key: "VERSION", Value: "1"},
+						{Key: "VERSION", Value: "2"},
+					},
+				},
+			},
+		},
 		{
 			"passing a valid environment variable",
 			true,
 			&Container{
+				Name:  "web",
+				Image: "nginx",
 				Environment: []EnvVariable{
 					{
 						Key:   "key",
@@ -170,6 +319,147 @@ func TestContainer_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			"build from https git url",
+			true,
+			&Container{
+				Name:  "web",
+				Image: "app:v1",
+				Build: &Build{
+					Git: GitSource{
+						URL:        "https://github.com/foo/bar.git",
+						ContextDir: "app",
+					},
+					Dockerfile: "Dockerfile.prod",
+				},
+			},
+		},
+		{
+			"build from scp-like git url",
+			true,
+			&Container{
+				Name:  "web",
+				Image: "app:v1",
+				Build: &Build{
+					Git: GitSource{
+						URL: "git@github.com:foo/bar.git",
+					},
+				},
+			},
+		},
+		{
+			"build from invalid git url",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "app:v1",
+				Build: &Build{
+					Git: GitSource{
+						URL: "github.com/foo/bar.git",
+					},
+				},
+			},
+		},
+		{
+			"build from git url with unsupported scheme",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "app:v1",
+				Build: &Build{
+					Git: GitSource{
+						URL: "ftp://github.com/foo/bar.git",
+					},
+				},
+			},
+		},
+		{
+			"build with absolute contextDir",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "app:v1",
+				Build: &Build{
+					Git: GitSource{
+						URL:        "https://github.com/foo/bar.git",
+						ContextDir: "/app",
+					},
+				},
+			},
+		},
+		{
+			"build with absolute dockerfile",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "app:v1",
+				Build: &Build{
+					Git: GitSource{
+						URL: "https://github.com/foo/bar.git",
+					},
+					Dockerfile: "/Dockerfile",
+				},
+			},
+		},
+		{
+			"container port out of range",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "nginx",
+				Ports: []Port{
+					{Port: PortMapping{ContainerPort: 0, ServicePort: 80}},
+				},
+			},
+		},
+		{
+			"service port out of range",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "nginx",
+				Ports: []Port{
+					{Port: PortMapping{ContainerPort: 80, ServicePort: 65536}},
+				},
+			},
+		},
+		{
+			"image with uppercase repository",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "Nginx:1.11",
+			},
+		},
+		{
+			"image with invalid tag",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "nginx:1.11:2",
+			},
+		},
+		{
+			"image from registry with port",
+			true,
+			&Container{
+				Name:  "web",
+				Image: "registry.example.com:5000/team/app:v1",
+			},
+		},
+		{
+			"build of image referenced by digest",
+			false,
+			&Container{
+				Name:  "web",
+				Image: "app@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Build: &Build{
+					Git: GitSource{
+						URL: "https://github.com/foo/bar.git",
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -202,6 +492,27 @@ func TestService_Validate(t *testing.T) {
 				Name: "foo_bar",
 			},
 		},
+		{
+			"service name with dot",
+			false,
+			&Service{
+				Name: "my.svc",
+			},
+		},
+		{
+			"service name starting with digit",
+			false,
+			&Service{
+				Name: "1api",
+			},
+		},
+		{
+			"service name longer than 63 characters",
+			false,
+			&Service{
+				Name: strings.Repeat("a", 64),
+			},
+		},
 		{
 			"negative replica count",
 			false,
@@ -210,6 +521,30 @@ func TestService_Validate(t *testing.T) {
 				Replicas: goutil.Int32Addr(-2),
 			},
 		},
+		{
+			"same service port in two containers",
+			false,
+			&Service{
+				Name: "test",
+				Containers: []Container{
+					{Name: "web", Image: "nginx", Ports: []Port{{Port: PortMapping{ContainerPort: 80, ServicePort: 80}}}},
+					{Name: "admin", Image: "nginx", Ports: []Port{{Port: PortMapping{ContainerPort: 8080, ServicePort: 80}}}},
+				},
+			},
+		},
+		{
+			"same service port internal and external",
+			true,
+			&Service{
+				Name: "test",
+				Containers: []Container{
+					{Name: "web", Image: "nginx", Ports: []Port{
+						{Port: PortMapping{ContainerPort: 80, ServicePort: 80}},
+						{Port: PortMapping{ContainerPort: 80, ServicePort: 80}, Type: PortType_External},
+					}},
+				},
+			},
+		},
 		{
 			"invalid emptyDirVolume name",
 			false,
@@ -289,6 +624,16 @@ func TestVolume_Validate(t *testing.T) {
 			},
 		},
 
+		{
+			"Volume name with dot given, should fail",
+			false,
+			&Volume{
+				Name:       "test.vol",
+				Size:       "5Gi",
+				AccessMode: "ReadWriteMany",
+			},
+		},
+
 		{
 			"Invalid volume size, should fail",
 			false,
@@ -395,6 +740,31 @@ func TestMount_Validate(t *testing.T) {
 
 }
 
+func TestParameter_Validate(t *testing.T) {
+	tests := []struct {
+		Name            string
+		ExpectedSuccess bool
+		Parameter       *Parameter
+	}{
+		{"valid name", true, &Parameter{Name: "APP_DOMAIN"}},
+		{"valid name starting with underscore", true, &Parameter{Name: "_foo1"}},
+		{"empty name", false, &Parameter{Name: ""}},
+		{"name starting with digit", false, &Parameter{Name: "1FOO"}},
+		{"name with dash", false, &Parameter{Name: "APP-DOMAIN"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := test.Parameter.validate()
+			if test.ExpectedSuccess && err != nil {
+				t.Fatalf("Expected success but failed as: %v", err)
+			} else if !test.ExpectedSuccess && err == nil {
+				t.Fatal("Expected failure but passed.")
+			}
+		})
+	}
+}
+
 func TestOpenCompose_Validate(t *testing.T) {
 	name := "test-service"
 	image := "test-image"
@@ -414,6 +784,7 @@ func TestOpenCompose_Validate(t *testing.T) {
 						Name: name,
 						Containers: []Container{
 							{
+								Name:  "test",
 								Image: image,
 							},
 						},
@@ -433,6 +804,7 @@ func TestOpenCompose_Validate(t *testing.T) {
 						Name: name,
 						Containers: []Container{
 							{
+								Name:  "test",
 								Image: image,
 							},
 						},
@@ -452,6 +824,7 @@ func TestOpenCompose_Validate(t *testing.T) {
 						Name: name,
 						Containers: []Container{
 							{
+								Name:  "test",
 								Image: image,
 							},
 						},
@@ -471,6 +844,7 @@ func TestOpenCompose_Validate(t *testing.T) {
 						Name: name,
 						Containers: []Container{
 							{
+								Name:  "test",
 								Image: image,
 							},
 						},
@@ -490,6 +864,7 @@ func TestOpenCompose_Validate(t *testing.T) {
 						Name: name,
 						Containers: []Container{
 							{
+								Name:  "test",
 								Image: image,
 							},
 						},
@@ -512,6 +887,7 @@ func TestOpenCompose_Validate(t *testing.T) {
 						Name: name,
 						Containers: []Container{
 							{
+								Name:  "test",
 								Image: image,
 							},
 						},
@@ -537,3 +913,169 @@ func TestOpenCompose_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenCompose_Validate_FieldErrorPath(t *testing.T) {
+	tests := []struct {
+		Name        string
+		openCompose *OpenCompose
+		Path        string
+	}{
+		{
+			"Negative replicas",
+			&OpenCompose{
+				Services: []Service{
+					{Name: "web", Replicas: goutil.Int32Addr(-1), Containers: []Container{{Name: "nginx", Image: "nginx"}}},
+				},
+			},
+			"/services/web/replicas",
+		},
+		{
+			"Invalid environment variable",
+			&OpenCompose{
+				Services: []Service{
+					{Name: "web", Containers: []Container{{Name: "nginx", Image: "nginx", Environment: []EnvVariable{{Key: "A=B", Value: "c"}}}}},
+				},
+			},
+			"/services/web/containers/nginx/env/A=B/name",
+		},
+		{
+			"Duplicate environment variable",
+			&OpenCompose{
+				Services: []Service{
+					{Name: "web", Containers: []Container{{Name: "nginx", Image: "nginx", Environment: []EnvVariable{{Key: "A", Value: "a"}, {Key: "A", Value: "b"}}}}},
+				},
+			},
+			"/services/web/containers/nginx/env/1/name",
+		},
+		{
+			"Mount of undefined volume",
+			&OpenCompose{
+				Services: []Service{
+					{Name: "web", Containers: []Container{{Name: "nginx", Image: "nginx", Mounts: []Mount{{VolumeRef: "data", MountPath: "/data"}}}}},
+				},
+			},
+			"/services/web/containers/nginx/mounts/0/volumeRef",
+		},
+		{
+			"Invalid volume name",
+			&OpenCompose{
+				Volumes: []Volume{
+					{Name: "data/x", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+			"/volumes/data~1x/name",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := test.openCompose.Validate()
+			fe, ok := err.(*FieldError)
+			if !ok {
+				t.Fatalf("Expected FieldError, got: %#v", err)
+			}
+
+			if fe.Path != test.Path {
+				t.Fatalf("Expected path %q, got %q (%s)", test.Path, fe.Path, err)
+			}
+		})
+	}
+}
+
+func TestOpenCompose_Validate_AllErrors(t *testing.T) {
+	openCompose := &OpenCompose{
+		Services: []Service{
+			{
+				Name:       "web_1",
+				Replicas:   goutil.Int32Addr(-1),
+				Containers: []Container{{Name: "nginx", Image: "Nginx", Mounts: []Mount{{VolumeRef: "data", MountPath: "data"}}}},
+			},
+		},
+		Volumes: []Volume{
+			{Name: "data", Size: "1x", AccessMode: "ReadWriteOnce"},
+		},
+	}
+
+	expected := []string{
+		"/services/web_1/name",
+		"/services/web_1/containers/nginx/image",
+		"/services/web_1/containers/nginx/mounts/0/mountPath",
+		"/services/web_1/replicas",
+		"/volumes/data/size",
+	}
+	expectedCodes := []util.ErrorCode{
+		ErrorCode_InvalidName,
+		ErrorCode_InvalidImage,
+		ErrorCode_InvalidMountPath,
+		ErrorCode_NegativeReplicas,
+		ErrorCode_InvalidSize,
+	}
+
+	var paths []string
+	var codes []util.ErrorCode
+	for _, err := range util.Errors(openCompose.Validate()) {
+		fe, ok := err.(*FieldError)
+		if !ok {
+			t.Fatalf("Expected FieldError, got: %#v", err)
+		}
+		paths = append(paths, fe.Path)
+		codes = append(codes, util.CodeOf(fe))
+	}
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("Expected errors at %q, got %q", expected, paths)
+	}
+	if !reflect.DeepEqual(codes, expectedCodes) {
+		t.Fatalf("Expected codes %q, got %q", expectedCodes, codes)
+	}
+}
+
+func TestOpenCompose_Warnings(t *testing.T) {
+	openCompose := &OpenCompose{
+		Services: []Service{
+			{
+				Name: "web",
+				Containers: []Container{
+					{
+						Name:  "nginx",
+						Image: "nginx",
+						Environment: []EnvVariable{
+							{Key: "http_proxy", Value: "http://proxy:3128"},
+							{Key: "LOG_LEVEL", Value: "info"},
+						},
+						Build: &Build{
+							Git:  GitSource{URL: "https://github.com/foo/bar.git"},
+							Args: []EnvVariable{{Key: "Version", Value: "1"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if err := openCompose.Validate(); err != nil {
+		t.Fatalf("Non-portable names should be valid, got: %s", err)
+	}
+
+	expected := []string{
+		"/services/web/containers/nginx/env/http_proxy/name",
+		"/services/web/containers/nginx/build/args/Version/name",
+	}
+
+	warnings := util.Errors(openCompose.Warnings())
+	var paths []string
+	for _, w := range warnings {
+		fe, ok := w.(*FieldError)
+		if !ok {
+			t.Fatalf("Expected FieldError, got: %#v", w)
+		}
+		if fe.Code() != ErrorCode_NonPortableEnvVariable {
+			t.Errorf("Expected code %q, got %q", ErrorCode_NonPortableEnvVariable, fe.Code())
+		}
+		paths = append(paths, fe.Path)
+	}
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("Expected warnings for\n%#v\ngot\n%#v", expected, paths)
+	}
+}
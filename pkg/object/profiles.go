@@ -0,0 +1,52 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+)
+
+func (s *Service) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// Returns sorted names of the defined profiles
+func (o *OpenCompose) ProfileNames() []string {
+	var names []string
+	for name := range o.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Applies the profiles in the given order and drops disabled services.
+// Profiles are removed afterwards as they are not needed anymore.
+// The result isn't validated, call Validate once the profiles are resolved.
+func (o *OpenCompose) ResolveProfiles(names []string) error {
+	for _, name := range names {
+		p, ok := o.Profiles[name]
+		if !ok {
+			return fmt.Errorf("profile %q is not defined, available profiles: %v", name, o.ProfileNames())
+		}
+
+		if err := o.merge(&OpenCompose{Version: o.Version, Services: p.Services, Volumes: p.Volumes}, true); err != nil {
+			return fmt.Errorf("profile %q: %v", name, err)
+		}
+	}
+
+	var services []Service
+	for _, s := range o.Services {
+		// templates are resolved before profiles
+		if s.Extends != "" {
+			return fmt.Errorf("service %q: profiles can't set 'extends'", s.Name)
+		}
+
+		if s.IsEnabled() {
+			services = append(services, s)
+		}
+	}
+	o.Services = services
+	o.Profiles = nil
+
+	return nil
+}
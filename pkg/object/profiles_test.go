@@ -0,0 +1,163 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+)
+
+func TestOpenCompose_ResolveProfiles(t *testing.T) {
+	base := &OpenCompose{
+		Version: "0.1-dev",
+		Services: []Service{
+			{
+				Name: "web",
+				Containers: []Container{
+					{Name: "nginx", Image: "nginx:1.11"},
+				},
+			},
+			{
+				Name:    "debug",
+				Enabled: goutil.BoolAddr(false),
+				Containers: []Container{
+					{Name: "busybox", Image: "busybox"},
+				},
+			},
+		},
+		Volumes: []Volume{
+			{Name: "data", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+		},
+		Profiles: map[string]Profile{
+			"prod": {
+				Services: []Service{
+					{
+						Name:     "web",
+						Replicas: goutil.Int32Addr(3),
+						Containers: []Container{
+							{Name: "nginx", Image: "nginx:1.12"},
+						},
+					},
+				},
+				Volumes: []Volume{
+					{Name: "data", Size: "10Gi"},
+				},
+			},
+			"dev": {
+				Services: []Service{
+					{Name: "debug", Enabled: goutil.BoolAddr(true)},
+				},
+			},
+			"off": {
+				Services: []Service{
+					{Name: "web", Enabled: goutil.BoolAddr(false)},
+				},
+			},
+			"incomplete": {
+				Services: []Service{
+					{Name: "cache", Replicas: goutil.Int32Addr(1)},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name     string
+		Succeed  bool
+		Profiles []string
+		Result   *OpenCompose
+	}{
+		{
+			"No profile",
+			true,
+			nil,
+			&OpenCompose{
+				Version:  "0.1-dev",
+				Services: []Service{base.Services[0]},
+				Volumes:  base.Volumes,
+			},
+		},
+		{
+			"Single profile",
+			true,
+			[]string{"prod"},
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name:     "web",
+						Replicas: goutil.Int32Addr(3),
+						Containers: []Container{
+							{Name: "nginx", Image: "nginx:1.12"},
+						},
+					},
+				},
+				Volumes: []Volume{
+					{Name: "data", Size: "10Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+		},
+		{
+			"Profiles applied in order",
+			true,
+			[]string{"prod", "dev", "off"},
+			&OpenCompose{
+				Version: "0.1-dev",
+				Services: []Service{
+					{
+						Name:    "debug",
+						Enabled: goutil.BoolAddr(true),
+						Containers: []Container{
+							{Name: "busybox", Image: "busybox"},
+						},
+					},
+				},
+				Volumes: []Volume{
+					{Name: "data", Size: "10Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+		},
+		{
+			"Undefined profile",
+			false,
+			[]string{"staging"},
+			nil,
+		},
+		{
+			"Profile adding incomplete service",
+			false,
+			[]string{"incomplete"},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			original := base.DeepCopy()
+
+			o := base.DeepCopy()
+			err := o.ResolveProfiles(test.Profiles)
+
+			if !reflect.DeepEqual(base, original) {
+				t.Fatalf("Resolving profiles on a copy modified the original object: %s", spew.Sprint(base))
+			}
+
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to resolve profiles %v\nErr: %s", test.Profiles, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, profiles: %v\nResolved: %s", test.Profiles, spew.Sprint(o))
+				return
+			}
+
+			if !reflect.DeepEqual(o, test.Result) {
+				t.Errorf("Expected: %s\nGot: %s", spew.Sprint(test.Result), spew.Sprint(o))
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Returns the service merged over the template it extends (recursively, templates can extend other templates)
+func (o *OpenCompose) extend(s *Service, chain []string) (Service, error) {
+	if s.Extends == "" {
+		return s.DeepCopy(), nil
+	}
+
+	for _, name := range chain {
+		if name == s.Extends {
+			return Service{}, fmt.Errorf("templates extend each other: %s -> %s", strings.Join(chain, " -> "), s.Extends)
+		}
+	}
+
+	template, ok := o.Templates[s.Extends]
+	if !ok {
+		return Service{}, fmt.Errorf("template %q is not defined", s.Extends)
+	}
+
+	result, err := o.extend(&template, append(chain, s.Extends))
+	if err != nil {
+		return Service{}, err
+	}
+
+	override := s.DeepCopy()
+	if err := result.merge(&override, false); err != nil {
+		return Service{}, fmt.Errorf("template %q: %v", s.Extends, err)
+	}
+	result.Name = s.Name
+	result.Extends = ""
+
+	return result, nil
+}
+
+// Merges services over the templates they extend, the same way as when merging files.
+// Templates are removed afterwards as they are not needed anymore.
+// The result isn't validated, call Validate once the templates are resolved.
+func (o *OpenCompose) ResolveTemplates() error {
+	for i := range o.Services {
+		s := &o.Services[i]
+		if s.Extends == "" {
+			continue
+		}
+
+		resolved, err := o.extend(s, nil)
+		if err != nil {
+			return fmt.Errorf("service %q: %v", s.Name, err)
+		}
+
+		// service can leave out what the template has, but together they have to be complete
+		if err := resolved.checkComplete(); err != nil {
+			return err
+		}
+
+		o.Services[i] = resolved
+	}
+	o.Templates = nil
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+)
+
+func TestOpenCompose_ResolveTemplates(t *testing.T) {
+	templates := map[string]Service{
+		"java": {
+			Replicas: goutil.Int32Addr(2),
+			Labels:   Labels{"runtime": "java"},
+			Containers: []Container{
+				{
+					Name:  "app",
+					Image: "base/java",
+					Environment: []EnvVariable{
+						{Key: "LOG_LEVEL", Value: "info"},
+					},
+				},
+			},
+		},
+		"java-debug": {
+			Extends: "java",
+			Containers: []Container{
+				{
+					Name: "app",
+					Environment: []EnvVariable{
+						{Key: "LOG_LEVEL", Value: "debug"},
+					},
+				},
+			},
+		},
+		"loop-a": {Extends: "loop-b"},
+		"loop-b": {Extends: "loop-a"},
+		"no-image": {
+			Containers: []Container{
+				{Name: "app"},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name     string
+		Succeed  bool
+		Services []Service
+		Result   []Service
+	}{
+		{
+			"Service without template",
+			true,
+			[]Service{
+				{Name: "web", Containers: []Container{{Name: "nginx", Image: "nginx"}}},
+			},
+			[]Service{
+				{Name: "web", Containers: []Container{{Name: "nginx", Image: "nginx"}}},
+			},
+		},
+		{
+			"Service overrides template",
+			true,
+			[]Service{
+				{
+					Name:     "orders",
+					Extends:  "java",
+					Replicas: goutil.Int32Addr(3),
+					Labels:   Labels{"team": "a"},
+					Containers: []Container{
+						{Name: "app", Image: "acme/orders:1.0"},
+					},
+				},
+			},
+			[]Service{
+				{
+					Name:     "orders",
+					Replicas: goutil.Int32Addr(3),
+					Labels:   Labels{"runtime": "java", "team": "a"},
+					Containers: []Container{
+						{
+							Name:  "app",
+							Image: "acme/orders:1.0",
+							Environment: []EnvVariable{
+								{Key: "LOG_LEVEL", Value: "info"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"Template extending template",
+			true,
+			[]Service{
+				{Name: "billing", Extends: "java-debug"},
+			},
+			[]Service{
+				{
+					Name:     "billing",
+					Replicas: goutil.Int32Addr(2),
+					Labels:   Labels{"runtime": "java"},
+					Containers: []Container{
+						{
+							Name:  "app",
+							Image: "base/java",
+							Environment: []EnvVariable{
+								{Key: "LOG_LEVEL", Value: "debug"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"Undefined template",
+			false,
+			[]Service{
+				{Name: "web", Extends: "python"},
+			},
+			nil,
+		},
+		{
+			"Templates extending each other",
+			false,
+			[]Service{
+				{Name: "web", Extends: "loop-a"},
+			},
+			nil,
+		},
+		{
+			"Incomplete after resolving",
+			false,
+			[]Service{
+				{Name: "web", Extends: "no-image"},
+			},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			o := &OpenCompose{
+				Version:   "0.1-dev",
+				Services:  test.Services,
+				Templates: templates,
+			}
+			original := o.DeepCopy()
+
+			err := o.ResolveTemplates()
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to resolve templates for %s\nErr: %s", spew.Sprint(test.Services), err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, services: %s\nResolved: %s", spew.Sprint(test.Services), spew.Sprint(o.Services))
+				return
+			}
+
+			if !reflect.DeepEqual(o.Services, test.Result) {
+				t.Errorf("Expected: %s\nGot: %s", spew.Sprint(test.Result), spew.Sprint(o.Services))
+			}
+
+			if o.Templates != nil {
+				t.Errorf("Expected templates to be removed, got: %s", spew.Sprint(o.Templates))
+			}
+
+			if !reflect.DeepEqual(templates, original.Templates) {
+				t.Errorf("Resolving templates modified them: %s", spew.Sprint(templates))
+			}
+		})
+	}
+}
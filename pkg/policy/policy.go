@@ -0,0 +1,309 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/redhat-developer/opencompose/pkg/encoding"
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+// Code of the violations when they are reported as diagnostics
+const ErrorCode_PolicyViolation pkgutil.ErrorCode = "OC5001"
+
+// What the rule is evaluated against
+const (
+	// OpenCompose as it is written in the file, after merging and applying profiles
+	Target_OpenCompose = "opencompose"
+	// Objects generated by the transformer
+	Target_Objects = "objects"
+)
+
+const (
+	Severity_Error   = "error"
+	Severity_Warning = "warning"
+)
+
+// Rule requires the values its selector picks to be set and/or to match patterns
+type Rule struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Target      string `yaml:"target,omitempty"`
+	// Kind of the generated objects the rule applies to, e.g. "Service", all of them when empty
+	Kind     string `yaml:"kind,omitempty"`
+	Select   string `yaml:"select"`
+	Required bool   `yaml:"required,omitempty"`
+	// Values have to match Pattern and must not match Forbidden
+	Pattern   string `yaml:"pattern,omitempty"`
+	Forbidden string `yaml:"forbidden,omitempty"`
+	Severity  string `yaml:"severity,omitempty"`
+
+	selector  Selector
+	pattern   *regexp.Regexp
+	forbidden *regexp.Regexp
+}
+
+func (r *Rule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type RuleAlias Rule
+	var st struct {
+		RuleAlias `yaml:",inline"`
+		Leftovers map[string]interface{} `yaml:",inline"` // Catches all undefined fields and must be empty after parsing.
+	}
+	if err := unmarshal(&st); err != nil {
+		return err
+	}
+
+	encodingutil.RemoveExtensionKeys(st.Leftovers)
+	if len(st.Leftovers) > 0 {
+		return encodingutil.NewExcessKeysErrorFromMap("Rule", st.Leftovers)
+	}
+
+	*r = Rule(st.RuleAlias)
+
+	return nil
+}
+
+// Checks the rule and compiles its selector and patterns
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("%s", "rule has to have a name")
+	}
+
+	switch r.Target {
+	case "":
+		r.Target = Target_OpenCompose
+	case Target_OpenCompose, Target_Objects:
+	default:
+		return fmt.Errorf("rule %q: unknown target %q, must be either %q or %q", r.Name, r.Target, Target_OpenCompose, Target_Objects)
+	}
+
+	if r.Kind != "" && r.Target != Target_Objects {
+		return fmt.Errorf("rule %q: kind can be set only for target %q", r.Name, Target_Objects)
+	}
+
+	switch r.Severity {
+	case "":
+		r.Severity = Severity_Error
+	case Severity_Error, Severity_Warning:
+	default:
+		return fmt.Errorf("rule %q: unknown severity %q, must be either %q or %q", r.Name, r.Severity, Severity_Error, Severity_Warning)
+	}
+
+	var err error
+	if r.selector, err = ParseSelector(r.Select); err != nil {
+		return fmt.Errorf("rule %q: %s", r.Name, err)
+	}
+
+	if !r.Required && r.Pattern == "" && r.Forbidden == "" {
+		return fmt.Errorf("rule %q: at least one of required, pattern or forbidden has to be set", r.Name)
+	}
+
+	if r.Pattern != "" {
+		if r.pattern, err = regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("rule %q: invalid pattern: %s", r.Name, err)
+		}
+	}
+	if r.Forbidden != "" {
+		if r.forbidden, err = regexp.Compile(r.Forbidden); err != nil {
+			return fmt.Errorf("rule %q: invalid forbidden pattern: %s", r.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Policy is a set of rules loaded from a file
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Loads policy from YAML or JSON
+func Load(data []byte) (*Policy, error) {
+	var p Policy
+	if err := encodingutil.Unmarshal(data, &p); err != nil {
+		return nil, encodingutil.Wrapf(err, "failed to unmarshal policy")
+	}
+
+	names := make(map[string]bool)
+	for i := range p.Rules {
+		if err := p.Rules[i].compile(); err != nil {
+			return nil, err
+		}
+		if names[p.Rules[i].Name] {
+			return nil, fmt.Errorf("rule %q: defined more than once", p.Rules[i].Name)
+		}
+		names[p.Rules[i].Name] = true
+	}
+	return &p, nil
+}
+
+// Violation is a value that doesn't comply with a rule of the policy
+type Violation struct {
+	Rule     string
+	Severity string
+	Target   string
+	// Kind and name of the generated object the value is in
+	Kind string
+	Name string
+	// JSON pointer to the value, for OpenCompose items of lists are identified by their names
+	Path    string
+	Message string
+}
+
+func (v Violation) Error() string {
+	if v.Target == Target_Objects {
+		return fmt.Sprintf("policy rule %q: %s %q: %s: %s", v.Rule, v.Kind, v.Name, v.Path, v.Message)
+	}
+	return fmt.Sprintf("policy rule %q: %s: %s", v.Rule, v.Path, v.Message)
+}
+
+func (v Violation) Code() pkgutil.ErrorCode {
+	return ErrorCode_PolicyViolation
+}
+
+// Returns violations of the rule by the document
+func (r *Rule) check(document interface{}) []Violation {
+	var violations []Violation
+	violate := func(path string, format string, args ...interface{}) {
+		message := fmt.Sprintf(format, args...)
+		if r.Description != "" {
+			message = fmt.Sprintf("%s (%s)", message, r.Description)
+		}
+		violations = append(violations, Violation{Rule: r.Name, Severity: r.Severity, Target: r.Target, Path: path, Message: message})
+	}
+
+	for _, m := range r.selector.find(document) {
+		if !m.Exists {
+			if r.Required {
+				violate(m.Path, "%s is required", r.Select)
+			}
+			continue
+		}
+
+		value := scalarString(m.Value)
+		if r.pattern != nil && !r.pattern.MatchString(value) {
+			violate(m.Path, "%q doesn't match %q", value, r.Pattern)
+		}
+		if r.forbidden != nil && r.forbidden.MatchString(value) {
+			violate(m.Path, "%q matches forbidden %q", value, r.Forbidden)
+		}
+	}
+	return violations
+}
+
+// Returns value as it would be written in the file, collections in JSON
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Converts document decoded from YAML or JSON into maps with string keys and slices
+func normalize(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for k, v := range n {
+			m[fmt.Sprint(k)] = normalize(v)
+		}
+		return m
+	case map[string]interface{}:
+		for k, v := range n {
+			n[k] = normalize(v)
+		}
+		return n
+	case []interface{}:
+		for i, v := range n {
+			n[i] = normalize(v)
+		}
+		return n
+	default:
+		return node
+	}
+}
+
+// Returns OpenCompose the way it is written in the file of the current spec version
+func openComposeDocument(o *object.OpenCompose) (interface{}, error) {
+	encoder, err := encoding.GetEncoderFor(encoding.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := encoder.Encode(o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenCompose: %s", err)
+	}
+
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal encoded OpenCompose: %s", err)
+	}
+	return normalize(document), nil
+}
+
+// Returns kind of the generated object, the transformers don't set TypeMeta
+func objectKind(obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
+
+// Evaluates the rules against OpenCompose and the objects generated from it
+func (p *Policy) Evaluate(o *object.OpenCompose, objects []runtime.Object) ([]Violation, error) {
+	var violations []Violation
+
+	document, err := openComposeDocument(o)
+	if err != nil {
+		return nil, err
+	}
+	for i := range p.Rules {
+		if p.Rules[i].Target == Target_OpenCompose {
+			violations = append(violations, p.Rules[i].check(document)...)
+		}
+	}
+
+	for _, obj := range objects {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal generated object: %s", err)
+		}
+		var document interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal generated object: %s", err)
+		}
+
+		kind := objectKind(obj)
+		name := ""
+		if metadata, ok := document.(map[string]interface{})["metadata"].(map[string]interface{}); ok {
+			name, _ = metadata["name"].(string)
+		}
+
+		for i := range p.Rules {
+			rule := &p.Rules[i]
+			if rule.Target != Target_Objects || (rule.Kind != "" && rule.Kind != kind) {
+				continue
+			}
+			for _, v := range rule.check(document) {
+				v.Kind, v.Name = kind, name
+				violations = append(violations, v)
+			}
+		}
+	}
+
+	return violations, nil
+}
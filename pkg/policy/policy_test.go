@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/object"
+	"github.com/redhat-developer/opencompose/pkg/transform/kubernetes"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Succeed bool
+		Policy  string
+	}{
+		{"Empty", true, ""},
+		{"Rules", true, `
+rules:
+- name: registry
+  select: services.*.containers.*.image
+  pattern: ^registry\.corp/
+- name: no-loadbalancer
+  target: objects
+  kind: Service
+  select: spec.type
+  forbidden: ^LoadBalancer$
+  severity: warning
+`},
+		{"JSON", true, `{"rules": [{"name": "team", "select": "services.*.labels.team", "required": true}]}`},
+		{"Unknown key", false, "rules:\n- name: a\n  selct: services\n  required: true\n"},
+		{"Missing name", false, "rules:\n- select: services\n  required: true\n"},
+		{"No check", false, "rules:\n- name: a\n  select: services\n"},
+		{"Missing selector", false, "rules:\n- name: a\n  required: true\n"},
+		{"Unknown target", false, "rules:\n- name: a\n  target: cluster\n  select: services\n  required: true\n"},
+		{"Kind for OpenCompose", false, "rules:\n- name: a\n  kind: Service\n  select: services\n  required: true\n"},
+		{"Unknown severity", false, "rules:\n- name: a\n  select: services\n  required: true\n  severity: fatal\n"},
+		{"Invalid pattern", false, "rules:\n- name: a\n  select: services\n  pattern: '('\n"},
+		{"Duplicate rule", false, "rules:\n- name: a\n  select: services\n  required: true\n- name: a\n  select: volumes\n  required: true\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			p, err := Load([]byte(tt.Policy))
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to load policy: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %#v", p)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Load([]byte(`
+rules:
+- name: registry
+  select: services.*.containers.*.image
+  pattern: ^registry\.corp/
+- name: team
+  select: services.*.labels.team
+  required: true
+  severity: warning
+- name: no-loadbalancer
+  target: objects
+  kind: Service
+  select: spec.type
+  forbidden: ^LoadBalancer$
+`))
+	if err != nil {
+		t.Fatalf("Failed to load policy: %s", err)
+	}
+
+	o := &object.OpenCompose{
+		Version: "0.1-dev",
+		Services: []object.Service{
+			{
+				Name:   "web",
+				Labels: object.Labels{"team": "web"},
+				Containers: []object.Container{
+					{
+						Name:  "nginx",
+						Image: "registry.corp/nginx:1.11",
+						Ports: []object.Port{
+							{Port: object.PortMapping{ContainerPort: 80, ServicePort: 80}, Type: object.PortType_External},
+						},
+					},
+				},
+			},
+			{
+				Name: "db",
+				Containers: []object.Container{
+					{
+						Name:  "mysql",
+						Image: "mysql:5.7",
+						Ports: []object.Port{
+							{Port: object.PortMapping{ContainerPort: 3306, ServicePort: 3306}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	transformer := &kubernetes.Transformer{}
+	objects, err := transformer.Transform(o)
+	if err != nil {
+		t.Fatalf("Failed to transform: %s", err)
+	}
+
+	violations, err := p.Evaluate(o, objects)
+	if err != nil {
+		t.Fatalf("Failed to evaluate policy: %s", err)
+	}
+
+	var got []string
+	for _, v := range violations {
+		got = append(got, fmt.Sprintf("%s %s %s %s %s", v.Rule, v.Severity, v.Kind, v.Name, v.Path))
+	}
+	expected := []string{
+		"registry error   /services/db/containers/mysql/image",
+		"team warning   /services/db/labels/team",
+		"no-loadbalancer error Service web /spec/type",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected violations %#v, got %#v", expected, got)
+	}
+}
@@ -0,0 +1,161 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	encodingutil "github.com/redhat-developer/opencompose/pkg/encoding/util"
+)
+
+// Selector picks values of a document by their path, e.g. "services.*.containers.*.image".
+// Segments are separated by '.', "*" stands for every item of a list or value of a mapping
+// and keys containing '.' are written in brackets, e.g. `labels["app.kubernetes.io/name"]`.
+type Selector []string
+
+// Segment of the selector matching every item or value
+const selectorWildcard = "*"
+
+func ParseSelector(s string) (Selector, error) {
+	var selector Selector
+	rest := s
+	for rest != "" {
+		var segment string
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("selector %q: missing ']'", s)
+			}
+			key, err := strconv.Unquote(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("selector %q: key in brackets has to be quoted: %s", s, rest[1:end])
+			}
+			segment, rest = key, rest[end+1:]
+			rest = strings.TrimPrefix(rest, ".")
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segment, rest = rest[:end], rest[end:]
+			if segment == "" {
+				return nil, fmt.Errorf("selector %q: empty segment", s)
+			}
+			rest = strings.TrimPrefix(rest, ".")
+		}
+		selector = append(selector, segment)
+	}
+
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("%s", "selector can't be empty")
+	}
+	return selector, nil
+}
+
+func (s Selector) String() string {
+	result := ""
+	for i, segment := range s {
+		if strings.ContainsAny(segment, ".[]") {
+			result += fmt.Sprintf("[%q]", segment)
+			continue
+		}
+		if i > 0 {
+			result += "."
+		}
+		result += segment
+	}
+	return result
+}
+
+// Value found by a selector
+type match struct {
+	// JSON pointer to the value, items of lists are identified by their names where they have one
+	Path  string
+	Value interface{}
+	// False when the last key of the selector is missing
+	Exists bool
+}
+
+// Returns the values the selector picks out of node, a document decoded into maps and slices.
+// Values whose key is missing are returned too so rules can require them, every item
+// or value "*" goes through gets its own match.
+func (s Selector) find(node interface{}) []match {
+	return s.findAt(node, "")
+}
+
+func (s Selector) findAt(node interface{}, path string) []match {
+	if len(s) == 0 {
+		return []match{{Path: path, Value: node, Exists: true}}
+	}
+
+	segment, rest := s[0], s[1:]
+	if segment == selectorWildcard {
+		var matches []match
+		switch n := node.(type) {
+		case []interface{}:
+			for i, item := range n {
+				key := strconv.Itoa(i)
+				if m, ok := item.(map[string]interface{}); ok {
+					if name, ok := m["name"].(string); ok && name != "" {
+						key = name
+					}
+				}
+				matches = append(matches, rest.findAt(item, path+"/"+encodingutil.EscapePathSegment(key))...)
+			}
+		case map[string]interface{}:
+			for _, key := range sortedKeys(n) {
+				matches = append(matches, rest.findAt(n[key], path+"/"+encodingutil.EscapePathSegment(key))...)
+			}
+		}
+		return matches
+	}
+
+	childPath := path + "/" + encodingutil.EscapePathSegment(segment)
+	var child interface{}
+	exists := false
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, exists = n[segment]
+	case []interface{}:
+		if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(n) {
+			child, exists = n[i], true
+		}
+	}
+
+	if !exists {
+		// only a missing value itself can be required, there is nothing to go through further down
+		if len(rest) == 0 || !containsWildcard(rest) {
+			return []match{{Path: childPath + pathOf(rest), Exists: false}}
+		}
+		return nil
+	}
+	return rest.findAt(child, childPath)
+}
+
+func containsWildcard(s Selector) bool {
+	for _, segment := range s {
+		if segment == selectorWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns JSON pointer of the segments
+func pathOf(segments []string) string {
+	path := ""
+	for _, segment := range segments {
+		path += "/" + encodingutil.EscapePathSegment(segment)
+	}
+	return path
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		Selector string
+		Succeed  bool
+		Segments Selector
+	}{
+		{"services", true, Selector{"services"}},
+		{"services.*.containers.*.image", true, Selector{"services", "*", "containers", "*", "image"}},
+		{`services.*.labels["app.kubernetes.io/name"]`, true, Selector{"services", "*", "labels", "app.kubernetes.io/name"}},
+		{`["a.b"].c`, true, Selector{"a.b", "c"}},
+		{"", false, nil},
+		{"services..image", false, nil},
+		{"services.", true, Selector{"services"}},
+		{`labels["app`, false, nil},
+		{`labels[app]`, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Selector, func(t *testing.T) {
+			s, err := ParseSelector(tt.Selector)
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to parse selector: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %#v", s)
+			}
+
+			if !reflect.DeepEqual(s, tt.Segments) {
+				t.Errorf("Expected %#v, got %#v", tt.Segments, s)
+			}
+		})
+	}
+}
+
+func TestSelectorFind(t *testing.T) {
+	document := map[string]interface{}{
+		"services": []interface{}{
+			map[string]interface{}{
+				"name":   "web",
+				"labels": map[string]interface{}{"team": "web", "app.kubernetes.io/name": "shop"},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "nginx", "image": "nginx"},
+					map[string]interface{}{"image": "sidecar"},
+				},
+			},
+			map[string]interface{}{
+				"name": "db",
+			},
+		},
+	}
+
+	tests := []struct {
+		Selector string
+		Matches  []match
+	}{
+		{
+			"services.*.containers.*.image",
+			[]match{
+				{"/services/web/containers/nginx/image", "nginx", true},
+				{"/services/web/containers/1/image", "sidecar", true},
+			},
+		},
+		{
+			"services.*.labels.team",
+			[]match{
+				{"/services/web/labels/team", "web", true},
+				{"/services/db/labels/team", nil, false},
+			},
+		},
+		{
+			`services.*.labels["app.kubernetes.io/name"]`,
+			[]match{
+				{"/services/web/labels/app.kubernetes.io~1name", "shop", true},
+				{"/services/db/labels/app.kubernetes.io~1name", nil, false},
+			},
+		},
+		{
+			"services.0.name",
+			[]match{
+				{"/services/0/name", "web", true},
+			},
+		},
+		{
+			"services.*.labels.*",
+			[]match{
+				{"/services/web/labels/app.kubernetes.io~1name", "shop", true},
+				{"/services/web/labels/team", "web", true},
+			},
+		},
+		{
+			"volumes.*.size",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Selector, func(t *testing.T) {
+			s, err := ParseSelector(tt.Selector)
+			if err != nil {
+				t.Fatalf("Failed to parse selector: %s", err)
+			}
+
+			matches := s.find(document)
+			if !reflect.DeepEqual(matches, tt.Matches) {
+				t.Errorf("Expected %#v, got %#v", tt.Matches, matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redhat-developer/opencompose/pkg/object"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/pkg/api/unversioned"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+const (
+	// Version of the generated docker-compose files
+	Version = "3"
+	// Name of the file written to the output directory
+	Filename = "docker-compose.yaml"
+)
+
+// File is a docker-compose file, it's not a Kubernetes object so it has no kind and is written as it is
+type File struct {
+	Version  string              `json:"version" yaml:"version"`
+	Services map[string]*Service `json:"services" yaml:"services"`
+	Volumes  map[string]*Volume  `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	// What works differently than in the cluster, it's not written
+	Warnings []string `json:"-" yaml:"-"`
+}
+
+func (f *File) GetObjectKind() unversioned.ObjectKind {
+	return unversioned.EmptyObjectKind
+}
+
+// Returns the file in YAML
+func (f *File) Marshal() ([]byte, error) {
+	return yaml.Marshal(f)
+}
+
+type Service struct {
+	Image       string            `json:"image,omitempty" yaml:"image,omitempty"`
+	Build       *Build            `json:"build,omitempty" yaml:"build,omitempty"`
+	Entrypoint  []string          `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+	Command     []string          `json:"command,omitempty" yaml:"command,omitempty"`
+	Environment []string          `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Ports       []string          `json:"ports,omitempty" yaml:"ports,omitempty"`
+	NetworkMode string            `json:"network_mode,omitempty" yaml:"network_mode,omitempty"`
+	DependsOn   []string          `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	Volumes     []interface{}     `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Restart     string            `json:"restart,omitempty" yaml:"restart,omitempty"`
+}
+
+type Build struct {
+	Context    string   `json:"context" yaml:"context"`
+	Dockerfile string   `json:"dockerfile,omitempty" yaml:"dockerfile,omitempty"`
+	Args       []string `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// Named volume, docker creates it with the default driver
+type Volume struct{}
+
+// Mount in the long syntax, used for mounts of a subpath of the volume
+type Mount struct {
+	Type     string        `json:"type" yaml:"type"`
+	Source   string        `json:"source" yaml:"source"`
+	Target   string        `json:"target" yaml:"target"`
+	ReadOnly bool          `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+	Volume   *MountOptions `json:"volume,omitempty" yaml:"volume,omitempty"`
+}
+
+type MountOptions struct {
+	Subpath string `json:"subpath,omitempty" yaml:"subpath,omitempty"`
+}
+
+type Transformer struct{}
+
+// Values are interpolated by docker-compose, OpenCompose variables are already substituted
+// so "$" has to be escaped to be kept as it is
+func escape(value string) string {
+	return strings.Replace(value, "$", "$$", -1)
+}
+
+func escapeAll(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escape(value)
+	}
+	return escaped
+}
+
+// Returns name of the named volume created for EmptyDir volume of the service
+func emptyDirVolumeName(s *object.Service, name string) string {
+	return fmt.Sprintf("%s-%s", s.Name, name)
+}
+
+func createBuild(b *object.Build) *Build {
+	// the fragment selects the ref and the directory in the repository, e.g. "#master:docker"
+	context := b.Git.URL
+	if b.Git.Ref != "" || b.Git.ContextDir != "" {
+		context += "#" + b.Git.Ref
+	}
+	if b.Git.ContextDir != "" {
+		context += ":" + b.Git.ContextDir
+	}
+
+	build := &Build{
+		Context:    context,
+		Dockerfile: b.Dockerfile,
+	}
+	for _, arg := range b.Args {
+		build.Args = append(build.Args, fmt.Sprintf("%s=%s", arg.Key, escape(arg.Value)))
+	}
+	return build
+}
+
+// Creates docker-compose service for the container, its ports are published by the service
+// that owns the network namespace of the group
+func (t *Transformer) CreateService(s *object.Service, c *object.Container) *Service {
+	cs := &Service{
+		Image:      c.Image,
+		Entrypoint: escapeAll(c.Command),
+		Command:    escapeAll(c.Args),
+		// Deployments restart the containers whenever they stop
+		Restart: "always",
+	}
+
+	if c.Build != nil {
+		cs.Build = createBuild(c.Build)
+	}
+
+	for _, e := range c.Environment {
+		cs.Environment = append(cs.Environment, fmt.Sprintf("%s=%s", e.Key, escape(e.Value)))
+	}
+
+	for _, m := range c.Mounts {
+		source := m.VolumeRef
+		if s.EmptyDirVolumeExists(m.VolumeRef) {
+			source = emptyDirVolumeName(s, m.VolumeRef)
+		}
+
+		if m.VolumeSubPath != "" {
+			cs.Volumes = append(cs.Volumes, &Mount{
+				Type:     "volume",
+				Source:   source,
+				Target:   m.MountPath,
+				ReadOnly: m.ReadOnly,
+				Volume:   &MountOptions{Subpath: m.VolumeSubPath},
+			})
+			continue
+		}
+
+		volume := fmt.Sprintf("%s:%s", source, m.MountPath)
+		if m.ReadOnly {
+			volume += ":ro"
+		}
+		cs.Volumes = append(cs.Volumes, volume)
+	}
+
+	if len(s.Labels) > 0 {
+		cs.Labels = make(map[string]string)
+		for key, value := range s.Labels {
+			cs.Labels[key] = escape(value)
+		}
+	}
+
+	return cs
+}
+
+// Creates docker-compose services for the containers of the service. The first container is named
+// after the service so the others can reach it by the same name as in the cluster, the rest join
+// its network namespace like containers of a pod do.
+func (t *Transformer) CreateServices(s *object.Service) (map[string]*Service, error) {
+	services := make(map[string]*Service)
+
+	var main *Service
+	for i := range s.Containers {
+		c := &s.Containers[i]
+		cs := t.CreateService(s, c)
+
+		name := s.Name
+		if main == nil {
+			main = cs
+		} else {
+			name = fmt.Sprintf("%s-%s", s.Name, c.Name)
+			cs.NetworkMode = "service:" + s.Name
+			cs.DependsOn = []string{s.Name}
+		}
+		services[name] = cs
+
+		// containers joining the network namespace can't publish ports
+		for _, p := range c.Ports {
+			port := fmt.Sprintf("%d:%d", p.Port.ServicePort, p.Port.ContainerPort)
+			if p.Type == object.PortType_Internal {
+				port = "127.0.0.1:" + port
+			}
+			main.Ports = append(main.Ports, port)
+		}
+	}
+
+	return services, nil
+}
+
+func (t *Transformer) Transform(o *object.OpenCompose) ([]runtime.Object, error) {
+	f := &File{
+		Version:  Version,
+		Services: make(map[string]*Service),
+		Volumes:  make(map[string]*Volume),
+	}
+
+	for _, v := range o.Volumes {
+		f.Volumes[v.Name] = &Volume{}
+	}
+
+	// services publishing the host ports, a port published on all interfaces
+	// collides with the same port published on 127.0.0.1 as well
+	published := make(map[int]string)
+
+	for i := range o.Services {
+		s := &o.Services[i]
+
+		services, err := t.CreateServices(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform service %q: %s", s.Name, err)
+		}
+		for name, cs := range services {
+			if _, ok := f.Services[name]; ok {
+				return nil, fmt.Errorf("failed to transform service %q: there is already docker-compose service %q", s.Name, name)
+			}
+			f.Services[name] = cs
+		}
+
+		for _, c := range s.Containers {
+			for _, p := range c.Ports {
+				if owner, ok := published[p.Port.ServicePort]; ok {
+					return nil, fmt.Errorf("failed to transform service %q: host port %d is already published by service %q", s.Name, p.Port.ServicePort, owner)
+				}
+				published[p.Port.ServicePort] = s.Name
+
+				// the other services reach the container port by the service name, not the service port
+				if p.Type == object.PortType_Internal && p.Port.ServicePort != p.Port.ContainerPort {
+					f.Warnings = append(f.Warnings, fmt.Sprintf("service %q: internal port %d is reachable by the other services as %s:%d, docker-compose can't map it to service port %d", s.Name, p.Port.ContainerPort, s.Name, p.Port.ContainerPort, p.Port.ServicePort))
+				}
+			}
+		}
+
+		for _, emptyDir := range s.EmptyDirVolumes {
+			name := emptyDirVolumeName(s, emptyDir.Name)
+			if _, ok := f.Volumes[name]; ok {
+				return nil, fmt.Errorf("failed to transform service %q: there is already volume %q", s.Name, name)
+			}
+			f.Volumes[name] = &Volume{}
+		}
+	}
+
+	if len(f.Volumes) == 0 {
+		f.Volumes = nil
+	}
+
+	return []runtime.Object{f}, nil
+}
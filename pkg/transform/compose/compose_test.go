@@ -0,0 +1,382 @@
+package compose
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+)
+
+func TestTransformer_CreateServices(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Service  *object.Service
+		Services map[string]*Service
+	}{
+		{
+			"One container",
+			&object.Service{
+				Name: "web",
+				Containers: []object.Container{
+					{
+						Name:    "nginx",
+						Image:   "nginx",
+						Command: []string{"nginx"},
+						Args:    []string{"-g", "daemon off;"},
+						Environment: []object.EnvVariable{
+							{Key: "PRICE", Value: "$5"},
+						},
+						Ports: []object.Port{
+							{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_External, Host: goutil.StringAddr("example.com")},
+							{Port: object.PortMapping{ContainerPort: 9090, ServicePort: 9090}, Type: object.PortType_Internal},
+						},
+					},
+				},
+				Labels: object.Labels{"team": "frontend"},
+			},
+			map[string]*Service{
+				"web": {
+					Image:       "nginx",
+					Entrypoint:  []string{"nginx"},
+					Command:     []string{"-g", "daemon off;"},
+					Environment: []string{"PRICE=$$5"},
+					Ports:       []string{"8080:80", "127.0.0.1:9090:9090"},
+					Labels:      map[string]string{"team": "frontend"},
+					Restart:     "always",
+				},
+			},
+		},
+		{
+			"Grouped containers",
+			&object.Service{
+				Name: "web",
+				Containers: []object.Container{
+					{
+						Name:  "app",
+						Image: "app",
+						Mounts: []object.Mount{
+							{VolumeRef: "data", MountPath: "/data", ReadOnly: true},
+							{VolumeRef: "cache", MountPath: "/cache"},
+						},
+					},
+					{
+						Name:  "proxy",
+						Image: "nginx",
+						Ports: []object.Port{
+							{Port: object.PortMapping{ContainerPort: 80, ServicePort: 80}, Type: object.PortType_External},
+						},
+						Mounts: []object.Mount{
+							{VolumeRef: "cache", MountPath: "/var/cache/nginx", VolumeSubPath: "nginx"},
+						},
+					},
+				},
+				EmptyDirVolumes: []object.EmptyDirVolume{
+					{Name: "cache"},
+				},
+			},
+			map[string]*Service{
+				"web": {
+					Image:   "app",
+					Ports:   []string{"80:80"},
+					Volumes: []interface{}{"data:/data:ro", "web-cache:/cache"},
+					Restart: "always",
+				},
+				"web-proxy": {
+					Image:       "nginx",
+					NetworkMode: "service:web",
+					DependsOn:   []string{"web"},
+					Volumes: []interface{}{
+						&Mount{Type: "volume", Source: "web-cache", Target: "/var/cache/nginx", Volume: &MountOptions{Subpath: "nginx"}},
+					},
+					Restart: "always",
+				},
+			},
+		},
+		{
+			"Build",
+			&object.Service{
+				Name: "app",
+				Containers: []object.Container{
+					{
+						Name:  "app",
+						Image: "registry.example.com/app",
+						Build: &object.Build{
+							Git:        object.GitSource{URL: "https://github.com/example/app.git", Ref: "master", ContextDir: "docker"},
+							Dockerfile: "Dockerfile.prod",
+							Args:       []object.EnvVariable{{Key: "VERSION", Value: "1"}},
+						},
+					},
+				},
+			},
+			map[string]*Service{
+				"app": {
+					Image: "registry.example.com/app",
+					Build: &Build{
+						Context:    "https://github.com/example/app.git#master:docker",
+						Dockerfile: "Dockerfile.prod",
+						Args:       []string{"VERSION=1"},
+					},
+					Restart: "always",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			transformer := Transformer{}
+			services, err := transformer.CreateServices(tt.Service)
+			if err != nil {
+				t.Fatalf("Failed to create services: %s", err)
+			}
+
+			if !reflect.DeepEqual(services, tt.Services) {
+				t.Errorf("Expected:\n%s\ngot:\n%s", spew.Sdump(tt.Services), spew.Sdump(services))
+			}
+		})
+	}
+}
+
+func TestTransformer_Transform(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Succeed     bool
+		OpenCompose *object.OpenCompose
+		File        string
+		Warnings    []string
+	}{
+		{
+			"Volumes",
+			true,
+			&object.OpenCompose{
+				Services: []object.Service{
+					{
+						Name: "db",
+						Containers: []object.Container{
+							{
+								Name:  "mysql",
+								Image: "mysql",
+								Mounts: []object.Mount{
+									{VolumeRef: "data", MountPath: "/var/lib/mysql"},
+									{VolumeRef: "tmp", MountPath: "/tmp"},
+								},
+							},
+						},
+						EmptyDirVolumes: []object.EmptyDirVolume{
+							{Name: "tmp"},
+						},
+					},
+				},
+				Volumes: []object.Volume{
+					{Name: "data", Size: "1Gi", AccessMode: "ReadWriteOnce"},
+				},
+			},
+			`
+version: "3"
+services:
+  db:
+    image: mysql
+    volumes:
+    - data:/var/lib/mysql
+    - db-tmp:/tmp
+    restart: always
+volumes:
+  data: {}
+  db-tmp: {}
+`,
+			nil,
+		},
+		{
+			"Ports",
+			true,
+			&object.OpenCompose{
+				Services: []object.Service{
+					{
+						Name: "web",
+						Containers: []object.Container{
+							{
+								Name:  "nginx",
+								Image: "nginx",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_External},
+									{Port: object.PortMapping{ContainerPort: 9090, ServicePort: 9090}, Type: object.PortType_Internal},
+								},
+							},
+						},
+					},
+					{
+						Name: "db",
+						Containers: []object.Container{
+							{
+								Name:  "mysql",
+								Image: "mysql",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 3306, ServicePort: 3307}, Type: object.PortType_Internal},
+								},
+							},
+						},
+					},
+					{
+						Name: "cache",
+						Containers: []object.Container{
+							{
+								Name:  "redis",
+								Image: "redis",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 6379, ServicePort: 6379}, Type: object.PortType_Internal},
+								},
+							},
+						},
+					},
+				},
+			},
+			`
+version: "3"
+services:
+  cache:
+    image: redis
+    ports:
+    - 127.0.0.1:6379:6379
+    restart: always
+  db:
+    image: mysql
+    ports:
+    - 127.0.0.1:3307:3306
+    restart: always
+  web:
+    image: nginx
+    ports:
+    - 8080:80
+    - 127.0.0.1:9090:9090
+    restart: always
+`,
+			[]string{`service "db": internal port 3306 is reachable by the other services as db:3306, docker-compose can't map it to service port 3307`},
+		},
+		{
+			"Host port collision",
+			false,
+			&object.OpenCompose{
+				Services: []object.Service{
+					{
+						Name: "web",
+						Containers: []object.Container{
+							{
+								Name:  "nginx",
+								Image: "nginx",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_External},
+								},
+							},
+						},
+					},
+					{
+						Name: "admin",
+						Containers: []object.Container{
+							{
+								Name:  "nginx",
+								Image: "nginx",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 8080, ServicePort: 8080}, Type: object.PortType_External},
+								},
+							},
+						},
+					},
+				},
+			},
+			"",
+			nil,
+		},
+		{
+			"Host port collision with internal port",
+			false,
+			&object.OpenCompose{
+				Services: []object.Service{
+					{
+						Name: "web",
+						Containers: []object.Container{
+							{
+								Name:  "nginx",
+								Image: "nginx",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_External},
+								},
+							},
+						},
+					},
+					{
+						Name: "admin",
+						Containers: []object.Container{
+							{
+								Name:  "nginx",
+								Image: "nginx",
+								Ports: []object.Port{
+									{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_Internal},
+								},
+							},
+						},
+					},
+				},
+			},
+			"",
+			nil,
+		},
+		{
+			"Name collision",
+			false,
+			&object.OpenCompose{
+				Services: []object.Service{
+					{
+						Name: "web",
+						Containers: []object.Container{
+							{Name: "web", Image: "app"},
+							{Name: "proxy", Image: "nginx"},
+						},
+					},
+					{
+						Name: "web-proxy",
+						Containers: []object.Container{
+							{Name: "proxy", Image: "nginx"},
+						},
+					},
+				},
+			},
+			"",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			transformer := Transformer{}
+			objects, err := transformer.Transform(tt.OpenCompose)
+			if err != nil {
+				if tt.Succeed {
+					t.Fatalf("Failed to transform: %s", err)
+				}
+				return
+			}
+			if !tt.Succeed {
+				t.Fatalf("Expected to fail, got %s", spew.Sdump(objects))
+			}
+
+			if len(objects) != 1 {
+				t.Fatalf("Expected one docker-compose file, got %s", spew.Sdump(objects))
+			}
+			data, err := objects[0].(*File).Marshal()
+			if err != nil {
+				t.Fatalf("Failed to marshal: %s", err)
+			}
+
+			if expected := strings.TrimPrefix(tt.File, "\n"); string(data) != expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", expected, data)
+			}
+
+			if warnings := objects[0].(*File).Warnings; !reflect.DeepEqual(warnings, tt.Warnings) {
+				t.Errorf("Expected warnings %#v, got %#v", tt.Warnings, warnings)
+			}
+		})
+	}
+}
@@ -152,14 +152,13 @@ func (t *Transformer) CreateIngresses(o *object.Service) ([]runtime.Object, erro
 	return result, nil
 }
 
-// Create k8s deployments for OpenCompose service
-func (t *Transformer) CreateDeployments(s *object.Service) ([]runtime.Object, error) {
-	result := []runtime.Object{}
+// Create pod template for OpenCompose service
+// It is shared by all controllers that manage pods for the service.
+func (t *Transformer) CreatePodTemplate(s *object.Service) (*api_v1.PodTemplateSpec, error) {
 	serviceLabels := map[string]string(s.Labels)
 
-	d := &ext_v1beta1.Deployment{
+	pt := &api_v1.PodTemplateSpec{
 		ObjectMeta: api_v1.ObjectMeta{
-			Name: s.Name,
 			Labels: *util.MergeMaps(
 				// The map containing `"service": s.Name` should always be
 				// passed later to avoid being overridden by util.MergeMaps()
@@ -169,35 +168,15 @@ func (t *Transformer) CreateDeployments(s *object.Service) ([]runtime.Object, er
 				},
 			),
 		},
-		Spec: ext_v1beta1.DeploymentSpec{
-			Strategy: ext_v1beta1.DeploymentStrategy{
-				// TODO: make it configurable
-				Type: ext_v1beta1.RollingUpdateDeploymentStrategyType,
-				// TODO: make it configurable
-				RollingUpdate: nil,
-			},
-			Template: api_v1.PodTemplateSpec{
-				ObjectMeta: api_v1.ObjectMeta{
-					Labels: *util.MergeMaps(
-						// The map containing `"service": s.Name` should always be
-						// passed later to avoid being overridden by util.MergeMaps()
-						&serviceLabels,
-						&map[string]string{
-							"service": s.Name,
-						},
-					),
-				},
-				Spec: api_v1.PodSpec{},
-			},
-		},
+		Spec: api_v1.PodSpec{},
 	}
 
-	d.Spec.Replicas = s.Replicas
-
 	for _, c := range s.Containers {
 		kc := api_v1.Container{
-			Name:  c.Name,
-			Image: c.Image,
+			Name:    c.Name,
+			Image:   c.Image,
+			Command: c.Command,
+			Args:    c.Args,
 		}
 
 		for _, e := range c.Environment {
@@ -207,9 +186,16 @@ func (t *Transformer) CreateDeployments(s *object.Service) ([]runtime.Object, er
 			})
 		}
 
+		// the same container port can be mapped to more service ports, it's listed once
+		containerPorts := make(map[int]bool)
 		for _, p := range c.Ports {
+			if containerPorts[p.Port.ContainerPort] {
+				continue
+			}
+			containerPorts[p.Port.ContainerPort] = true
+
 			kc.Ports = append(kc.Ports, api_v1.ContainerPort{
-				Name:          c.Name,
+				Name:          p.ContainerPortName(),
 				ContainerPort: int32(p.Port.ContainerPort),
 			})
 		}
@@ -237,11 +223,11 @@ func (t *Transformer) CreateDeployments(s *object.Service) ([]runtime.Object, er
 						},
 					},
 				}
-				d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, volume)
+				pt.Spec.Volumes = append(pt.Spec.Volumes, volume)
 			}
 		}
 
-		d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, kc)
+		pt.Spec.Containers = append(pt.Spec.Containers, kc)
 	}
 
 	// make entry of emptydir in deployment volume directive
@@ -252,9 +238,47 @@ func (t *Transformer) CreateDeployments(s *object.Service) ([]runtime.Object, er
 				EmptyDir: &api_v1.EmptyDirVolumeSource{},
 			},
 		}
-		d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, volume)
+		pt.Spec.Volumes = append(pt.Spec.Volumes, volume)
+	}
+
+	return pt, nil
+}
+
+// Create k8s deployments for OpenCompose service
+func (t *Transformer) CreateDeployments(s *object.Service) ([]runtime.Object, error) {
+	result := []runtime.Object{}
+	serviceLabels := map[string]string(s.Labels)
+
+	pt, err := t.CreatePodTemplate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ext_v1beta1.Deployment{
+		ObjectMeta: api_v1.ObjectMeta{
+			Name: s.Name,
+			Labels: *util.MergeMaps(
+				// The map containing `"service": s.Name` should always be
+				// passed later to avoid being overridden by util.MergeMaps()
+				&serviceLabels,
+				&map[string]string{
+					"service": s.Name,
+				},
+			),
+		},
+		Spec: ext_v1beta1.DeploymentSpec{
+			Strategy: ext_v1beta1.DeploymentStrategy{
+				// TODO: make it configurable
+				Type: ext_v1beta1.RollingUpdateDeploymentStrategyType,
+				// TODO: make it configurable
+				RollingUpdate: nil,
+			},
+			Template: *pt,
+		},
 	}
 
+	d.Spec.Replicas = s.Replicas
+
 	result = append(result, d)
 
 	return result, nil
@@ -670,6 +670,91 @@ func TestTransformer_CreateDeployments(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Ports",
+			true,
+			&object.Service{
+				Name: name,
+				Containers: []object.Container{
+					{
+						Name:  containerName,
+						Image: image,
+						Ports: []object.Port{
+							{Port: object.PortMapping{ContainerPort: 80, ServicePort: 80}},
+							{Port: object.PortMapping{ContainerPort: 443, ServicePort: 443}},
+							{Port: object.PortMapping{ContainerPort: 80, ServicePort: 8080}, Type: object.PortType_External},
+						},
+					},
+				},
+			},
+			[]runtime.Object{
+				&ext_v1beta1.Deployment{
+					ObjectMeta: sMeta,
+					Spec: ext_v1beta1.DeploymentSpec{
+						Strategy: strategy,
+						Template: api_v1.PodTemplateSpec{
+							ObjectMeta: api_v1.ObjectMeta{
+								Labels: map[string]string{
+									"service": name,
+								},
+							},
+							Spec: api_v1.PodSpec{
+								Containers: []api_v1.Container{
+									{
+										Name:  containerName,
+										Image: image,
+										Ports: []api_v1.ContainerPort{
+											{Name: "port-80", ContainerPort: 80},
+											{Name: "port-443", ContainerPort: 443},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"Command and args",
+			true,
+			&object.Service{
+				Name: name,
+				Containers: []object.Container{
+					{
+						Name:    containerName,
+						Image:   image,
+						Command: []string{"nginx"},
+						Args:    []string{"-g", "daemon off;"},
+					},
+				},
+			},
+			[]runtime.Object{
+				&ext_v1beta1.Deployment{
+					ObjectMeta: sMeta,
+					Spec: ext_v1beta1.DeploymentSpec{
+						Strategy: strategy,
+						Template: api_v1.PodTemplateSpec{
+							ObjectMeta: api_v1.ObjectMeta{
+								Labels: map[string]string{
+									"service": name,
+								},
+							},
+							Spec: api_v1.PodSpec{
+								Containers: []api_v1.Container{
+									{
+										Name:    containerName,
+										Image:   image,
+										Command: []string{"nginx"},
+										Args:    []string{"-g", "daemon off;"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	transformer := Transformer{}
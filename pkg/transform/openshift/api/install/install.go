@@ -0,0 +1,14 @@
+// Package install registers the OpenShift API types into api.Scheme
+// so they can be serialized the same way as the Kubernetes objects.
+package install
+
+import (
+	"github.com/redhat-developer/opencompose/pkg/transform/openshift/api/v1"
+	"k8s.io/client-go/pkg/api"
+)
+
+func init() {
+	if err := v1.AddToScheme(api.Scheme); err != nil {
+		panic(err)
+	}
+}
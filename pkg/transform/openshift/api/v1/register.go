@@ -0,0 +1,45 @@
+// Package v1 contains the subset of the OpenShift API types that OpenCompose generates.
+// OpenShift types are not part of k8s.io/client-go and vendoring github.com/openshift/origin
+// would pull in the whole k8s.io/kubernetes tree, so we keep our own copy of the few types
+// we need. Field names and json tags follow the upstream definitions.
+package v1
+
+import (
+	"k8s.io/client-go/pkg/api/unversioned"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+const (
+	AppsGroupName     = "apps.openshift.io"
+	BuildGroupName    = "build.openshift.io"
+	ImageGroupName    = "image.openshift.io"
+	TemplateGroupName = "template.openshift.io"
+)
+
+var (
+	AppsSchemeGroupVersion     = unversioned.GroupVersion{Group: AppsGroupName, Version: "v1"}
+	BuildSchemeGroupVersion    = unversioned.GroupVersion{Group: BuildGroupName, Version: "v1"}
+	ImageSchemeGroupVersion    = unversioned.GroupVersion{Group: ImageGroupName, Version: "v1"}
+	TemplateSchemeGroupVersion = unversioned.GroupVersion{Group: TemplateGroupName, Version: "v1"}
+)
+
+var (
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	AddToScheme   = SchemeBuilder.AddToScheme
+)
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(AppsSchemeGroupVersion,
+		&DeploymentConfig{},
+	)
+	scheme.AddKnownTypes(BuildSchemeGroupVersion,
+		&BuildConfig{},
+	)
+	scheme.AddKnownTypes(ImageSchemeGroupVersion,
+		&ImageStream{},
+	)
+	scheme.AddKnownTypes(TemplateSchemeGroupVersion,
+		&Template{},
+	)
+	return nil
+}
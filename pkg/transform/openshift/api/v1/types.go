@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"k8s.io/client-go/pkg/api/unversioned"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+// DeploymentConfig represents a configuration for a single deployment of an image
+type DeploymentConfig struct {
+	unversioned.TypeMeta `json:",inline"`
+	api_v1.ObjectMeta    `json:"metadata,omitempty"`
+
+	Spec   DeploymentConfigSpec   `json:"spec"`
+	Status DeploymentConfigStatus `json:"status"`
+}
+
+type DeploymentConfigSpec struct {
+	Strategy DeploymentStrategy        `json:"strategy"`
+	Triggers []DeploymentTriggerPolicy `json:"triggers"`
+	Replicas int32                     `json:"replicas"`
+	Test     bool                      `json:"test"`
+	Selector map[string]string         `json:"selector,omitempty"`
+	Template *api_v1.PodTemplateSpec   `json:"template,omitempty"`
+}
+
+type DeploymentConfigStatus struct {
+	LatestVersion int64 `json:"latestVersion,omitempty"`
+}
+
+type DeploymentStrategyType string
+
+const (
+	DeploymentStrategyTypeRecreate DeploymentStrategyType = "Recreate"
+	DeploymentStrategyTypeRolling  DeploymentStrategyType = "Rolling"
+)
+
+type DeploymentStrategy struct {
+	Type DeploymentStrategyType `json:"type,omitempty"`
+}
+
+type DeploymentTriggerType string
+
+const (
+	DeploymentTriggerOnImageChange  DeploymentTriggerType = "ImageChange"
+	DeploymentTriggerOnConfigChange DeploymentTriggerType = "ConfigChange"
+)
+
+type DeploymentTriggerPolicy struct {
+	Type              DeploymentTriggerType               `json:"type,omitempty"`
+	ImageChangeParams *DeploymentTriggerImageChangeParams `json:"imageChangeParams,omitempty"`
+}
+
+type DeploymentTriggerImageChangeParams struct {
+	Automatic      bool                   `json:"automatic,omitempty"`
+	ContainerNames []string               `json:"containerNames,omitempty"`
+	From           api_v1.ObjectReference `json:"from"`
+}
+
+// ImageStream stores a mapping of tags to images
+type ImageStream struct {
+	unversioned.TypeMeta `json:",inline"`
+	api_v1.ObjectMeta    `json:"metadata,omitempty"`
+
+	Spec ImageStreamSpec `json:"spec"`
+}
+
+type ImageStreamSpec struct {
+	LookupPolicy ImageLookupPolicy `json:"lookupPolicy,omitempty"`
+	Tags         []TagReference    `json:"tags,omitempty"`
+}
+
+// ImageLookupPolicy describes how an image stream can be used to override the image references
+// used by pods, builds, and other resources in a namespace
+type ImageLookupPolicy struct {
+	Local bool `json:"local"`
+}
+
+// TagReference specifies optional annotations for images using this tag and an
+// optional reference to an ImageStreamTag, ImageStreamImage, or DockerImage this
+// tag should track
+type TagReference struct {
+	Name         string                  `json:"name"`
+	Annotations  map[string]string       `json:"annotations,omitempty"`
+	From         *api_v1.ObjectReference `json:"from,omitempty"`
+	ImportPolicy TagImportPolicy         `json:"importPolicy,omitempty"`
+}
+
+type TagImportPolicy struct {
+	Insecure  bool `json:"insecure,omitempty"`
+	Scheduled bool `json:"scheduled,omitempty"`
+}
+
+// BuildConfig is a template which can be used to create new builds
+type BuildConfig struct {
+	unversioned.TypeMeta `json:",inline"`
+	api_v1.ObjectMeta    `json:"metadata,omitempty"`
+
+	Spec   BuildConfigSpec   `json:"spec"`
+	Status BuildConfigStatus `json:"status"`
+}
+
+type BuildConfigSpec struct {
+	Triggers []BuildTriggerPolicy `json:"triggers"`
+	Source   BuildSource          `json:"source,omitempty"`
+	Strategy BuildStrategy        `json:"strategy"`
+	Output   BuildOutput          `json:"output,omitempty"`
+}
+
+type BuildConfigStatus struct {
+	LastVersion int64 `json:"lastVersion"`
+}
+
+type BuildTriggerType string
+
+const (
+	ConfigChangeBuildTriggerType BuildTriggerType = "ConfigChange"
+)
+
+type BuildTriggerPolicy struct {
+	Type BuildTriggerType `json:"type"`
+}
+
+type BuildSourceType string
+
+const (
+	BuildSourceGit BuildSourceType = "Git"
+)
+
+type BuildSource struct {
+	Type       BuildSourceType `json:"type,omitempty"`
+	Git        *GitBuildSource `json:"git,omitempty"`
+	ContextDir string          `json:"contextDir,omitempty"`
+}
+
+type GitBuildSource struct {
+	URI string `json:"uri"`
+	Ref string `json:"ref,omitempty"`
+}
+
+type BuildStrategyType string
+
+const (
+	DockerBuildStrategyType BuildStrategyType = "Docker"
+)
+
+type BuildStrategy struct {
+	Type           BuildStrategyType    `json:"type,omitempty"`
+	DockerStrategy *DockerBuildStrategy `json:"dockerStrategy,omitempty"`
+}
+
+type DockerBuildStrategy struct {
+	DockerfilePath string          `json:"dockerfilePath,omitempty"`
+	BuildArgs      []api_v1.EnvVar `json:"buildArgs,omitempty"`
+}
+
+type BuildOutput struct {
+	To *api_v1.ObjectReference `json:"to,omitempty"`
+}
+
+// Template contains the inputs needed to produce a Config
+type Template struct {
+	unversioned.TypeMeta `json:",inline"`
+	api_v1.ObjectMeta    `json:"metadata,omitempty"`
+
+	Message    string                 `json:"message,omitempty"`
+	Objects    []runtime.RawExtension `json:"objects"`
+	Parameters []Parameter            `json:"parameters,omitempty"`
+}
+
+// Parameter defines a name/value variable that is to be processed during
+// the Template to Config transformation
+type Parameter struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+	Value       string `json:"value,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
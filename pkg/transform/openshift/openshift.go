@@ -1,15 +1,438 @@
 package openshift
 
 import (
-	//"github.com/redhat-developer/opencompose/pkg/object"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/redhat-developer/opencompose/pkg/object"
 	"github.com/redhat-developer/opencompose/pkg/transform/kubernetes"
-	//"k8s.io/client-go/pkg/runtime"
+	_ "github.com/redhat-developer/opencompose/pkg/transform/openshift/api/install"
+	os_v1 "github.com/redhat-developer/opencompose/pkg/transform/openshift/api/v1"
+	"github.com/redhat-developer/opencompose/pkg/util"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	ext_v1beta1 "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/pkg/runtime"
+	"k8s.io/client-go/pkg/util/validation"
+)
+
+const (
+	// Pod template annotation that makes OpenShift resolve image names
+	// through ImageStreams with local lookup policy
+	resolveNamesAnnotation = "alpha.image.policy.openshift.io/resolve-names"
 )
 
 type Transformer struct {
 	kubernetes.Transformer
+
+	// Generate DeploymentConfigs and ImageStreams instead of Deployments
+	DeploymentConfig bool
+}
+
+// Splits image reference into repository, ImageStream name and tag.
+// ImageStream is named after the last component of the image repository,
+// so "docker.io/library/nginx:1.11" becomes ImageStream "nginx" with tag "1.11".
+// Images referenced by digest can't be tracked by ImageStreamTag
+// so ok is false for them, as well as for invalid references.
+func imageStreamTag(image string) (repository, name, tag string, ok bool) {
+	ref, err := object.ParseImageRef(image)
+	if err != nil || ref.Digest != "" {
+		return "", "", "", false
+	}
+
+	tag = ref.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+
+	name = ref.Repository[strings.LastIndex(ref.Repository, "/")+1:]
+
+	return ref.Name(), name, tag, true
+}
+
+// Create OpenShift ImageStreams for images used by OpenCompose services
+// ImageStreams are generated for images that are built from source and,
+// when generating DeploymentConfigs, for all other distinct images as well.
+func (t *Transformer) CreateImageStreams(services []object.Service) ([]runtime.Object, error) {
+	result := []runtime.Object{}
+
+	// ImageStream name -> repository it was created for
+	repositories := make(map[string]string)
+	imageStreams := make(map[string]*os_v1.ImageStream)
+	// ImageStreamTag -> image that is built into it
+	builds := make(map[string]string)
+	for _, s := range services {
+		for _, c := range s.Containers {
+			if c.Build == nil && !t.DeploymentConfig {
+				continue
+			}
+
+			repository, name, tag, ok := imageStreamTag(c.Image)
+			if !ok {
+				continue
+			}
+
+			// image repositories allow '_' and more, OpenShift rejects such ImageStream names
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+				return nil, fmt.Errorf("image %q: ImageStream name %q derived from it is not valid: %s", c.Image, name, strings.Join(errs, ", "))
+			}
+
+			if r, exists := repositories[name]; exists && r != repository {
+				return nil, fmt.Errorf("images %q and %q would both be tracked by ImageStream %q", r, repository, name)
+			}
+			repositories[name] = repository
+
+			is, exists := imageStreams[name]
+			if !exists {
+				is = &os_v1.ImageStream{
+					ObjectMeta: api_v1.ObjectMeta{
+						Name: name,
+					},
+				}
+				imageStreams[name] = is
+				result = append(result, is)
+			}
+
+			if c.Build != nil {
+				for _, tr := range is.Spec.Tags {
+					if tr.Name == tag {
+						return nil, fmt.Errorf("image %q: can't be both built and imported from %q", c.Image, tr.From.Name)
+					}
+				}
+
+				builds[fmt.Sprintf("%s:%s", name, tag)] = c.Image
+				// let Deployments reference the built image by ImageStreamTag name
+				is.Spec.LookupPolicy.Local = true
+				// the tag itself is created by the build
+				continue
+			}
+
+			if b, exists := builds[fmt.Sprintf("%s:%s", name, tag)]; exists {
+				return nil, fmt.Errorf("image %q: can't be both built and imported from %q", b, c.Image)
+			}
+
+			tagExists := false
+			for _, tr := range is.Spec.Tags {
+				if tr.Name == tag {
+					tagExists = true
+					break
+				}
+			}
+			if tagExists {
+				continue
+			}
+
+			is.Spec.Tags = append(is.Spec.Tags, os_v1.TagReference{
+				Name: tag,
+				From: &api_v1.ObjectReference{
+					Kind: "DockerImage",
+					Name: c.Image,
+				},
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// Create OpenShift BuildConfigs for containers of OpenCompose service that are built from source
+func (t *Transformer) CreateBuildConfigs(s *object.Service) ([]runtime.Object, error) {
+	result := []runtime.Object{}
+	serviceLabels := map[string]string(s.Labels)
+
+	for _, c := range s.Containers {
+		if c.Build == nil {
+			continue
+		}
+
+		_, name, tag, ok := imageStreamTag(c.Image)
+		if !ok {
+			return nil, fmt.Errorf("container %q: image %q can't be used as build output", c.Name, c.Image)
+		}
+
+		bc := &os_v1.BuildConfig{
+			ObjectMeta: api_v1.ObjectMeta{
+				Name: name,
+				Labels: *util.MergeMaps(
+					// The map containing `"service": s.Name` should always be
+					// passed later to avoid being overridden by util.MergeMaps()
+					&serviceLabels,
+					&map[string]string{
+						"service": s.Name,
+					},
+				),
+			},
+			Spec: os_v1.BuildConfigSpec{
+				Triggers: []os_v1.BuildTriggerPolicy{
+					{
+						Type: os_v1.ConfigChangeBuildTriggerType,
+					},
+				},
+				Source: os_v1.BuildSource{
+					Type: os_v1.BuildSourceGit,
+					Git: &os_v1.GitBuildSource{
+						URI: c.Build.Git.URL,
+						Ref: c.Build.Git.Ref,
+					},
+					ContextDir: c.Build.Git.ContextDir,
+				},
+				Strategy: os_v1.BuildStrategy{
+					Type: os_v1.DockerBuildStrategyType,
+					DockerStrategy: &os_v1.DockerBuildStrategy{
+						DockerfilePath: c.Build.Dockerfile,
+					},
+				},
+				Output: os_v1.BuildOutput{
+					To: &api_v1.ObjectReference{
+						Kind: "ImageStreamTag",
+						Name: fmt.Sprintf("%s:%s", name, tag),
+					},
+				},
+			},
+		}
+
+		for _, a := range c.Build.Args {
+			bc.Spec.Strategy.DockerStrategy.BuildArgs = append(bc.Spec.Strategy.DockerStrategy.BuildArgs, api_v1.EnvVar{
+				Name:  a.Key,
+				Value: a.Value,
+			})
+		}
+
+		result = append(result, bc)
+	}
+
+	return result, nil
+}
+
+// Create k8s deployments for OpenCompose service
+// Containers with images built from source reference the ImageStreamTag the build
+// pushes to, the Deployment resolves it through ImageStream with local lookup policy.
+func (t *Transformer) CreateDeployments(s *object.Service) ([]runtime.Object, error) {
+	result, err := t.Transformer.CreateDeployments(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// container name -> ImageStreamTag its image is built into
+	builtImages := make(map[string]string)
+	for _, c := range s.Containers {
+		if c.Build == nil {
+			continue
+		}
+		_, name, tag, ok := imageStreamTag(c.Image)
+		if !ok {
+			return nil, fmt.Errorf("container %q: image %q can't be used as build output", c.Name, c.Image)
+		}
+		builtImages[c.Name] = fmt.Sprintf("%s:%s", name, tag)
+	}
+	if len(builtImages) == 0 {
+		return result, nil
+	}
+
+	for _, o := range result {
+		d, ok := o.(*ext_v1beta1.Deployment)
+		if !ok {
+			continue
+		}
+
+		if d.Spec.Template.Annotations == nil {
+			d.Spec.Template.Annotations = make(map[string]string)
+		}
+		d.Spec.Template.Annotations[resolveNamesAnnotation] = "*"
+
+		for i := range d.Spec.Template.Spec.Containers {
+			if image, ok := builtImages[d.Spec.Template.Spec.Containers[i].Name]; ok {
+				d.Spec.Template.Spec.Containers[i].Image = image
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Create OpenShift DeploymentConfigs for OpenCompose service
+func (t *Transformer) CreateDeploymentConfigs(s *object.Service) ([]runtime.Object, error) {
+	result := []runtime.Object{}
+	serviceLabels := map[string]string(s.Labels)
+
+	pt, err := t.CreatePodTemplate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := &os_v1.DeploymentConfig{
+		ObjectMeta: api_v1.ObjectMeta{
+			Name: s.Name,
+			Labels: *util.MergeMaps(
+				// The map containing `"service": s.Name` should always be
+				// passed later to avoid being overridden by util.MergeMaps()
+				&serviceLabels,
+				&map[string]string{
+					"service": s.Name,
+				},
+			),
+		},
+		Spec: os_v1.DeploymentConfigSpec{
+			Strategy: os_v1.DeploymentStrategy{
+				// TODO: make it configurable
+				Type: os_v1.DeploymentStrategyTypeRolling,
+			},
+			Triggers: []os_v1.DeploymentTriggerPolicy{
+				{
+					Type: os_v1.DeploymentTriggerOnConfigChange,
+				},
+			},
+			// OpenShift defaults to 1 replica as well but the field isn't optional
+			Replicas: 1,
+			Selector: map[string]string{
+				"service": s.Name,
+			},
+			Template: pt,
+		},
+	}
+
+	if s.Replicas != nil {
+		dc.Spec.Replicas = *s.Replicas
+	}
+
+	// roll out new deployment every time the ImageStreamTag of a container changes
+	for _, c := range s.Containers {
+		_, name, tag, ok := imageStreamTag(c.Image)
+		if !ok {
+			continue
+		}
+
+		dc.Spec.Triggers = append(dc.Spec.Triggers, os_v1.DeploymentTriggerPolicy{
+			Type: os_v1.DeploymentTriggerOnImageChange,
+			ImageChangeParams: &os_v1.DeploymentTriggerImageChangeParams{
+				Automatic:      true,
+				ContainerNames: []string{c.Name},
+				From: api_v1.ObjectReference{
+					Kind: "ImageStreamTag",
+					Name: fmt.Sprintf("%s:%s", name, tag),
+				},
+			},
+		})
+	}
+
+	result = append(result, dc)
+
+	return result, nil
+}
+
+// Merges BuildConfigs of all services.
+// Containers built into the same ImageStreamTag share one BuildConfig, which fails if they are built
+// differently. BuildConfigs are named after their ImageStream, when more tags of the ImageStream
+// are built the tag is appended to the name.
+func mergeBuildConfigs(buildConfigs []*os_v1.BuildConfig) ([]runtime.Object, error) {
+	result := []runtime.Object{}
+
+	// ImageStreamTag -> BuildConfig building it
+	outputs := make(map[string]*os_v1.BuildConfig)
+	// BuildConfig name -> number of ImageStreamTags built by BuildConfigs with that name
+	tags := make(map[string]int)
+	merged := []*os_v1.BuildConfig{}
+	for _, bc := range buildConfigs {
+		output := bc.Spec.Output.To.Name
+		if b, exists := outputs[output]; exists {
+			if !reflect.DeepEqual(b.Spec, bc.Spec) {
+				return nil, fmt.Errorf("ImageStreamTag %q is built by services %q and %q from different sources", output, b.Labels["service"], bc.Labels["service"])
+			}
+			continue
+		}
+		outputs[output] = bc
+		tags[bc.Name]++
+		merged = append(merged, bc)
+	}
+
+	for _, bc := range merged {
+		if tags[bc.Name] > 1 {
+			tag := bc.Spec.Output.To.Name[strings.LastIndex(bc.Spec.Output.To.Name, ":")+1:]
+			// tags may contain '_' and capitals which aren't valid in names
+			bc.Name = fmt.Sprintf("%s-%s", bc.Name, strings.ToLower(strings.Replace(tag, "_", "-", -1)))
+		}
+		result = append(result, bc)
+	}
+
+	return result, nil
+}
+
+func (t *Transformer) TransformServices(services []object.Service) ([]runtime.Object, error) {
+	result := []runtime.Object{}
+	buildConfigs := []*os_v1.BuildConfig{}
+
+	for _, service := range services {
+		// create k8s services
+		objects, err := t.CreateServices(&service)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate services: %s", err)
+		}
+		result = append(result, objects...)
+
+		// create k8s ingresses
+		objects, err = t.CreateIngresses(&service)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate ingresses: %s", err)
+		}
+		result = append(result, objects...)
+
+		if t.DeploymentConfig {
+			// create deploymentconfigs
+			objects, err = t.CreateDeploymentConfigs(&service)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate deploymentconfigs: %s", err)
+			}
+		} else {
+			// create k8s deployments
+			objects, err = t.CreateDeployments(&service)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate deployments: %s", err)
+			}
+		}
+		result = append(result, objects...)
+
+		// create buildconfigs
+		objects, err = t.CreateBuildConfigs(&service)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate buildconfigs: %s", err)
+		}
+		for _, o := range objects {
+			buildConfigs = append(buildConfigs, o.(*os_v1.BuildConfig))
+		}
+	}
+
+	objects, err := mergeBuildConfigs(buildConfigs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate buildconfigs: %s", err)
+	}
+	result = append(result, objects...)
+
+	// create imagestreams
+	objects, err = t.CreateImageStreams(services)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate imagestreams: %s", err)
+	}
+	result = append(result, objects...)
+
+	return result, nil
 }
 
-//func (t *Transformer) Transform(o *object.OpenCompose) ([]runtime.Object, error) {
-//	return nil, nil
-//}
+func (t *Transformer) Transform(o *object.OpenCompose) ([]runtime.Object, error) {
+	result := []runtime.Object{}
+
+	// services
+	serviceObjects, err := t.TransformServices(o.Services)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform services: %s", err)
+	}
+	result = append(result, serviceObjects...)
+
+	// volumes
+	volumeObjects, err := t.TransformVolumes(o.Volumes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform volumes: %s", err)
+	}
+	result = append(result, volumeObjects...)
+
+	return result, nil
+}
@@ -0,0 +1,666 @@
+package openshift
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	os_v1 "github.com/redhat-developer/opencompose/pkg/transform/openshift/api/v1"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	ext_v1beta1 "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+func TestImageStreamTag(t *testing.T) {
+	tests := []struct {
+		Image      string
+		Ok         bool
+		Repository string
+		Name       string
+		Tag        string
+	}{
+		{"nginx", true, "nginx", "nginx", "latest"},
+		{"nginx:1.11", true, "nginx", "nginx", "1.11"},
+		{"docker.io/library/nginx:1.11", true, "docker.io/library/nginx", "nginx", "1.11"},
+		{"localhost:5000/foo/bar", true, "localhost:5000/foo/bar", "bar", "latest"},
+		{"localhost:5000/foo/bar:v2", true, "localhost:5000/foo/bar", "bar", "v2"},
+		{"nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", false, "", "", ""},
+		{"Nginx", false, "", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Image, func(t *testing.T) {
+			repository, name, tag, ok := imageStreamTag(test.Image)
+			if ok != test.Ok || repository != test.Repository || name != test.Name || tag != test.Tag {
+				t.Errorf("Expected (%q, %q, %q, %v), got (%q, %q, %q, %v)",
+					test.Repository, test.Name, test.Tag, test.Ok, repository, name, tag, ok)
+			}
+		})
+	}
+}
+
+func TestTransformer_CreateImageStreams(t *testing.T) {
+	build := &object.Build{
+		Git: object.GitSource{
+			URL: "https://github.com/foo/bar.git",
+		},
+	}
+
+	tests := []struct {
+		Name             string
+		Succeed          bool
+		DeploymentConfig bool
+		Services         []object.Service
+		ImageStreams     []runtime.Object
+	}{
+		{
+			"Distinct images across services",
+			true,
+			true,
+			[]object.Service{
+				{
+					Name: "web",
+					Containers: []object.Container{
+						{Name: "nginx", Image: "nginx:1.11"},
+						{Name: "sidecar", Image: "foo/sidecar"},
+					},
+				},
+				{
+					Name: "static",
+					Containers: []object.Container{
+						{Name: "nginx", Image: "nginx:1.11"},
+						{Name: "nginx-old", Image: "nginx:1.9"},
+					},
+				},
+			},
+			[]runtime.Object{
+				&os_v1.ImageStream{
+					ObjectMeta: api_v1.ObjectMeta{Name: "nginx"},
+					Spec: os_v1.ImageStreamSpec{
+						Tags: []os_v1.TagReference{
+							{
+								Name: "1.11",
+								From: &api_v1.ObjectReference{Kind: "DockerImage", Name: "nginx:1.11"},
+							},
+							{
+								Name: "1.9",
+								From: &api_v1.ObjectReference{Kind: "DockerImage", Name: "nginx:1.9"},
+							},
+						},
+					},
+				},
+				&os_v1.ImageStream{
+					ObjectMeta: api_v1.ObjectMeta{Name: "sidecar"},
+					Spec: os_v1.ImageStreamSpec{
+						Tags: []os_v1.TagReference{
+							{
+								Name: "latest",
+								From: &api_v1.ObjectReference{Kind: "DockerImage", Name: "foo/sidecar"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"Different repositories with the same name",
+			false,
+			true,
+			[]object.Service{
+				{
+					Name: "web",
+					Containers: []object.Container{
+						{Name: "one", Image: "foo/nginx"},
+						{Name: "two", Image: "bar/nginx"},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"Only built images without DeploymentConfigs",
+			true,
+			false,
+			[]object.Service{
+				{
+					Name: "web",
+					Containers: []object.Container{
+						{Name: "nginx", Image: "nginx:1.11"},
+						{Name: "app", Image: "app:v1", Build: build},
+					},
+				},
+			},
+			[]runtime.Object{
+				&os_v1.ImageStream{
+					ObjectMeta: api_v1.ObjectMeta{Name: "app"},
+					Spec: os_v1.ImageStreamSpec{
+						LookupPolicy: os_v1.ImageLookupPolicy{Local: true},
+					},
+				},
+			},
+		},
+		{
+			"Image both built and imported",
+			false,
+			true,
+			[]object.Service{
+				{
+					Name: "web",
+					Containers: []object.Container{
+						{Name: "one", Image: "app:v1"},
+						{Name: "two", Image: "app:v1", Build: build},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"Image built and imported by different services",
+			false,
+			true,
+			[]object.Service{
+				{
+					Name: "web",
+					Containers: []object.Container{
+						{Name: "app", Image: "app:v1", Build: build},
+					},
+				},
+				{
+					Name: "worker",
+					Containers: []object.Container{
+						{Name: "app", Image: "app:v1"},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"Two builds into the same ImageStream",
+			true,
+			false,
+			[]object.Service{
+				{
+					Name: "web",
+					Containers: []object.Container{
+						{Name: "one", Image: "app:v1", Build: build},
+						{Name: "two", Image: "app:v2", Build: build},
+					},
+				},
+			},
+			[]runtime.Object{
+				&os_v1.ImageStream{
+					ObjectMeta: api_v1.ObjectMeta{Name: "app"},
+					Spec: os_v1.ImageStreamSpec{
+						LookupPolicy: os_v1.ImageLookupPolicy{Local: true},
+					},
+				},
+			},
+		},
+		{
+			"Built tag and imported tag of the same ImageStream",
+			true,
+			true,
+			[]object.Service{
+				{
+					Name: "web",
+					Containers: []object.Container{
+						{Name: "app", Image: "app:v2", Build: build},
+					},
+				},
+				{
+					Name: "worker",
+					Containers: []object.Container{
+						{Name: "app", Image: "app:v1"},
+						{Name: "build", Image: "app:v2", Build: build},
+					},
+				},
+			},
+			[]runtime.Object{
+				&os_v1.ImageStream{
+					ObjectMeta: api_v1.ObjectMeta{Name: "app"},
+					Spec: os_v1.ImageStreamSpec{
+						LookupPolicy: os_v1.ImageLookupPolicy{Local: true},
+						Tags: []os_v1.TagReference{
+							{
+								Name: "v1",
+								From: &api_v1.ObjectReference{Kind: "DockerImage", Name: "app:v1"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"Invalid ImageStream name",
+			false,
+			true,
+			[]object.Service{
+				{
+					Name: "web",
+					Containers: []object.Container{
+						{Name: "app", Image: "foo/my_app:v1"},
+					},
+				},
+			},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transformer := Transformer{DeploymentConfig: test.DeploymentConfig}
+			is, err := transformer.CreateImageStreams(test.Services)
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to create imagestreams from %#v\nErr: %s", test.Services, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, services: %#v\nConverted ImageStreams: %#v", test.Services, spew.Sprint(is))
+				return
+			}
+
+			if !reflect.DeepEqual(is, test.ImageStreams) {
+				t.Errorf("Expected: %#v\nGot: %#v\n", spew.Sprint(test.ImageStreams), spew.Sprint(is))
+				return
+			}
+		})
+	}
+}
+
+func TestTransformer_CreateDeploymentConfigs(t *testing.T) {
+	name := "test"
+	containerName := "test"
+	image := "docker.io/test:v1"
+	labels := map[string]string{
+		"service": name,
+	}
+	meta := api_v1.ObjectMeta{
+		Name:   name,
+		Labels: labels,
+	}
+	template := &api_v1.PodTemplateSpec{
+		ObjectMeta: api_v1.ObjectMeta{
+			Labels: labels,
+		},
+		Spec: api_v1.PodSpec{
+			Containers: []api_v1.Container{
+				{
+					Name:  containerName,
+					Image: image,
+				},
+			},
+		},
+	}
+	triggers := []os_v1.DeploymentTriggerPolicy{
+		{
+			Type: os_v1.DeploymentTriggerOnConfigChange,
+		},
+		{
+			Type: os_v1.DeploymentTriggerOnImageChange,
+			ImageChangeParams: &os_v1.DeploymentTriggerImageChangeParams{
+				Automatic:      true,
+				ContainerNames: []string{containerName},
+				From: api_v1.ObjectReference{
+					Kind: "ImageStreamTag",
+					Name: "test:v1",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name              string
+		Succeed           bool
+		Service           *object.Service
+		DeploymentConfigs []runtime.Object
+	}{
+		{
+			"When no replica field given",
+			true,
+			&object.Service{
+				Name: name,
+				Containers: []object.Container{
+					{
+						Name:  containerName,
+						Image: image,
+					},
+				},
+			},
+			[]runtime.Object{
+				&os_v1.DeploymentConfig{
+					ObjectMeta: meta,
+					Spec: os_v1.DeploymentConfigSpec{
+						Strategy: os_v1.DeploymentStrategy{Type: os_v1.DeploymentStrategyTypeRolling},
+						Triggers: triggers,
+						Replicas: 1,
+						Selector: labels,
+						Template: template,
+					},
+				},
+			},
+		},
+		{
+			"When valid replica value given",
+			true,
+			&object.Service{
+				Name:     name,
+				Replicas: goutil.Int32Addr(3),
+				Containers: []object.Container{
+					{
+						Name:  containerName,
+						Image: image,
+					},
+				},
+			},
+			[]runtime.Object{
+				&os_v1.DeploymentConfig{
+					ObjectMeta: meta,
+					Spec: os_v1.DeploymentConfigSpec{
+						Strategy: os_v1.DeploymentStrategy{Type: os_v1.DeploymentStrategyTypeRolling},
+						Triggers: triggers,
+						Replicas: 3,
+						Selector: labels,
+						Template: template,
+					},
+				},
+			},
+		},
+	}
+
+	transformer := Transformer{}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			dc, err := transformer.CreateDeploymentConfigs(test.Service)
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to create deploymentconfig from %#v\nErr: %s", test.Service, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, service: %#v\nConverted DeploymentConfig: %#v", test.Service, spew.Sprint(dc))
+				return
+			}
+
+			if !reflect.DeepEqual(dc, test.DeploymentConfigs) {
+				t.Errorf("Expected: %#v\nGot: %#v\n", spew.Sprint(test.DeploymentConfigs), spew.Sprint(dc))
+				return
+			}
+		})
+	}
+}
+
+func TestTransformer_CreateBuildConfigs(t *testing.T) {
+	name := "test"
+	meta := api_v1.ObjectMeta{
+		Name: "app",
+		Labels: map[string]string{
+			"service": name,
+		},
+	}
+
+	tests := []struct {
+		Name         string
+		Succeed      bool
+		Service      *object.Service
+		BuildConfigs []runtime.Object
+	}{
+		{
+			"Container without build",
+			true,
+			&object.Service{
+				Name: name,
+				Containers: []object.Container{
+					{Name: "nginx", Image: "nginx"},
+				},
+			},
+			[]runtime.Object{},
+		},
+		{
+			"Container with build",
+			true,
+			&object.Service{
+				Name: name,
+				Containers: []object.Container{
+					{
+						Name:  "app",
+						Image: "app:v1",
+						Build: &object.Build{
+							Git: object.GitSource{
+								URL:        "https://github.com/foo/bar.git",
+								Ref:        "master",
+								ContextDir: "app",
+							},
+							Dockerfile: "Dockerfile.prod",
+							Args: []object.EnvVariable{
+								{Key: "FOO", Value: "bar"},
+							},
+						},
+					},
+				},
+			},
+			[]runtime.Object{
+				&os_v1.BuildConfig{
+					ObjectMeta: meta,
+					Spec: os_v1.BuildConfigSpec{
+						Triggers: []os_v1.BuildTriggerPolicy{
+							{Type: os_v1.ConfigChangeBuildTriggerType},
+						},
+						Source: os_v1.BuildSource{
+							Type: os_v1.BuildSourceGit,
+							Git: &os_v1.GitBuildSource{
+								URI: "https://github.com/foo/bar.git",
+								Ref: "master",
+							},
+							ContextDir: "app",
+						},
+						Strategy: os_v1.BuildStrategy{
+							Type: os_v1.DockerBuildStrategyType,
+							DockerStrategy: &os_v1.DockerBuildStrategy{
+								DockerfilePath: "Dockerfile.prod",
+								BuildArgs: []api_v1.EnvVar{
+									{Name: "FOO", Value: "bar"},
+								},
+							},
+						},
+						Output: os_v1.BuildOutput{
+							To: &api_v1.ObjectReference{
+								Kind: "ImageStreamTag",
+								Name: "app:v1",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	transformer := Transformer{}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			bc, err := transformer.CreateBuildConfigs(test.Service)
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to create buildconfig from %#v\nErr: %s", test.Service, err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, service: %#v\nConverted BuildConfig: %#v", test.Service, spew.Sprint(bc))
+				return
+			}
+
+			if !reflect.DeepEqual(bc, test.BuildConfigs) {
+				t.Errorf("Expected: %#v\nGot: %#v\n", spew.Sprint(test.BuildConfigs), spew.Sprint(bc))
+				return
+			}
+		})
+	}
+}
+
+func TestTransformer_CreateDeployments(t *testing.T) {
+	build := &object.Build{
+		Git: object.GitSource{
+			URL: "https://github.com/foo/bar.git",
+		},
+	}
+
+	tests := []struct {
+		Name        string
+		Service     *object.Service
+		Images      map[string]string
+		Annotations map[string]string
+	}{
+		{
+			"Without build",
+			&object.Service{
+				Name: "web",
+				Containers: []object.Container{
+					{Name: "nginx", Image: "nginx:1.11"},
+				},
+			},
+			map[string]string{
+				"nginx": "nginx:1.11",
+			},
+			nil,
+		},
+		{
+			"Registry qualified image built from source",
+			&object.Service{
+				Name: "web",
+				Containers: []object.Container{
+					{Name: "app", Image: "registry.example.com/team/app:v1", Build: build},
+					{Name: "nginx", Image: "nginx:1.11"},
+				},
+			},
+			map[string]string{
+				"app":   "app:v1",
+				"nginx": "nginx:1.11",
+			},
+			map[string]string{
+				resolveNamesAnnotation: "*",
+			},
+		},
+	}
+
+	transformer := Transformer{}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			objects, err := transformer.CreateDeployments(test.Service)
+			if err != nil {
+				t.Fatalf("Failed to create deployments from %#v\nErr: %s", test.Service, err)
+			}
+			if len(objects) != 1 {
+				t.Fatalf("Expected 1 deployment, got: %s", spew.Sprint(objects))
+			}
+
+			d := objects[0].(*ext_v1beta1.Deployment)
+			images := make(map[string]string)
+			for _, c := range d.Spec.Template.Spec.Containers {
+				images[c.Name] = c.Image
+			}
+			if !reflect.DeepEqual(images, test.Images) {
+				t.Errorf("Expected images: %#v\nGot: %#v", test.Images, images)
+			}
+			if !reflect.DeepEqual(d.Spec.Template.Annotations, test.Annotations) {
+				t.Errorf("Expected annotations: %#v\nGot: %#v", test.Annotations, d.Spec.Template.Annotations)
+			}
+		})
+	}
+}
+
+func TestMergeBuildConfigs(t *testing.T) {
+	buildConfig := func(service, output, url string) *os_v1.BuildConfig {
+		return &os_v1.BuildConfig{
+			ObjectMeta: api_v1.ObjectMeta{
+				Name:   output[:strings.Index(output, ":")],
+				Labels: map[string]string{"service": service},
+			},
+			Spec: os_v1.BuildConfigSpec{
+				Source: os_v1.BuildSource{
+					Git: &os_v1.GitBuildSource{URI: url},
+				},
+				Output: os_v1.BuildOutput{
+					To: &api_v1.ObjectReference{Kind: "ImageStreamTag", Name: output},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		Name         string
+		Succeed      bool
+		BuildConfigs []*os_v1.BuildConfig
+		Names        []string
+	}{
+		{
+			"Distinct ImageStreams",
+			true,
+			[]*os_v1.BuildConfig{
+				buildConfig("web", "web:v1", "https://github.com/foo/web.git"),
+				buildConfig("worker", "worker:v1", "https://github.com/foo/worker.git"),
+			},
+			[]string{"web", "worker"},
+		},
+		{
+			"Same build shared by services",
+			true,
+			[]*os_v1.BuildConfig{
+				buildConfig("web", "app:v1", "https://github.com/foo/app.git"),
+				buildConfig("worker", "app:v1", "https://github.com/foo/app.git"),
+			},
+			[]string{"app"},
+		},
+		{
+			"Tags of the same ImageStream",
+			true,
+			[]*os_v1.BuildConfig{
+				buildConfig("web", "app:v1", "https://github.com/foo/app.git"),
+				buildConfig("worker", "app:Next_1", "https://github.com/foo/app.git"),
+			},
+			[]string{"app-v1", "app-next-1"},
+		},
+		{
+			"Same tag built from different sources",
+			false,
+			[]*os_v1.BuildConfig{
+				buildConfig("web", "app:v1", "https://github.com/foo/app.git"),
+				buildConfig("worker", "app:v1", "https://github.com/foo/other.git"),
+			},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			objects, err := mergeBuildConfigs(test.BuildConfigs)
+			if err != nil {
+				if test.Succeed {
+					t.Errorf("Failed to merge buildconfigs %s\nErr: %s", spew.Sprint(test.BuildConfigs), err)
+				}
+				return
+			}
+
+			if !test.Succeed {
+				t.Errorf("Expected failure, but succeeded, buildconfigs: %s", spew.Sprint(objects))
+				return
+			}
+
+			names := []string{}
+			for _, o := range objects {
+				names = append(names, o.(*os_v1.BuildConfig).Name)
+			}
+			if !reflect.DeepEqual(names, test.Names) {
+				t.Errorf("Expected: %#v\nGot: %#v\n", test.Names, names)
+			}
+		})
+	}
+}
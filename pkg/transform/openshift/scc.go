@@ -0,0 +1,154 @@
+package openshift
+
+import (
+	"fmt"
+
+	os_v1 "github.com/redhat-developer/opencompose/pkg/transform/openshift/api/v1"
+	pkgutil "github.com/redhat-developer/opencompose/pkg/util"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	ext_v1beta1 "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+// Code of the violations when they are reported as diagnostics
+const ErrorCode_RestrictedSCC pkgutil.ErrorCode = "OC3001"
+
+type SCCSeverity int
+
+const (
+	// Pod is admitted but most likely won't work, e.g. it can't bind its port
+	SCCSeverity_Warning SCCSeverity = iota
+	// Pod is rejected by the admission
+	SCCSeverity_Error
+)
+
+func (s SCCSeverity) String() string {
+	switch s {
+	case SCCSeverity_Warning:
+		return "WARNING"
+	case SCCSeverity_Error:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("SCCSeverity(%d)", int(s))
+	}
+}
+
+// SCCViolation describes a setting in generated pod spec that
+// the 'restricted' SecurityContextConstraints rejects or breaks
+type SCCViolation struct {
+	Severity SCCSeverity
+	Kind     string
+	Name     string
+	// Path of the offending field within the object
+	Path    string
+	Message string
+}
+
+func (v SCCViolation) String() string {
+	return fmt.Sprintf("%s: %s %q: %s: %s", v.Severity, v.Kind, v.Name, v.Path, v.Message)
+}
+
+// Checks pod spec against the 'restricted' SecurityContextConstraints which is what
+// OpenShift uses for pods of regular users. The restricted SCC runs containers with
+// an arbitrary non-root UID from the namespace range, drops all host access and
+// doesn't allow adding capabilities.
+// The transformers set only the container ports so far, the checks of host access, users,
+// privileges, capabilities and hostPath volumes guard the fields they may set in the future
+// as well as pod specs that don't come from the transformers.
+func checkRestrictedSCCPodSpec(spec *api_v1.PodSpec, path string) []SCCViolation {
+	var violations []SCCViolation
+	errorf := func(path string, format string, args ...interface{}) {
+		violations = append(violations, SCCViolation{Severity: SCCSeverity_Error, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	warningf := func(path string, format string, args ...interface{}) {
+		violations = append(violations, SCCViolation{Severity: SCCSeverity_Warning, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if spec.HostNetwork {
+		errorf(path+".hostNetwork", "host network is not allowed")
+	}
+	if spec.HostPID {
+		errorf(path+".hostPID", "host PID namespace is not allowed")
+	}
+	if spec.HostIPC {
+		errorf(path+".hostIPC", "host IPC namespace is not allowed")
+	}
+
+	if sc := spec.SecurityContext; sc != nil && sc.RunAsUser != nil {
+		errorf(path+".securityContext.runAsUser", "fixed user %d is not allowed, UID is assigned from the namespace range", *sc.RunAsUser)
+	}
+
+	for i, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			errorf(fmt.Sprintf("%s.volumes[%d].hostPath", path, i), "volume %q: hostPath volumes are not allowed", volume.Name)
+		}
+	}
+
+	checkContainer := func(c api_v1.Container, cpath string) {
+		if sc := c.SecurityContext; sc != nil {
+			if sc.Privileged != nil && *sc.Privileged {
+				errorf(cpath+".securityContext.privileged", "container %q: privileged containers are not allowed", c.Name)
+			}
+			if sc.RunAsUser != nil {
+				errorf(cpath+".securityContext.runAsUser", "container %q: fixed user %d is not allowed, UID is assigned from the namespace range", c.Name, *sc.RunAsUser)
+			}
+			if sc.Capabilities != nil && len(sc.Capabilities.Add) > 0 {
+				errorf(cpath+".securityContext.capabilities.add", "container %q: adding capabilities %v is not allowed", c.Name, sc.Capabilities.Add)
+			}
+		}
+
+		for j, p := range c.Ports {
+			ppath := fmt.Sprintf("%s.ports[%d]", cpath, j)
+			if p.HostPort != 0 {
+				errorf(ppath+".hostPort", "container %q: host port %d is not allowed", c.Name, p.HostPort)
+			}
+			if p.ContainerPort < 1024 {
+				warningf(ppath+".containerPort", "container %q: port %d is privileged, container runs as non-root user and most likely won't be able to bind it", c.Name, p.ContainerPort)
+			}
+		}
+	}
+
+	// init containers run under the same SCC, they would be a way around the checks otherwise
+	for i, c := range spec.InitContainers {
+		checkContainer(c, fmt.Sprintf("%s.initContainers[%d]", path, i))
+	}
+	for i, c := range spec.Containers {
+		checkContainer(c, fmt.Sprintf("%s.containers[%d]", path, i))
+	}
+
+	return violations
+}
+
+// Checks pod specs in generated objects for settings
+// that the 'restricted' SecurityContextConstraints rejects or breaks
+func CheckRestrictedSCC(objects []runtime.Object) []SCCViolation {
+	var violations []SCCViolation
+
+	for _, o := range objects {
+		var kind, name string
+		var podViolations []SCCViolation
+		switch t := o.(type) {
+		case *ext_v1beta1.Deployment:
+			kind, name = "Deployment", t.Name
+			podViolations = checkRestrictedSCCPodSpec(&t.Spec.Template.Spec, "spec.template.spec")
+		case *os_v1.DeploymentConfig:
+			kind, name = "DeploymentConfig", t.Name
+			if t.Spec.Template != nil {
+				podViolations = checkRestrictedSCCPodSpec(&t.Spec.Template.Spec, "spec.template.spec")
+			}
+		case *api_v1.Pod:
+			kind, name = "Pod", t.Name
+			podViolations = checkRestrictedSCCPodSpec(&t.Spec, "spec")
+		default:
+			continue
+		}
+
+		for _, v := range podViolations {
+			v.Kind = kind
+			v.Name = name
+			violations = append(violations, v)
+		}
+	}
+
+	return violations
+}
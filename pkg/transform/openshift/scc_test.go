@@ -0,0 +1,177 @@
+package openshift
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	os_v1 "github.com/redhat-developer/opencompose/pkg/transform/openshift/api/v1"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	ext_v1beta1 "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+func TestCheckRestrictedSCC(t *testing.T) {
+	uid := int64(0)
+	deployment := func(spec api_v1.PodSpec) *ext_v1beta1.Deployment {
+		return &ext_v1beta1.Deployment{
+			ObjectMeta: api_v1.ObjectMeta{Name: "test"},
+			Spec: ext_v1beta1.DeploymentSpec{
+				Template: api_v1.PodTemplateSpec{Spec: spec},
+			},
+		}
+	}
+
+	tests := []struct {
+		Name       string
+		Objects    []runtime.Object
+		Violations []SCCViolation
+	}{
+		{
+			"Compatible pod spec",
+			[]runtime.Object{
+				deployment(api_v1.PodSpec{
+					Containers: []api_v1.Container{
+						{Name: "web", Ports: []api_v1.ContainerPort{{ContainerPort: 8080}}},
+					},
+				}),
+				&api_v1.Service{ObjectMeta: api_v1.ObjectMeta{Name: "test"}},
+			},
+			nil,
+		},
+		{
+			"Privileged port",
+			[]runtime.Object{
+				deployment(api_v1.PodSpec{
+					Containers: []api_v1.Container{
+						{Name: "web", Ports: []api_v1.ContainerPort{{ContainerPort: 80}}},
+					},
+				}),
+			},
+			[]SCCViolation{
+				{SCCSeverity_Warning, "Deployment", "test", "spec.template.spec.containers[0].ports[0].containerPort", `container "web": port 80 is privileged, container runs as non-root user and most likely won't be able to bind it`},
+			},
+		},
+		{
+			"Host access and fixed user",
+			[]runtime.Object{
+				deployment(api_v1.PodSpec{
+					HostNetwork:     true,
+					SecurityContext: &api_v1.PodSecurityContext{RunAsUser: &uid},
+					Volumes: []api_v1.Volume{
+						{Name: "data", VolumeSource: api_v1.VolumeSource{HostPath: &api_v1.HostPathVolumeSource{Path: "/data"}}},
+					},
+				}),
+			},
+			[]SCCViolation{
+				{SCCSeverity_Error, "Deployment", "test", "spec.template.spec.hostNetwork", "host network is not allowed"},
+				{SCCSeverity_Error, "Deployment", "test", "spec.template.spec.securityContext.runAsUser", "fixed user 0 is not allowed, UID is assigned from the namespace range"},
+				{SCCSeverity_Error, "Deployment", "test", "spec.template.spec.volumes[0].hostPath", `volume "data": hostPath volumes are not allowed`},
+			},
+		},
+		{
+			"Privileged container in DeploymentConfig",
+			[]runtime.Object{
+				&os_v1.DeploymentConfig{
+					ObjectMeta: api_v1.ObjectMeta{Name: "test"},
+					Spec: os_v1.DeploymentConfigSpec{
+						Template: &api_v1.PodTemplateSpec{
+							Spec: api_v1.PodSpec{
+								Containers: []api_v1.Container{
+									{
+										Name: "web",
+										SecurityContext: &api_v1.SecurityContext{
+											Privileged: goutil.BoolAddr(true),
+											Capabilities: &api_v1.Capabilities{
+												Add: []api_v1.Capability{"NET_ADMIN"},
+											},
+										},
+										Ports: []api_v1.ContainerPort{{ContainerPort: 8080, HostPort: 8080}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			[]SCCViolation{
+				{SCCSeverity_Error, "DeploymentConfig", "test", "spec.template.spec.containers[0].securityContext.privileged", `container "web": privileged containers are not allowed`},
+				{SCCSeverity_Error, "DeploymentConfig", "test", "spec.template.spec.containers[0].securityContext.capabilities.add", `container "web": adding capabilities [NET_ADMIN] is not allowed`},
+				{SCCSeverity_Error, "DeploymentConfig", "test", "spec.template.spec.containers[0].ports[0].hostPort", `container "web": host port 8080 is not allowed`},
+			},
+		},
+		{
+			"Privileged init container",
+			[]runtime.Object{
+				deployment(api_v1.PodSpec{
+					InitContainers: []api_v1.Container{
+						{Name: "setup", SecurityContext: &api_v1.SecurityContext{RunAsUser: &uid}},
+					},
+					Containers: []api_v1.Container{
+						{Name: "web", SecurityContext: &api_v1.SecurityContext{Privileged: goutil.BoolAddr(true)}},
+					},
+				}),
+			},
+			[]SCCViolation{
+				{SCCSeverity_Error, "Deployment", "test", "spec.template.spec.initContainers[0].securityContext.runAsUser", `container "setup": fixed user 0 is not allowed, UID is assigned from the namespace range`},
+				{SCCSeverity_Error, "Deployment", "test", "spec.template.spec.containers[0].securityContext.privileged", `container "web": privileged containers are not allowed`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			violations := CheckRestrictedSCC(test.Objects)
+			if !reflect.DeepEqual(violations, test.Violations) {
+				t.Errorf("Expected %#v\ngot %#v", test.Violations, violations)
+			}
+		})
+	}
+}
+
+func TestCheckRestrictedSCC_Transform(t *testing.T) {
+	o := &object.OpenCompose{
+		Version: "0.1-dev",
+		Services: []object.Service{
+			{
+				Name: "web",
+				Containers: []object.Container{
+					{
+						Name:  "nginx",
+						Image: "nginx",
+						Ports: []object.Port{
+							{Port: object.PortMapping{ContainerPort: 80, ServicePort: 80}, Type: object.PortType_External},
+							{Port: object.PortMapping{ContainerPort: 8080, ServicePort: 8080}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range []struct {
+		Name             string
+		DeploymentConfig bool
+		Kind             string
+	}{
+		{"Deployments", false, "Deployment"},
+		{"DeploymentConfigs", true, "DeploymentConfig"},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			transformer := Transformer{DeploymentConfig: test.DeploymentConfig}
+			objects, err := transformer.Transform(o)
+			if err != nil {
+				t.Fatalf("Failed to transform: %s", err)
+			}
+
+			// the privileged port is the only setting of the generated pod specs the restricted SCC breaks
+			expected := []SCCViolation{
+				{SCCSeverity_Warning, test.Kind, "web", "spec.template.spec.containers[0].ports[0].containerPort", `container "nginx": port 80 is privileged, container runs as non-root user and most likely won't be able to bind it`},
+			}
+			if violations := CheckRestrictedSCC(objects); !reflect.DeepEqual(violations, expected) {
+				t.Errorf("Expected %#v\ngot %#v", expected, violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package openshift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+
+	"github.com/redhat-developer/opencompose/pkg/object"
+	os_v1 "github.com/redhat-developer/opencompose/pkg/transform/openshift/api/v1"
+	"k8s.io/client-go/pkg/api"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+// Parameter references can't be kept in the OpenCompose file while it is decoded,
+// validated and transformed (e.g. "${{REPLICAS}}" isn't a number) so they are replaced
+// by placeholders first. The placeholders are valid in most of the fields (DNS labels,
+// label values, image tags, numbers) and are replaced back by parameter references
+// once the objects are serialized into the Template.
+
+func parameterHash(name string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return h.Sum32()
+}
+
+// Placeholder for ${NAME} parameter reference
+func ParameterPlaceholder(name string) string {
+	return fmt.Sprintf("ocparam-%08x", parameterHash(name))
+}
+
+// Placeholder for ${{NAME}} parameter reference, it fits into int32
+func TypedParameterPlaceholder(name string) int32 {
+	return 2000000000 + int32(parameterHash(name)%100000000)
+}
+
+// Substitutes parameter references by placeholders that are replaced back
+// to the references by CreateTemplate
+func TemplateParameterResolver(p object.Parameter, typed bool) (string, error) {
+	if typed {
+		return strconv.Itoa(int(TypedParameterPlaceholder(p.Name))), nil
+	}
+	return ParameterPlaceholder(p.Name), nil
+}
+
+// Numbers in JSON that can be typed parameter placeholders, the delimiter before them is matched
+// and the one after them is checked separately so adjacent items of an array both match
+var typedPlaceholderRegexp = regexp.MustCompile(`[:\[,](2[0-9]{9})`)
+
+// Replaces parameter placeholders in serialized object by parameter references
+func restoreParameterReferences(data []byte, parameters []object.Parameter) []byte {
+	typed := make(map[string]string)
+	for _, p := range parameters {
+		data = bytes.Replace(data, []byte(ParameterPlaceholder(p.Name)), []byte(fmt.Sprintf("${%s}", p.Name)), -1)
+		typed[strconv.Itoa(int(TypedParameterPlaceholder(p.Name)))] = p.Name
+	}
+
+	var result []byte
+	last := 0
+	for _, match := range typedPlaceholderRegexp.FindAllSubmatchIndex(data, -1) {
+		start, end := match[2], match[3]
+		name, ok := typed[string(data[start:end])]
+		// numbers in JSON are always delimited by one of these characters
+		if !ok || end >= len(data) || bytes.IndexByte([]byte(",]}"), data[end]) < 0 {
+			continue
+		}
+		result = append(result, data[last:start]...)
+		result = append(result, fmt.Sprintf(`"${{%s}}"`, name)...)
+		last = end
+	}
+	return append(result, data[last:]...)
+}
+
+// Create OpenShift Template wrapping the objects
+func CreateTemplate(name string, parameters []object.Parameter, objects []runtime.Object) (*os_v1.Template, error) {
+	t := &os_v1.Template{
+		ObjectMeta: api_v1.ObjectMeta{
+			Name: name,
+		},
+		Objects: []runtime.RawExtension{},
+	}
+
+	for _, o := range objects {
+		gvk, isUnversioned, err := api.Scheme.ObjectKind(o)
+		if err != nil {
+			return nil, fmt.Errorf("ConvertToVersion failed: %s", err)
+		}
+		if isUnversioned {
+			return nil, fmt.Errorf("ConvertToVersion failed: can't output unversioned type: %T", o)
+		}
+
+		o.GetObjectKind().SetGroupVersionKind(gvk)
+
+		data, err := json.Marshal(o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal object: %s", err)
+		}
+
+		t.Objects = append(t.Objects, runtime.RawExtension{
+			Raw: restoreParameterReferences(data, parameters),
+		})
+	}
+
+	for _, p := range parameters {
+		t.Parameters = append(t.Parameters, os_v1.Parameter{
+			Name:        p.Name,
+			DisplayName: p.DisplayName,
+			Description: p.Description,
+			Value:       p.Value,
+			Required:    p.Required,
+		})
+	}
+
+	return t, nil
+}
@@ -0,0 +1,71 @@
+package openshift
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/redhat-developer/opencompose/pkg/goutil"
+	"github.com/redhat-developer/opencompose/pkg/object"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	ext_v1beta1 "k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/pkg/runtime"
+)
+
+func TestCreateTemplate(t *testing.T) {
+	parameters := []object.Parameter{
+		{Name: "TAG", Value: "latest"},
+		{Name: "REPLICAS", Value: "1", Required: true},
+	}
+
+	d := &ext_v1beta1.Deployment{
+		ObjectMeta: api_v1.ObjectMeta{
+			Name: "foo",
+		},
+		Spec: ext_v1beta1.DeploymentSpec{
+			Replicas: goutil.Int32Addr(TypedParameterPlaceholder("REPLICAS")),
+			Template: api_v1.PodTemplateSpec{
+				Spec: api_v1.PodSpec{
+					Containers: []api_v1.Container{
+						{
+							Name:  "foo",
+							Image: fmt.Sprintf("foo:%s", ParameterPlaceholder("TAG")),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	template, err := CreateTemplate("foo", parameters, []runtime.Object{d})
+	if err != nil {
+		t.Fatalf("Failed to create template: %s", err)
+	}
+
+	if len(template.Objects) != 1 {
+		t.Fatalf("Expected 1 object, got %d", len(template.Objects))
+	}
+
+	expected := `{"kind":"Deployment","apiVersion":"extensions/v1beta1","metadata":{"name":"foo","creationTimestamp":null},"spec":{"replicas":"${{REPLICAS}}","template":{"metadata":{"creationTimestamp":null},"spec":{"containers":[{"name":"foo","image":"foo:${TAG}","resources":{}}]}},"strategy":{}},"status":{}}`
+	if got := string(template.Objects[0].Raw); got != expected {
+		t.Errorf("Expected %s\ngot %s", expected, got)
+	}
+
+	if len(template.Parameters) != 2 || template.Parameters[1].Name != "REPLICAS" || !template.Parameters[1].Required {
+		t.Errorf("Unexpected template parameters: %#v", template.Parameters)
+	}
+}
+
+func TestRestoreParameterReferences(t *testing.T) {
+	parameters := []object.Parameter{
+		{Name: "TAG"},
+		{Name: "A"},
+		{Name: "B"},
+	}
+	a, b := TypedParameterPlaceholder("A"), TypedParameterPlaceholder("B")
+
+	data := fmt.Sprintf(`{"image":"foo:%s","items":[%d,%d],"other":2000000000,"a":%d}`, ParameterPlaceholder("TAG"), a, b, a)
+	expected := `{"image":"foo:${TAG}","items":["${{A}}","${{B}}"],"other":2000000000,"a":"${{A}}"}`
+	if got := string(restoreParameterReferences([]byte(data), parameters)); got != expected {
+		t.Errorf("Expected %s\ngot %s", expected, got)
+	}
+}
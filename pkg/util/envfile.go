@@ -0,0 +1,59 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var envVariableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Parses KEY=VALUE assignment, the value can be empty
+func ParseKeyValue(s string) (string, string, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("%q: expected KEY=VALUE", s)
+	}
+
+	key := parts[0]
+	if !envVariableNameRegexp.MatchString(key) {
+		return "", "", fmt.Errorf("%q: invalid variable name %q, must match regexp %q", s, key, envVariableNameRegexp.String())
+	}
+
+	return key, parts[1], nil
+}
+
+// Parses .env file with KEY=VALUE lines as used by docker-compose.
+// Empty lines and lines starting with '#' are ignored, optional 'export ' prefix
+// is dropped and the value can be enclosed in single or double quotes.
+func ParseEnvFile(data []byte) (map[string]string, error) {
+	variables := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, err := ParseKeyValue(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		variables[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return variables, nil
+}
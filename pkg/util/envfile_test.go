@@ -0,0 +1,56 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		succeed   bool
+		data      string
+		variables map[string]string
+	}{
+		{
+			"comments, quotes and export",
+			true, `
+# image tag for CI
+TAG=v1.2
+export HOST=ci.example.com
+QUOTED="a b"
+SINGLE='c=d'
+EMPTY=
+`,
+			map[string]string{
+				"TAG":    "v1.2",
+				"HOST":   "ci.example.com",
+				"QUOTED": "a b",
+				"SINGLE": "c=d",
+				"EMPTY":  "",
+			},
+		},
+		{"missing '='", false, "TAG\n", nil},
+		{"invalid name", false, "1TAG=v1\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variables, err := ParseEnvFile([]byte(tt.data))
+			if err != nil {
+				if tt.succeed {
+					t.Fatalf("ParseEnvFile was expected to succeed for %q, but it failed with the error: %v", tt.data, err)
+				}
+				return
+			}
+
+			if !tt.succeed {
+				t.Fatalf("ParseEnvFile was expected to fail for %q, but it passed", tt.data)
+			}
+
+			if !reflect.DeepEqual(variables, tt.variables) {
+				t.Fatalf("Expected: %#v\nGot: %#v", tt.variables, variables)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorList collects multiple errors, e.g. all the problems found in a file,
+// so they can be reported at once instead of one by one
+type ErrorList []error
+
+func (el ErrorList) Error() string {
+	var messages []string
+	for _, err := range el {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Returns nil for empty list, the only error for list with one item and the list otherwise
+func (el ErrorList) Err() error {
+	switch len(el) {
+	case 0:
+		return nil
+	case 1:
+		return el[0]
+	default:
+		return el
+	}
+}
+
+// Appends err to the list, errors of nested lists are appended one by one and nil is skipped
+func (el ErrorList) Append(err error) ErrorList {
+	if err == nil {
+		return el
+	}
+	if list, ok := err.(ErrorList); ok {
+		for _, e := range list {
+			el = el.Append(e)
+		}
+		return el
+	}
+	return append(el, err)
+}
+
+// Returns the errors err consists of, i.e. items of ErrorList or err itself
+func Errors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if list, ok := err.(ErrorList); ok {
+		return list
+	}
+	if exit, ok := err.(ExitError); ok {
+		return Errors(exit.Err)
+	}
+	return []error{err}
+}
+
+// ExitError makes the program exit with Code instead of 1. Err is reported as any other error,
+// it is nil when the problems have already been printed.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Returns code the program exits with because of err
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exit, ok := err.(ExitError); ok {
+		return exit.Code
+	}
+	return 1
+}
+
+// ErrorCode identifies the kind of a problem, the codes are stable so tools can rely on them
+type ErrorCode string
+
+// Code of the errors that don't have any
+const ErrorCode_Unknown ErrorCode = "OC0000"
+
+// Coded is implemented by errors that have an ErrorCode
+type Coded interface {
+	Code() ErrorCode
+}
+
+// Returns code of err or ErrorCode_Unknown if it doesn't have any
+func CodeOf(err error) ErrorCode {
+	if c, ok := err.(Coded); ok && c.Code() != "" {
+		return c.Code()
+	}
+	return ErrorCode_Unknown
+}
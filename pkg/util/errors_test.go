@@ -0,0 +1,83 @@
+package util
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestErrorList_Err(t *testing.T) {
+	a, b := errors.New("a"), errors.New("b")
+
+	tests := []struct {
+		Name  string
+		List  ErrorList
+		Error error
+	}{
+		{"Empty list", nil, nil},
+		{"Single error", ErrorList{a}, a},
+		{"More errors", ErrorList{a, b}, ErrorList{a, b}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := tt.List.Err(); !reflect.DeepEqual(err, tt.Error) {
+				t.Fatalf("Expected %#v, got %#v", tt.Error, err)
+			}
+		})
+	}
+}
+
+func TestErrorList_Append(t *testing.T) {
+	a, b, c := errors.New("a"), errors.New("b"), errors.New("c")
+
+	var list ErrorList
+	list = list.Append(a)
+	list = list.Append(nil)
+	list = list.Append(ErrorList{b, ErrorList{c}})
+
+	if !reflect.DeepEqual(list, ErrorList{a, b, c}) {
+		t.Fatalf("Expected nested lists to be flattened, got %#v", list)
+	}
+
+	if list.Error() != "a\nb\nc" {
+		t.Fatalf("Expected errors on separate lines, got %q", list.Error())
+	}
+
+	if errs := Errors(list.Err()); len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %#v", errs)
+	}
+	if errs := Errors(a); !reflect.DeepEqual(errs, []error{a}) {
+		t.Fatalf("Expected the error itself, got %#v", errs)
+	}
+	if errs := Errors(nil); errs != nil {
+		t.Fatalf("Expected no errors, got %#v", errs)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	a := errors.New("a")
+
+	tests := []struct {
+		Name   string
+		Error  error
+		Code   int
+		Errors []error
+	}{
+		{"No error", nil, 0, nil},
+		{"Error", a, 1, []error{a}},
+		{"Exit error", ExitError{Code: 2, Err: a}, 2, []error{a}},
+		{"Exit error without message", ExitError{Code: 3}, 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if code := ExitCode(tt.Error); code != tt.Code {
+				t.Errorf("Expected exit code %d, got %d", tt.Code, code)
+			}
+			if errs := Errors(tt.Error); !reflect.DeepEqual(errs, tt.Errors) {
+				t.Errorf("Expected errors %#v, got %#v", tt.Errors, errs)
+			}
+		})
+	}
+}